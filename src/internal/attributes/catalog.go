@@ -1,8 +1,11 @@
 package attributes
 
 import (
+	"sort"
+
 	"github.com/rinq/rinq-go/src/internal/x/bufferpool"
 	"github.com/rinq/rinq-go/src/rinq/constraint"
+	"github.com/rinq/rinq-go/src/rinq/ident"
 )
 
 // Catalog is a namespaced collection of attributes.
@@ -29,6 +32,25 @@ func (c Catalog) MatchConstraint(ns string, con constraint.Constraint) bool {
 	return isMatch.(bool)
 }
 
+// NamespacesAt returns the names of the namespaces that contained at least one
+// attribute at the revision rev, in order.
+func (c Catalog) NamespacesAt(rev ident.Revision) []string {
+	var names []string
+
+	for ns, t := range c {
+		for _, attr := range t {
+			if attr.CreatedAt <= rev {
+				names = append(names, ns)
+				break
+			}
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
 // IsEmpty returns true if there are no attributes in the catalog.
 func (c Catalog) IsEmpty() bool {
 	for _, t := range c {
@@ -1,6 +1,11 @@
 package attributes
 
-import "github.com/rinq/rinq-go/src/rinq/constraint"
+import (
+	"strings"
+
+	"github.com/rinq/rinq-go/src/internal/x/compare"
+	"github.com/rinq/rinq-go/src/rinq/constraint"
+)
 
 // catalogMatcher is a constraint.Visitor that evaluates a constraint against an
 // attribute catalog.
@@ -64,3 +69,61 @@ func (m *catalogMatcher) Or(cons []constraint.Constraint, args ...interface{}) (
 
 	return false, nil
 }
+
+func (m *catalogMatcher) In(k string, vs []string, args ...interface{}) (interface{}, error) {
+	ns := unpackNamespace(args)
+	value := m.cat[ns][k].Value
+
+	for _, v := range vs {
+		if value == v {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (m *catalogMatcher) Prefix(k, p string, args ...interface{}) (interface{}, error) {
+	ns := unpackNamespace(args)
+	return strings.HasPrefix(m.cat[ns][k].Value, p), nil
+}
+
+func (m *catalogMatcher) Matches(k, pattern string, args ...interface{}) (interface{}, error) {
+	ns := unpackNamespace(args)
+
+	re := compilePattern(pattern)
+	if re == nil {
+		return false, nil
+	}
+
+	return re.MatchString(m.cat[ns][k].Value), nil
+}
+
+func (m *catalogMatcher) Exists(k string, args ...interface{}) (interface{}, error) {
+	ns := unpackNamespace(args)
+	return m.cat[ns][k].Value != "", nil
+}
+
+func (m *catalogMatcher) Less(k, v string, args ...interface{}) (interface{}, error) {
+	ns := unpackNamespace(args)
+	c, ok := compare.Numbers(m.cat[ns][k].Value, v)
+	return ok && c < 0, nil
+}
+
+func (m *catalogMatcher) Greater(k, v string, args ...interface{}) (interface{}, error) {
+	ns := unpackNamespace(args)
+	c, ok := compare.Numbers(m.cat[ns][k].Value, v)
+	return ok && c > 0, nil
+}
+
+func (m *catalogMatcher) VersionLess(k, v string, args ...interface{}) (interface{}, error) {
+	ns := unpackNamespace(args)
+	c, ok := compare.Versions(m.cat[ns][k].Value, v)
+	return ok && c < 0, nil
+}
+
+func (m *catalogMatcher) VersionGreater(k, v string, args ...interface{}) (interface{}, error) {
+	ns := unpackNamespace(args)
+	c, ok := compare.Versions(m.cat[ns][k].Value, v)
+	return ok && c > 0, nil
+}
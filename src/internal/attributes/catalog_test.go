@@ -157,6 +157,105 @@ var _ = Describe("Catalog", func() {
 					constraint.Equal("a", "2"),
 				),
 			),
+
+			Entry(
+				"In",
+				Catalog{
+					"ns": {"a": {Attr: rinq.Set("a", "admin")}},
+				},
+				"ns",
+				constraint.In("a", "admin", "ops"),
+			),
+
+			Entry(
+				"Prefix",
+				Catalog{
+					"ns": {"a": {Attr: rinq.Set("a", "eu-west")}},
+				},
+				"ns",
+				constraint.Prefix("a", "eu-"),
+			),
+
+			Entry(
+				"Matches",
+				Catalog{
+					"ns": {"a": {Attr: rinq.Set("a", "eu-west-1")}},
+				},
+				"ns",
+				constraint.Matches("a", `^eu-\w+-\d$`),
+			),
+
+			Entry(
+				"Exists",
+				Catalog{
+					"ns": {"a": {Attr: rinq.Set("a", "1")}},
+				},
+				"ns",
+				constraint.Exists("a"),
+			),
+
+			Entry(
+				"Less",
+				Catalog{
+					"ns": {"a": {Attr: rinq.Set("a", "3")}},
+				},
+				"ns",
+				constraint.Less("a", "10"),
+			),
+
+			Entry(
+				"Less (decimal)",
+				Catalog{
+					"ns": {"a": {Attr: rinq.Set("a", "1.25")}},
+				},
+				"ns",
+				constraint.Less("a", "1.5"),
+			),
+
+			Entry(
+				"Less (negative)",
+				Catalog{
+					"ns": {"a": {Attr: rinq.Set("a", "-2.5")}},
+				},
+				"ns",
+				constraint.Less("a", "-2.25"),
+			),
+
+			Entry(
+				"Greater (decimal)",
+				Catalog{
+					"ns": {"a": {Attr: rinq.Set("a", "0.5")}},
+				},
+				"ns",
+				constraint.Greater("a", "0.10"),
+			),
+
+			Entry(
+				"VersionLess",
+				Catalog{
+					"ns": {"a": {Attr: rinq.Set("a", "1.0.0-beta")}},
+				},
+				"ns",
+				constraint.VersionLess("a", "1.0.0"),
+			),
+
+			Entry(
+				"VersionGreater",
+				Catalog{
+					"ns": {"a": {Attr: rinq.Set("a", "1.10.0")}},
+				},
+				"ns",
+				constraint.VersionGreater("a", "1.2.3"),
+			),
+
+			Entry(
+				"VersionGreater (two components)",
+				Catalog{
+					"ns": {"a": {Attr: rinq.Set("a", "1.10")}},
+				},
+				"ns",
+				constraint.VersionGreater("a", "1.2"),
+			),
 		)
 
 		DescribeTable(
@@ -235,9 +334,137 @@ var _ = Describe("Catalog", func() {
 					constraint.Equal("a", "3"),
 				),
 			),
+
+			Entry(
+				"In",
+				Catalog{
+					"ns": {"a": {Attr: rinq.Set("a", "guest")}},
+				},
+				"ns",
+				constraint.In("a", "admin", "ops"),
+			),
+
+			Entry(
+				"Prefix",
+				Catalog{
+					"ns": {"a": {Attr: rinq.Set("a", "us-east")}},
+				},
+				"ns",
+				constraint.Prefix("a", "eu-"),
+			),
+
+			Entry(
+				"Matches",
+				Catalog{
+					"ns": {"a": {Attr: rinq.Set("a", "us-east-1")}},
+				},
+				"ns",
+				constraint.Matches("a", `^eu-`),
+			),
+
+			Entry(
+				"Matches with invalid pattern",
+				Catalog{
+					"ns": {"a": {Attr: rinq.Set("a", "eu-west-1")}},
+				},
+				"ns",
+				constraint.Matches("a", `(`),
+			),
+
+			Entry(
+				"Exists",
+				Catalog{
+					"ns": {"a": {Attr: rinq.Set("a", "1")}},
+				},
+				"ns",
+				constraint.Exists("b"),
+			),
+
+			Entry(
+				"Exists (empty value)",
+				Catalog{
+					"ns": {"a": {Attr: rinq.Set("a", "")}},
+				},
+				"ns",
+				constraint.Exists("a"),
+			),
+
+			Entry(
+				"Less",
+				Catalog{
+					"ns": {"a": {Attr: rinq.Set("a", "30")}},
+				},
+				"ns",
+				constraint.Less("a", "10"),
+			),
+
+			Entry(
+				"Greater (decimal)",
+				Catalog{
+					"ns": {"a": {Attr: rinq.Set("a", "1.25")}},
+				},
+				"ns",
+				constraint.Greater("a", "1.5"),
+			),
+
+			Entry(
+				"Greater with version value",
+				Catalog{
+					"ns": {"a": {Attr: rinq.Set("a", "1.10.0")}},
+				},
+				"ns",
+				constraint.Greater("a", "1"),
+			),
+
+			Entry(
+				"Greater with incomparable value",
+				Catalog{
+					"ns": {"a": {Attr: rinq.Set("a", "abc")}},
+				},
+				"ns",
+				constraint.Greater("a", "1"),
+			),
+
+			Entry(
+				"VersionGreater",
+				Catalog{
+					"ns": {"a": {Attr: rinq.Set("a", "1.2.3")}},
+				},
+				"ns",
+				constraint.VersionGreater("a", "1.10.0"),
+			),
+
+			Entry(
+				"VersionLess with number value",
+				Catalog{
+					"ns": {"a": {Attr: rinq.Set("a", "-1")}},
+				},
+				"ns",
+				constraint.VersionLess("a", "1"),
+			),
 		)
 	})
 
+	Describe("NamespacesAt", func() {
+		It("returns the namespaces that contained attributes at the revision, in order", func() {
+			cat := Catalog{
+				"ns2": {
+					"a": {Attr: rinq.Set("a", "1"), CreatedAt: 1},
+				},
+				"ns1": {
+					"b": {Attr: rinq.Set("b", "2"), CreatedAt: 2},
+					"c": {Attr: rinq.Set("c", "3"), CreatedAt: 1},
+				},
+				"ns3": {
+					"d": {Attr: rinq.Set("d", "4"), CreatedAt: 2},
+				},
+				"ns4": {},
+			}
+
+			Expect(cat.NamespacesAt(1)).To(Equal([]string{"ns1", "ns2"}))
+		})
+	})
+
 	Describe("IsEmpty", func() {
 		It("returns true when the catalog is empty", func() {
 			Expect(Catalog{}.IsEmpty()).To(BeTrue())
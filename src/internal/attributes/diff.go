@@ -62,3 +62,52 @@ func (d *Diff) StringWithoutNamespace() string {
 
 	return buf.String()
 }
+
+// MultiDiff is a change to attributes within any number of namespaces,
+// produced by a single revision.
+type MultiDiff struct {
+	Revision ident.Revision
+	Diffs    []*Diff
+}
+
+// NewMultiDiff returns a new, empty MultiDiff.
+func NewMultiDiff(rev ident.Revision) *MultiDiff {
+	return &MultiDiff{
+		Revision: rev,
+	}
+}
+
+// Add adds a diff to d. It panics if diff was produced by a different revision.
+func (d *MultiDiff) Add(diff *Diff) {
+	if diff.Revision != d.Revision {
+		panic("diff was produced by a different revision")
+	}
+
+	d.Diffs = append(d.Diffs, diff)
+}
+
+// IsEmpty returns true if none of the diffs contain any attributes.
+func (d *MultiDiff) IsEmpty() bool {
+	for _, diff := range d.Diffs {
+		if !diff.IsEmpty() {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (d *MultiDiff) String() string {
+	buf := bufferpool.Get()
+	defer bufferpool.Put(buf)
+
+	for index, diff := range d.Diffs {
+		if index != 0 {
+			buf.WriteRune(' ')
+		}
+
+		buf.WriteString(diff.String())
+	}
+
+	return buf.String()
+}
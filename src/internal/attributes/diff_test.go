@@ -120,3 +120,52 @@ var _ = Describe("Diff", func() {
 		})
 	})
 })
+
+var _ = Describe("MultiDiff", func() {
+	Describe("Add", func() {
+		It("panics if the diff was produced by a different revision", func() {
+			d := NewMultiDiff(1)
+
+			Expect(func() {
+				d.Add(NewDiff("ns", 2))
+			}).To(Panic())
+		})
+	})
+
+	Describe("IsEmpty", func() {
+		It("returns true if none of the diffs contain attributes", func() {
+			d := NewMultiDiff(1)
+			d.Add(NewDiff("ns1", 1))
+			d.Add(NewDiff("ns2", 1))
+
+			Expect(d.IsEmpty()).To(BeTrue())
+		})
+
+		It("returns false if any of the diffs contain attributes", func() {
+			diff := NewDiff("ns2", 1)
+			diff.Append(VAttr{Attr: rinq.Set("a", "1")})
+
+			d := NewMultiDiff(1)
+			d.Add(NewDiff("ns1", 1))
+			d.Add(diff)
+
+			Expect(d.IsEmpty()).To(BeFalse())
+		})
+	})
+
+	Describe("String", func() {
+		It("renders each diff with its namespace", func() {
+			a := NewDiff("ns1", 1)
+			a.Append(VAttr{Attr: rinq.Set("a", "1"), CreatedAt: 1})
+
+			b := NewDiff("ns2", 1)
+			b.Append(VAttr{Attr: rinq.Set("b", "2")})
+
+			d := NewMultiDiff(1)
+			d.Add(a)
+			d.Add(b)
+
+			Expect(d.String()).To(Equal("ns1::{+a=1} ns2::{b=2}"))
+		})
+	})
+})
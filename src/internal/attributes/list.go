@@ -25,3 +25,17 @@ func (l List) IsEmpty() bool {
 func (l List) String() string {
 	return ToString(l)
 }
+
+// MustValidate panics if any of the attributes in l can not be used in an
+// update.
+func (l List) MustValidate() {
+	for _, attr := range l {
+		if attr.TTL < 0 {
+			panic("attribute TTL must not be negative")
+		}
+
+		if attr.IsFrozen && attr.TTL != 0 {
+			panic("frozen attributes can not have a TTL")
+		}
+	}
+}
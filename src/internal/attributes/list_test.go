@@ -1,6 +1,8 @@
 package attributes_test
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	. "github.com/rinq/rinq-go/src/internal/attributes"
@@ -57,4 +59,34 @@ var _ = Describe("List", func() {
 			Expect(list.String()).To(Equal("{a=1, b=2}"))
 		})
 	})
+
+	Describe("MustValidate", func() {
+		It("does not panic for valid attributes", func() {
+			list = List{
+				rinq.Set("a", "1"),
+				rinq.Freeze("b", "2"),
+				rinq.SetTTL("c", "3", time.Second),
+			}
+
+			Expect(func() {
+				list.MustValidate()
+			}).NotTo(Panic())
+		})
+
+		It("panics if an attribute has a negative TTL", func() {
+			list = List{rinq.SetTTL("a", "1", -time.Second)}
+
+			Expect(func() {
+				list.MustValidate()
+			}).To(Panic())
+		})
+
+		It("panics if a frozen attribute has a TTL", func() {
+			list = List{{Key: "a", Value: "1", IsFrozen: true, TTL: time.Second}}
+
+			Expect(func() {
+				list.MustValidate()
+			}).To(Panic())
+		})
+	})
 })
@@ -0,0 +1,47 @@
+package attributes
+
+import (
+	"regexp"
+	"sync"
+
+	"github.com/rinq/rinq-go/src/rinq/constraint"
+)
+
+// maxCachedPatterns is the maximum number of compiled patterns that are kept
+// by compilePattern(). The cache is emptied when it is full.
+const maxCachedPatterns = 1024
+
+var (
+	patternsMutex sync.RWMutex
+	patterns      = map[string]*regexp.Regexp{}
+)
+
+// compilePattern returns the compiled form of a MATCHES constraint's pattern,
+// or nil if the pattern is invalid.
+//
+// Compiled patterns are cached, so that a constraint that is evaluated
+// against many sessions compiles its pattern only once.
+func compilePattern(pattern string) *regexp.Regexp {
+	patternsMutex.RLock()
+	re, ok := patterns[pattern]
+	patternsMutex.RUnlock()
+
+	if ok {
+		return re
+	}
+
+	if len(pattern) <= constraint.MaxPatternLength {
+		re, _ = regexp.Compile(pattern)
+	}
+
+	patternsMutex.Lock()
+	defer patternsMutex.Unlock()
+
+	if len(patterns) >= maxCachedPatterns {
+		patterns = map[string]*regexp.Regexp{}
+	}
+
+	patterns[pattern] = re
+
+	return re
+}
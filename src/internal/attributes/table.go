@@ -1,6 +1,10 @@
 package attributes
 
-import "github.com/rinq/rinq-go/src/rinq"
+import (
+	"time"
+
+	"github.com/rinq/rinq-go/src/rinq"
+)
 
 // Table is a simple map-based implementation of rinq.AttrTable
 type Table map[string]rinq.Attr
@@ -11,6 +15,49 @@ func (t Table) Get(k string) (rinq.Attr, bool) {
 	return attr, ok
 }
 
+// GetInt returns the value of the attribute with key k as an integer.
+func (t Table) GetInt(k string) (int64, bool, error) {
+	attr, ok := t[k]
+	if !ok {
+		return 0, false, nil
+	}
+
+	v, err := attr.Int()
+	return v, true, err
+}
+
+// GetBool returns the value of the attribute with key k as a boolean.
+func (t Table) GetBool(k string) (bool, bool, error) {
+	attr, ok := t[k]
+	if !ok {
+		return false, false, nil
+	}
+
+	v, err := attr.Bool()
+	return v, true, err
+}
+
+// GetTime returns the value of the attribute with key k as a time.
+func (t Table) GetTime(k string) (time.Time, bool, error) {
+	attr, ok := t[k]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+
+	v, err := attr.Time()
+	return v, true, err
+}
+
+// GetJSON unmarshals the value of the attribute with key k into v.
+func (t Table) GetJSON(k string, v interface{}) (bool, error) {
+	attr, ok := t[k]
+	if !ok {
+		return false, nil
+	}
+
+	return true, attr.JSON(v)
+}
+
 // Each calls fn for each attribute in the collection. Iteration stops
 // when fn returns false.
 func (t Table) Each(fn func(rinq.Attr) bool) {
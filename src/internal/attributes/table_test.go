@@ -25,6 +25,45 @@ var _ = Describe("Table", func() {
 		})
 	})
 
+	Describe("GetInt", func() {
+		It("returns the attribute value as an integer", func() {
+			v, ok, err := table.GetInt("a")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(v).To(BeEquivalentTo(1))
+		})
+
+		It("returns false if the attribute does not exist", func() {
+			_, ok, err := table.GetInt("c")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeFalse())
+		})
+
+		It("returns an error if the value can not be converted", func() {
+			table["c"] = rinq.SetBool("c", true)
+
+			_, ok, err := table.GetInt("c")
+
+			Expect(err).To(HaveOccurred())
+			Expect(ok).To(BeTrue())
+		})
+	})
+
+	Describe("GetJSON", func() {
+		It("unmarshals the attribute value", func() {
+			table["c"] = rinq.SetJSON("c", []int{1, 2})
+
+			var v []int
+			ok, err := table.GetJSON("c", &v)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(v).To(Equal([]int{1, 2}))
+		})
+	})
+
 	Describe("Each", func() {
 		It("calls the function for each attribute in the table", func() {
 			var attrs []rinq.Attr
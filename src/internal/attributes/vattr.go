@@ -1,6 +1,8 @@
 package attributes
 
 import (
+	"time"
+
 	"github.com/rinq/rinq-go/src/rinq"
 	"github.com/rinq/rinq-go/src/rinq/ident"
 )
@@ -11,4 +13,8 @@ type VAttr struct {
 
 	CreatedAt ident.Revision `json:"cr,omitempty"`
 	UpdatedAt ident.Revision `json:"ur,omitempty"`
+
+	// ExpiresAt is the time at which the attribute is cleared, if it has a
+	// TTL. It is only known to the owning peer.
+	ExpiresAt time.Time `json:"-"`
 }
@@ -2,6 +2,7 @@ package attributes
 
 import (
 	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/ident"
 )
 
 // VTable is a collection of attributes with revision information.
@@ -36,3 +37,51 @@ func (t VTable) Clone() VTable {
 
 	return c
 }
+
+// At returns the attributes in t as they were at the revision rev. Attributes
+// created after rev are excluded.
+//
+// ok is false if any attribute has been updated since rev, in which case its
+// value at rev is no longer known.
+func (t VTable) At(rev ident.Revision) (table Table, ok bool) {
+	table = Table{}
+
+	for k, attr := range t {
+		if attr.CreatedAt > rev {
+			continue
+		}
+
+		if attr.UpdatedAt > rev {
+			return nil, false
+		}
+
+		table[k] = attr.Attr
+	}
+
+	return table, true
+}
+
+// ChangedBetween returns the attributes in t that were modified after the
+// revision from, as they were at the revision to.
+//
+// ok is false if any attribute that existed at to has been updated since to,
+// in which case it is not known whether it was modified after from.
+func (t VTable) ChangedBetween(from, to ident.Revision) (table Table, ok bool) {
+	table = Table{}
+
+	for k, attr := range t {
+		if attr.CreatedAt > to {
+			continue
+		}
+
+		if attr.UpdatedAt > to {
+			return nil, false
+		}
+
+		if attr.UpdatedAt > from {
+			table[k] = attr.Attr
+		}
+	}
+
+	return table, true
+}
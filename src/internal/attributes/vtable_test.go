@@ -61,6 +61,59 @@ var _ = Describe("VTable", func() {
 		})
 	})
 
+	Describe("At", func() {
+		BeforeEach(func() {
+			table = VTable{
+				"a": {Attr: rinq.Set("a", "1"), CreatedAt: 1, UpdatedAt: 1},
+				"b": {Attr: rinq.Set("b", "2"), CreatedAt: 1, UpdatedAt: 2},
+				"c": {Attr: rinq.Set("c", "3"), CreatedAt: 3, UpdatedAt: 3},
+			}
+		})
+
+		It("returns the attributes that existed at the revision", func() {
+			t, ok := table.At(2)
+
+			Expect(ok).To(BeTrue())
+			Expect(t).To(Equal(Table{
+				"a": rinq.Set("a", "1"),
+				"b": rinq.Set("b", "2"),
+			}))
+		})
+
+		It("returns false if an attribute has been updated since the revision", func() {
+			_, ok := table.At(1)
+
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Describe("ChangedBetween", func() {
+		BeforeEach(func() {
+			table = VTable{
+				"a": {Attr: rinq.Set("a", "1"), CreatedAt: 1, UpdatedAt: 1},
+				"b": {Attr: rinq.Set("b", "2"), CreatedAt: 1, UpdatedAt: 2},
+				"c": {Attr: rinq.Set("c", "3"), CreatedAt: 3, UpdatedAt: 3},
+				"d": {Attr: rinq.Set("d", "4"), CreatedAt: 4, UpdatedAt: 4},
+			}
+		})
+
+		It("returns the attributes modified after from, up to and including to", func() {
+			t, ok := table.ChangedBetween(1, 3)
+
+			Expect(ok).To(BeTrue())
+			Expect(t).To(Equal(Table{
+				"b": rinq.Set("b", "2"),
+				"c": rinq.Set("c", "3"),
+			}))
+		})
+
+		It("returns false if an attribute has been updated since to", func() {
+			_, ok := table.ChangedBetween(0, 1)
+
+			Expect(ok).To(BeFalse())
+		})
+	})
+
 	Describe("Clone", func() {
 		It("returns a different instance", func() {
 			t := table.Clone()
@@ -0,0 +1,86 @@
+package command
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/rinq/rinq-go/src/internal/x/aimd"
+)
+
+// Backpressure adapts the number of command requests that a server accepts at
+// any given time (its pre-fetch) to the latency of its handlers and its heap
+// usage.
+type Backpressure struct {
+	limit     *aimd.Limit
+	heapLimit uint64
+	heapUsage func() uint64
+	preFetch  uint
+}
+
+// NewBackpressure returns a Backpressure that adjusts the pre-fetch between 1
+// and max, based on the target handler latency.
+//
+// If heapLimit is non-zero, the pre-fetch is decreased while heapUsage()
+// returns a value above heapLimit, and is not increased again until it
+// returns to below the limit.
+func NewBackpressure(
+	max uint,
+	latency time.Duration,
+	heapLimit uint64,
+	heapUsage func() uint64,
+) *Backpressure {
+	return &Backpressure{
+		limit:     aimd.NewLimit(1, max, latency),
+		heapLimit: heapLimit,
+		heapUsage: heapUsage,
+		preFetch:  max,
+	}
+}
+
+// Observe adjusts the limit based on the latency of a handled request.
+func (b *Backpressure) Observe(latency time.Duration, now time.Time) {
+	b.limit.Observe(latency, now)
+}
+
+// Adjust updates the limit based on the heap usage and calls apply() with the
+// new pre-fetch if it has changed since the last call. prev is the pre-fetch
+// before the adjustment.
+//
+// If apply() returns an error, the pre-fetch is considered unchanged.
+func (b *Backpressure) Adjust(
+	now time.Time,
+	apply func(preFetch uint) error,
+) (prev, n uint, err error) {
+	if b.heapLimit != 0 {
+		pressure := b.heapUsage() > b.heapLimit
+
+		if pressure {
+			b.limit.Decrease(now)
+		}
+
+		b.limit.Hold(pressure)
+	}
+
+	prev = b.preFetch
+	n = b.limit.Current()
+
+	if n == prev {
+		return
+	}
+
+	if err = apply(n); err != nil {
+		return
+	}
+
+	b.preFetch = n
+
+	return
+}
+
+// HeapUsage returns the number of bytes of allocated heap objects.
+func HeapUsage() uint64 {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+
+	return stats.HeapAlloc
+}
@@ -0,0 +1,116 @@
+package command_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/rinq/rinq-go/src/internal/command"
+)
+
+var _ = Describe("Backpressure", func() {
+	var (
+		now     time.Time
+		heap    uint64
+		applied []uint
+		subject *Backpressure
+	)
+
+	// apply is a stub that records the pre-fetch values applied to the channel
+	apply := func(n uint) error {
+		applied = append(applied, n)
+		return nil
+	}
+
+	BeforeEach(func() {
+		now = time.Now()
+		heap = 0
+		applied = nil
+
+		subject = NewBackpressure(
+			8,
+			time.Second,
+			1000,
+			func() uint64 { return heap },
+		)
+	})
+
+	Describe("Adjust", func() {
+		It("does not apply the pre-fetch when it has not changed", func() {
+			prev, n, err := subject.Adjust(now, apply)
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(prev).To(Equal(uint(8)))
+			Expect(n).To(Equal(uint(8)))
+			Expect(applied).To(BeEmpty())
+		})
+
+		It("applies the pre-fetch after the latency exceeds the target", func() {
+			subject.Observe(2*time.Second, now)
+
+			prev, n, err := subject.Adjust(now, apply)
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(prev).To(Equal(uint(8)))
+			Expect(n).To(Equal(uint(4)))
+			Expect(applied).To(Equal([]uint{4}))
+		})
+
+		It("decreases the pre-fetch while the heap usage exceeds the limit", func() {
+			heap = 2000
+
+			subject.Adjust(now, apply)
+			subject.Adjust(now.Add(time.Second), apply)
+
+			Expect(applied).To(Equal([]uint{4, 2}))
+		})
+
+		It("holds the reduced pre-fetch while the heap usage exceeds the limit", func() {
+			heap = 2000
+			subject.Adjust(now, apply)
+
+			// a full window of fast requests would normally increase the limit
+			for i := 0; i < 8; i++ {
+				subject.Observe(time.Millisecond, now)
+			}
+
+			_, n, _ := subject.Adjust(now.Add(500*time.Millisecond), apply)
+
+			Expect(n).To(Equal(uint(4)))
+		})
+
+		It("allows the pre-fetch to increase once the heap usage is below the limit", func() {
+			heap = 2000
+			subject.Adjust(now, apply)
+
+			heap = 0
+			subject.Adjust(now.Add(500*time.Millisecond), apply)
+
+			for i := 0; i < 4; i++ {
+				subject.Observe(time.Millisecond, now)
+			}
+
+			_, n, _ := subject.Adjust(now.Add(time.Second), apply)
+
+			Expect(n).To(Equal(uint(5)))
+			Expect(applied).To(Equal([]uint{4, 5}))
+		})
+
+		It("retries the pre-fetch if it could not be applied", func() {
+			subject.Observe(2*time.Second, now)
+
+			_, _, err := subject.Adjust(now, func(uint) error {
+				return errors.New("<error>")
+			})
+			Expect(err).To(MatchError("<error>"))
+
+			prev, n, err := subject.Adjust(now, apply)
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(prev).To(Equal(uint(8)))
+			Expect(n).To(Equal(uint(4)))
+			Expect(applied).To(Equal([]uint{4}))
+		})
+	})
+})
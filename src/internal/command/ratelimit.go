@@ -0,0 +1,225 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jmalloc/twelf/src/twelf"
+	"github.com/rinq/rinq-go/src/internal/x/ratelimit"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/options"
+)
+
+// RateLimiter rejects command requests that exceed any of the limits described
+// by a set of rate limit policies.
+//
+// The limiter for each policy is retained when the policies are replaced with
+// an identical policy, so calling Peer.Listen() again does not reset the
+// limits of a namespace.
+type RateLimiter struct {
+	peerID ident.PeerID
+	logger twelf.Logger
+
+	mutex    sync.Mutex
+	policies []options.RateLimitPolicy
+	limiters []*ratelimit.Limiter
+	sources  map[sourceKey]sourceLimit
+	prunedAt time.Time
+}
+
+// sourceKey identifies the attribute that overrides the limit of a policy, at
+// a specific revision of the source session.
+type sourceKey struct {
+	ref ident.Ref
+	ns  string
+	key string
+}
+
+// sourceLimit is a limit obtained from the attributes of a source session.
+type sourceLimit struct {
+	requests  uint
+	fetchedAt time.Time
+}
+
+// sourceLimitTTL is how long limits obtained from session attributes are
+// cached. Attributes never change at a given revision, so the cache is only
+// pruned to bound its size.
+const sourceLimitTTL = time.Minute
+
+// NewRateLimiter returns a rate limiter without any policies.
+func NewRateLimiter(peerID ident.PeerID, logger twelf.Logger) *RateLimiter {
+	return &RateLimiter{
+		peerID:  peerID,
+		logger:  logger,
+		sources: map[sourceKey]sourceLimit{},
+	}
+}
+
+// SetPolicies replaces the policies enforced by the limiter. The state of any
+// policy that is identical to one of the existing policies is retained.
+func (l *RateLimiter) SetPolicies(policies []options.RateLimitPolicy) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	limiters := make([]*ratelimit.Limiter, len(policies))
+
+	for i, p := range policies {
+		for j, q := range l.policies {
+			if p == q && l.limiters[j] != nil {
+				limiters[i] = l.limiters[j]
+				l.limiters[j] = nil // don't share a limiter with a duplicate policy
+				break
+			}
+		}
+
+		if limiters[i] == nil {
+			limiters[i] = ratelimit.NewLimiter(p.Interval)
+		}
+	}
+
+	l.policies = policies
+	l.limiters = limiters
+}
+
+// Allow returns true if req is within every limit, in which case a token is
+// taken from each of the limits. Otherwise, no tokens are taken and wait is
+// the time until the request would be accepted.
+func (l *RateLimiter) Allow(ctx context.Context, req rinq.Request) (ok bool, wait time.Duration) {
+	l.mutex.Lock()
+	policies := l.policies
+	limiters := l.limiters
+	l.mutex.Unlock()
+
+	// the limits are resolved before acquiring the lock, as they may require
+	// fetching the source session's attributes from a remote peer
+	requests := make([]uint, len(policies))
+	for i, p := range policies {
+		requests[i] = l.requests(ctx, req, p)
+	}
+
+	now := time.Now()
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	for i, p := range policies {
+		if ok, wait := limiters[i].Peek(rateLimitKey(req, p.Key), requests[i], now); !ok {
+			return false, wait
+		}
+	}
+
+	for i, p := range policies {
+		limiters[i].Take(rateLimitKey(req, p.Key), requests[i], now)
+	}
+
+	return true, 0
+}
+
+// requests returns the number of requests per interval allowed by p for the
+// source session of req.
+func (l *RateLimiter) requests(ctx context.Context, req rinq.Request, p options.RateLimitPolicy) uint {
+	if p.AttrNamespace == "" {
+		return p.Requests
+	}
+
+	k := sourceKey{req.ID.Ref, p.AttrNamespace, p.AttrKey}
+	now := time.Now()
+
+	l.mutex.Lock()
+	l.pruneSources(now)
+	s, ok := l.sources[k]
+	l.mutex.Unlock()
+
+	if ok {
+		return s.requests
+	}
+
+	attr, err := req.Source.Get(ctx, p.AttrNamespace, p.AttrKey)
+	if err != nil {
+		// don't cache the default, so that the attribute is fetched again by
+		// the next request
+		logRateLimitAttrError(l.logger, l.peerID, req.ID, p.AttrNamespace, p.AttrKey, err)
+		return p.Requests
+	}
+
+	n := p.Requests
+	if attr.Value != "" {
+		if v, err := strconv.ParseUint(attr.Value, 10, 31); err == nil {
+			n = uint(v)
+		}
+	}
+
+	l.mutex.Lock()
+	l.sources[k] = sourceLimit{n, now}
+	l.mutex.Unlock()
+
+	return n
+}
+
+// pruneSources discards any cached source limits that are older than
+// sourceLimitTTL, at most once per TTL.
+func (l *RateLimiter) pruneSources(now time.Time) {
+	if now.Sub(l.prunedAt) < sourceLimitTTL {
+		return
+	}
+
+	l.prunedAt = now
+
+	for k, s := range l.sources {
+		if now.Sub(s.fetchedAt) >= sourceLimitTTL {
+			delete(l.sources, k)
+		}
+	}
+}
+
+// RateLimit returns a handler that rejects command requests that are not
+// allowed by l, and forwards all other requests to h.
+func RateLimit(h rinq.CommandHandler, l *RateLimiter) rinq.CommandHandler {
+	return func(
+		ctx context.Context,
+		req rinq.Request,
+		res rinq.Response,
+	) {
+		if ok, wait := l.Allow(ctx, req); !ok {
+			req.Payload.Close()
+			rejectRateLimited(res, wait)
+			return
+		}
+
+		h(ctx, req, res)
+	}
+}
+
+// rateLimitKey returns the key of the bucket used for req.
+func rateLimitKey(req rinq.Request, k options.RateLimitKey) string {
+	switch k {
+	case options.PerCommand:
+		return req.Command
+	case options.PerSession:
+		return req.Source.SessionID().String()
+	case options.PerPeer:
+		return req.Source.SessionID().Peer.String()
+	default:
+		return ""
+	}
+}
+
+// rejectRateLimited responds to a request that exceeded a rate limit. wait is
+// the time until the request would be accepted.
+func rejectRateLimited(res rinq.Response, wait time.Duration) {
+	// round up, so the caller never retries too early
+	ms := uint64((wait + time.Millisecond - 1) / time.Millisecond)
+
+	payload := rinq.NewPayload(ms)
+	defer payload.Close()
+
+	res.Error(rinq.Failure{
+		Type:    rinq.RateLimitedFailure,
+		Message: fmt.Sprintf("rate limit exceeded, retry after %dms", ms),
+		Payload: payload,
+	})
+}
@@ -0,0 +1,24 @@
+package command
+
+import (
+	"github.com/jmalloc/twelf/src/twelf"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+)
+
+func logRateLimitAttrError(
+	logger twelf.Logger,
+	peerID ident.PeerID,
+	msgID ident.MessageID,
+	ns string,
+	key string,
+	err error,
+) {
+	logger.Log(
+		"%s could not fetch the '%s::%s' rate limit attribute for request %s, using the default limit: %s",
+		peerID.ShortString(),
+		ns,
+		key,
+		msgID.ShortString(),
+		err,
+	)
+}
@@ -3,12 +3,13 @@ package command
 import (
 	"github.com/rinq/rinq-go/src/internal/service"
 	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/options"
 )
 
 // Server processes command requests made by an invoker.
 type Server interface {
 	service.Service
 
-	Listen(ns string, h rinq.CommandHandler) (bool, error)
+	Listen(ns string, h rinq.CommandHandler, opts options.ListenOptions) (bool, error)
 	Unlisten(ns string) (bool, error)
 }
@@ -26,17 +26,41 @@ func SharedPeer() rinq.Peer {
 	return sharedPeer.peer
 }
 
-// NewPeer returns a new peer for use in functional tests.
-func NewPeer() rinq.Peer {
-	peer, err := rinqamqp.DialEnv(
-		options.Logger(
-			&twelf.StandardLogger{CaptureDebug: true},
-		),
+// NewPeer returns a new peer for use in functional tests, with the given
+// options in addition to the defaults.
+func NewPeer(opts ...options.Option) rinq.Peer {
+	opts = append(
+		[]options.Option{
+			options.Logger(
+				&twelf.StandardLogger{CaptureDebug: true},
+			),
+		},
+		opts...,
 	)
 
+	peer, err := rinqamqp.DialEnv(opts...)
+
 	if err != nil {
 		panic(err)
 	}
 
 	return peer
 }
+
+// NewPeerPair returns two new peers for use in functional tests, typically one
+// that sends messages and one that receives them, each with the given options
+// in addition to the defaults.
+func NewPeerPair(opts ...options.Option) (rinq.Peer, rinq.Peer) {
+	return NewPeer(opts...), NewPeer(opts...)
+}
+
+// StopPeers stops the given peers and waits until they have all stopped.
+func StopPeers(peers ...rinq.Peer) {
+	for _, p := range peers {
+		p.Stop()
+	}
+
+	for _, p := range peers {
+		<-p.Done()
+	}
+}
@@ -0,0 +1,186 @@
+package localsession
+
+import (
+	"sync"
+
+	"github.com/rinq/rinq-go/src/internal/attributes"
+	"github.com/rinq/rinq-go/src/rinq/constraint"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+)
+
+// index is a secondary index of the sessions in a store, keyed by the
+// namespace, key and value of their attributes.
+//
+// Only attributes with non-empty values are indexed.
+type index struct {
+	mutex    sync.RWMutex
+	sessions map[indexKey]map[ident.SessionID]*Session
+	values   map[ident.SessionID]map[attrKey]string
+	observer IndexObserver
+}
+
+// IndexObserver is notified when an attribute value is added to or removed
+// from the index of a store.
+//
+// The methods are called while the index is locked, and usually while the
+// session being updated is locked, so they must not call back into the store
+// or its sessions, and any time they spend blocked delays other updates.
+type IndexObserver interface {
+	// Indexed is called when the first session with the value v for the
+	// attribute k in the ns namespace is indexed.
+	Indexed(ns, k, v string)
+
+	// Unindexed is called when the last session with the value v for the
+	// attribute k in the ns namespace is removed from the index.
+	Unindexed(ns, k, v string)
+}
+
+// attrKey identifies an attribute within a session.
+type attrKey struct {
+	ns, key string
+}
+
+// indexKey identifies the sessions with a specific attribute value.
+type indexKey struct {
+	attrKey
+	value string
+}
+
+// candidateSet is a set of sessions that may match a constraint.
+type candidateSet map[ident.SessionID]*Session
+
+func newIndex() *index {
+	return &index{
+		sessions: map[indexKey]map[ident.SessionID]*Session{},
+		values:   map[ident.SessionID]map[attrKey]string{},
+	}
+}
+
+// Update updates the index to reflect the attributes changed by diff.
+func (i *index) Update(sess *Session, id ident.SessionID, diff *attributes.MultiDiff) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	for _, d := range diff.Diffs {
+		for _, attr := range d.VList {
+			i.set(sess, id, attrKey{d.Namespace, attr.Key}, attr.Value)
+		}
+	}
+}
+
+// Add adds all of the attributes in cat to the index.
+func (i *index) Add(sess *Session, id ident.SessionID, cat attributes.Catalog) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	for ns, attrs := range cat {
+		for _, attr := range attrs {
+			i.set(sess, id, attrKey{ns, attr.Key}, attr.Value)
+		}
+	}
+}
+
+// Remove removes all of the attributes of the session with the given ID from
+// the index.
+func (i *index) Remove(id ident.SessionID) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	for k, v := range i.values[id] {
+		i.unlink(id, indexKey{k, v})
+	}
+
+	delete(i.values, id)
+}
+
+// SetObserver sets the observer that is notified of changes to the indexed
+// values. Indexed() is called for each value that is already indexed.
+func (i *index) SetObserver(o IndexObserver) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	i.observer = o
+
+	if o != nil {
+		for k := range i.sessions {
+			o.Indexed(k.ns, k.key, k.value)
+		}
+	}
+}
+
+// Candidates returns the sessions that may match con, using ns as the default
+// namespace. ok is false if con can not be evaluated using the index, in which
+// case every session must be considered.
+func (i *index) Candidates(ns string, con constraint.Constraint) (sessions []*Session, ok bool) {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+
+	set, _ := con.Accept(&planner{i}, ns)
+	if set == nil {
+		return nil, false
+	}
+
+	for _, sess := range set.(candidateSet) {
+		sessions = append(sessions, sess)
+	}
+
+	return sessions, true
+}
+
+// set updates the indexed value of the attribute k. It assumes i.mutex is
+// already locked.
+func (i *index) set(sess *Session, id ident.SessionID, k attrKey, v string) {
+	values := i.values[id]
+
+	if prev, ok := values[k]; ok {
+		if prev == v {
+			return
+		}
+
+		i.unlink(id, indexKey{k, prev})
+		delete(values, k)
+	}
+
+	if v == "" {
+		return
+	}
+
+	if values == nil {
+		values = map[attrKey]string{}
+		i.values[id] = values
+	}
+
+	values[k] = v
+
+	ik := indexKey{k, v}
+	sessions := i.sessions[ik]
+	if sessions == nil {
+		sessions = map[ident.SessionID]*Session{}
+		i.sessions[ik] = sessions
+
+		if i.observer != nil {
+			i.observer.Indexed(k.ns, k.key, v)
+		}
+	}
+
+	sessions[id] = sess
+}
+
+// unlink removes the session with the given ID from the sessions with the
+// attribute value described by k. It assumes i.mutex is already locked.
+func (i *index) unlink(id ident.SessionID, k indexKey) {
+	sessions, ok := i.sessions[k]
+	if !ok {
+		return
+	}
+
+	delete(sessions, id)
+
+	if len(sessions) == 0 {
+		delete(i.sessions, k)
+
+		if i.observer != nil {
+			i.observer.Unindexed(k.ns, k.key, k.value)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package localsession
+
+import "github.com/rinq/rinq-go/src/rinq/constraint"
+
+// planner is a constraint.Visitor that uses an index to find the sessions that
+// may match a constraint.
+//
+// Each method returns a candidateSet containing every session that may match
+// the constraint, or nil if the constraint can not be evaluated using the
+// index. The candidates must still be evaluated against the full constraint.
+type planner struct {
+	idx *index
+}
+
+// unpackNamespace extracts the first element of args as a string.
+func unpackNamespace(args []interface{}) string {
+	return args[0].(string)
+}
+
+func (p *planner) None(...interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+func (p *planner) Within(ns string, cons []constraint.Constraint, _ ...interface{}) (interface{}, error) {
+	return p.And(cons, ns)
+}
+
+func (p *planner) Equal(k, v string, args ...interface{}) (interface{}, error) {
+	if v == "" {
+		// sessions without the attribute also match, but are not indexed
+		return nil, nil
+	}
+
+	return p.lookup(unpackNamespace(args), k, v), nil
+}
+
+func (p *planner) NotEqual(string, string, ...interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+func (p *planner) Not(constraint.Constraint, ...interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+func (p *planner) And(cons []constraint.Constraint, args ...interface{}) (interface{}, error) {
+	var result candidateSet
+
+	for _, con := range cons {
+		set, _ := con.Accept(p, args...)
+		if set == nil {
+			continue
+		}
+
+		result = intersect(result, set.(candidateSet))
+	}
+
+	if result == nil {
+		return nil, nil
+	}
+
+	return result, nil
+}
+
+func (p *planner) Or(cons []constraint.Constraint, args ...interface{}) (interface{}, error) {
+	result := candidateSet{}
+
+	for _, con := range cons {
+		set, _ := con.Accept(p, args...)
+		if set == nil {
+			return nil, nil
+		}
+
+		for id, sess := range set.(candidateSet) {
+			result[id] = sess
+		}
+	}
+
+	return result, nil
+}
+
+func (p *planner) In(k string, vs []string, args ...interface{}) (interface{}, error) {
+	ns := unpackNamespace(args)
+	result := candidateSet{}
+
+	for _, v := range vs {
+		if v == "" {
+			return nil, nil
+		}
+
+		for id, sess := range p.lookup(ns, k, v) {
+			result[id] = sess
+		}
+	}
+
+	return result, nil
+}
+
+func (p *planner) Prefix(string, string, ...interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+func (p *planner) Matches(string, string, ...interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+func (p *planner) Exists(string, ...interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+func (p *planner) Less(string, string, ...interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+func (p *planner) Greater(string, string, ...interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+func (p *planner) VersionLess(string, string, ...interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+func (p *planner) VersionGreater(string, string, ...interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+// lookup returns the sessions that have the value v for the attribute k in the
+// ns namespace.
+func (p *planner) lookup(ns, k, v string) candidateSet {
+	sessions := p.idx.sessions[indexKey{attrKey{ns, k}, v}]
+	if sessions == nil {
+		return candidateSet{}
+	}
+
+	return candidateSet(sessions)
+}
+
+// intersect returns the sessions that are in both a and b. If a is nil, b is
+// returned unchanged.
+func intersect(a, b candidateSet) candidateSet {
+	if a == nil {
+		return b
+	}
+
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+
+	result := candidateSet{}
+
+	for id, sess := range a {
+		if _, ok := b[id]; ok {
+			result[id] = sess
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,122 @@
+package localsession
+
+import (
+	"github.com/rinq/rinq-go/src/internal/attributes"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+)
+
+// usage describes the attributes of a session that count towards its quota.
+// Only attributes with non-empty values are counted.
+//
+// It is updated incrementally from the diff produced by each revision, and is
+// never modified in place, so that it can be replaced only once a change has
+// been committed.
+type usage struct {
+	keys map[string]uint // map of namespace to the number of keys in use
+	size uint            // total size of the keys and values in use
+}
+
+// namespaces returns the number of namespaces that contain attributes.
+func (u usage) namespaces() uint {
+	return uint(len(u.keys))
+}
+
+// apply returns the usage after the change described by d, where prev and
+// next are the attribute catalogs before and after the change.
+func (u usage) apply(prev, next attributes.Catalog, d *attributes.Diff) usage {
+	if d.IsEmpty() {
+		return u
+	}
+
+	keys := u.keys[d.Namespace]
+	seen := make(map[string]struct{}, len(d.VList))
+
+	for _, attr := range d.VList {
+		// an update may contain the same key more than once
+		if _, ok := seen[attr.Key]; ok {
+			continue
+		}
+		seen[attr.Key] = struct{}{}
+
+		if v := prev[d.Namespace][attr.Key].Value; v != "" {
+			keys--
+			u.size -= uint(len(attr.Key) + len(v))
+		}
+
+		if v := next[d.Namespace][attr.Key].Value; v != "" {
+			keys++
+			u.size += uint(len(attr.Key) + len(v))
+		}
+	}
+
+	m := make(map[string]uint, len(u.keys)+1)
+	for ns, n := range u.keys {
+		m[ns] = n
+	}
+
+	if keys == 0 {
+		delete(m, d.Namespace)
+	} else {
+		m[d.Namespace] = keys
+	}
+
+	u.keys = m
+
+	return u
+}
+
+// Usage returns the number of namespaces that contain attributes, and the
+// total size of their keys and values, as counted against the session's quota.
+func (s *Session) Usage() (namespaces, size uint) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.usage.namespaces(), s.usage.size
+}
+
+// checkQuota returns the usage after the change described by diffs, or an
+// error if it would exceed the session's quota. next is the attribute catalog
+// that would be in place at revision rev.
+// It assumes s.mutex is already locked.
+func (s *Session) checkQuota(
+	rev ident.Revision,
+	next attributes.Catalog,
+	diffs ...*attributes.Diff,
+) (usage, error) {
+	u := s.usage
+
+	for _, d := range diffs {
+		u = u.apply(s.attrs, next, d)
+
+		if s.quota.ValueSize != 0 {
+			for _, attr := range d.VList {
+				if uint(len(attr.Value)) > s.quota.ValueSize {
+					return u, s.quotaExceeded(rev, "value size")
+				}
+			}
+		}
+
+		if s.quota.KeysPerNamespace != 0 && u.keys[d.Namespace] > s.quota.KeysPerNamespace {
+			return u, s.quotaExceeded(rev, "keys per namespace")
+		}
+	}
+
+	if s.quota.Namespaces != 0 && u.namespaces() > s.quota.Namespaces {
+		return u, s.quotaExceeded(rev, "namespaces")
+	}
+
+	if s.quota.TotalSize != 0 && u.size > s.quota.TotalSize {
+		return u, s.quotaExceeded(rev, "total size")
+	}
+
+	return u, nil
+}
+
+// quotaExceeded logs and returns an error indicating that an update at
+// revision rev would exceed the quota described by limit.
+func (s *Session) quotaExceeded(rev ident.Revision, limit string) error {
+	logQuotaExceeded(s.logger, s.ref.ID.At(rev), limit)
+
+	return rinq.QuotaExceededError{Ref: s.ref, Limit: limit}
+}
@@ -0,0 +1,131 @@
+package localsession_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/rinq/rinq-go/src/internal/attributes"
+	. "github.com/rinq/rinq-go/src/internal/localsession"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/options"
+)
+
+var _ = Describe("Session quota", func() {
+	var subject *Session
+
+	newSession := func(quota options.QuotaPolicy) *Session {
+		return NewSession(
+			ident.NewPeerID().Session(1),
+			nil,
+			nil,
+			nil,
+			silentLogger{},
+			opentracing.NoopTracer{},
+			quota,
+		)
+	}
+
+	BeforeEach(func() {
+		subject = newSession(options.QuotaPolicy{})
+	})
+
+	Describe("Usage", func() {
+		It("counts non-empty attributes", func() {
+			_, _, err := subject.TryUpdate(0, "ns", attributes.List{
+				rinq.Set("a", "12"),
+				rinq.Set("b", ""),
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, _, err = subject.TryUpdateMany(1, map[string]attributes.List{
+				"other": {rinq.Set("c", "345")},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			namespaces, size := subject.Usage()
+			Expect(namespaces).To(Equal(uint(2)))
+			Expect(size).To(Equal(uint(7)))
+		})
+
+		It("counts an attribute that appears more than once in an update only once", func() {
+			_, _, err := subject.TryUpdate(0, "ns", attributes.List{
+				rinq.Set("a", "1"),
+				rinq.Set("a", "23"),
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, size := subject.Usage()
+			Expect(size).To(Equal(uint(3)))
+		})
+
+		It("is reduced when attributes are updated, cleared or emptied", func() {
+			_, _, err := subject.TryUpdate(0, "ns", attributes.List{
+				rinq.Set("a", "12"),
+				rinq.Set("b", "34"),
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, _, err = subject.TryUpdate(1, "ns", attributes.List{
+				rinq.Set("a", "1"),
+				rinq.Set("b", ""),
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			namespaces, size := subject.Usage()
+			Expect(namespaces).To(Equal(uint(1)))
+			Expect(size).To(Equal(uint(2)))
+
+			_, _, err = subject.TryClear(2, "ns")
+			Expect(err).NotTo(HaveOccurred())
+
+			namespaces, size = subject.Usage()
+			Expect(namespaces).To(BeZero())
+			Expect(size).To(BeZero())
+		})
+
+		It("is unchanged by a rejected update", func() {
+			subject = newSession(options.QuotaPolicy{TotalSize: 4})
+
+			_, _, err := subject.TryUpdate(0, "ns", attributes.List{
+				rinq.Set("a", "1"),
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, _, err = subject.TryUpdate(1, "ns", attributes.List{
+				rinq.Set("b", "234"),
+			})
+			Expect(err).To(BeAssignableToTypeOf(rinq.QuotaExceededError{}))
+
+			namespaces, size := subject.Usage()
+			Expect(namespaces).To(Equal(uint(1)))
+			Expect(size).To(Equal(uint(2)))
+		})
+	})
+
+	DescribeTable(
+		"rejects updates that exceed the quota",
+		func(quota options.QuotaPolicy, limit string) {
+			subject = newSession(quota)
+
+			_, _, err := subject.TryUpdate(0, "ns", attributes.List{
+				rinq.Set("a", "1"),
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, _, err = subject.TryUpdateMany(1, map[string]attributes.List{
+				"ns":    {rinq.Set("b", "23")},
+				"other": {rinq.Set("c", "4")},
+			})
+			Expect(err).To(Equal(rinq.QuotaExceededError{
+				Ref:   subject.ID().At(1),
+				Limit: limit,
+			}))
+		},
+		Entry("keys per namespace", options.QuotaPolicy{KeysPerNamespace: 1}, "keys per namespace"),
+		Entry("namespaces", options.QuotaPolicy{Namespaces: 1}, "namespaces"),
+		Entry("value size", options.QuotaPolicy{ValueSize: 1}, "value size"),
+		Entry("total size", options.QuotaPolicy{TotalSize: 6}, "total size"),
+	)
+})
@@ -6,6 +6,8 @@ import (
 	"github.com/jmalloc/twelf/src/twelf"
 	"github.com/rinq/rinq-go/src/internal/attributes"
 	"github.com/rinq/rinq-go/src/internal/namespaces"
+	"github.com/rinq/rinq-go/src/internal/revisions"
+	"github.com/rinq/rinq-go/src/internal/watch"
 	"github.com/rinq/rinq-go/src/rinq"
 	"github.com/rinq/rinq-go/src/rinq/ident"
 	"github.com/rinq/rinq-go/src/rinq/trace"
@@ -22,6 +24,12 @@ func (r *revision) SessionID() ident.SessionID {
 	return r.ref.ID
 }
 
+// Ref returns the session-ref of the revision. It is not part of the
+// rinq.Revision interface, see revisions.Number().
+func (r *revision) Ref() ident.Ref {
+	return r.ref
+}
+
 func (r *revision) Refresh(ctx context.Context) (rinq.Revision, error) {
 	return r.session.CurrentRevision(), nil
 }
@@ -72,8 +80,44 @@ func (r *revision) GetMany(ctx context.Context, ns string, keys ...string) (rinq
 	return table, nil
 }
 
+func (r *revision) Namespaces(ctx context.Context) ([]string, error) {
+	return r.attrs.NamespacesAt(r.ref.Rev), nil
+}
+
+func (r *revision) GetAll(ctx context.Context, ns string) (rinq.AttrTable, error) {
+	namespaces.MustValidate(ns)
+
+	table, ok := r.attrs[ns].At(r.ref.Rev)
+	if !ok {
+		return nil, rinq.StaleFetchError{Ref: r.ref}
+	}
+
+	return table, nil
+}
+
+func (r *revision) DiffSince(ctx context.Context, from rinq.Revision, ns string) (rinq.AttrTable, error) {
+	namespaces.MustValidate(ns)
+
+	fromRev, err := revisions.Number(r.ref.ID, from)
+	if err != nil {
+		return nil, err
+	}
+
+	if fromRev >= r.ref.Rev {
+		return attributes.Table{}, nil
+	}
+
+	table, ok := r.attrs[ns].ChangedBetween(fromRev, r.ref.Rev)
+	if !ok {
+		return nil, rinq.StaleFetchError{Ref: r.ref}
+	}
+
+	return table, nil
+}
+
 func (r *revision) Update(ctx context.Context, ns string, attrs ...rinq.Attr) (rinq.Revision, error) {
 	namespaces.MustValidate(ns)
+	attributes.List(attrs).MustValidate()
 
 	if len(attrs) == 0 {
 		return r, nil
@@ -89,6 +133,50 @@ func (r *revision) Update(ctx context.Context, ns string, attrs ...rinq.Attr) (r
 	return rev, nil
 }
 
+func (r *revision) UpdateIf(ctx context.Context, ns string, cond []rinq.AttrCondition, attrs ...rinq.Attr) (rinq.Revision, error) {
+	namespaces.MustValidate(ns)
+	attributes.List(attrs).MustValidate()
+
+	if len(attrs) == 0 {
+		return r, nil
+	}
+
+	rev, diff, err := r.session.TryUpdateIf(r.ref.Rev, ns, cond, attrs)
+	if err != nil {
+		return r, err
+	}
+
+	logUpdate(ctx, r.logger, r.ref.ID.At(diff.Revision), diff)
+
+	return rev, nil
+}
+
+func (r *revision) UpdateMany(ctx context.Context, attrs map[string][]rinq.Attr) (rinq.Revision, error) {
+	changes := make(map[string]attributes.List, len(attrs))
+
+	for ns, a := range attrs {
+		namespaces.MustValidate(ns)
+		attributes.List(a).MustValidate()
+
+		if len(a) != 0 {
+			changes[ns] = a
+		}
+	}
+
+	if len(changes) == 0 {
+		return r, nil
+	}
+
+	rev, diff, err := r.session.TryUpdateMany(r.ref.Rev, changes)
+	if err != nil {
+		return r, err
+	}
+
+	logUpdateMany(ctx, r.logger, r.ref.ID.At(diff.Revision), diff)
+
+	return rev, nil
+}
+
 func (r *revision) Clear(ctx context.Context, ns string) (rinq.Revision, error) {
 	namespaces.MustValidate(ns)
 
@@ -102,6 +190,24 @@ func (r *revision) Clear(ctx context.Context, ns string) (rinq.Revision, error)
 	return rev, nil
 }
 
+func (r *revision) Watch(ctx context.Context, ns string, keys ...string) (<-chan rinq.AttrChange, error) {
+	namespaces.MustValidate(ns)
+
+	w, err := r.session.Watch(r.ref.Rev, ns, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	logWatch(ctx, r.logger, r.ref, ns, keys)
+
+	return watch.Deliver(
+		ctx,
+		w,
+		r.session.ChangeRevision,
+		func() { r.session.Unwatch(w) },
+	), nil
+}
+
 func (r *revision) Destroy(ctx context.Context) error {
 	first, err := r.session.TryDestroy(r.ref.Rev)
 	if err != nil {
@@ -31,6 +31,44 @@ func logUpdate(
 	}
 }
 
+func logUpdateMany(
+	ctx context.Context,
+	logger twelf.Logger,
+	ref ident.Ref,
+	diff *attributes.MultiDiff,
+) {
+	if traceID := trace.Get(ctx); traceID != "" {
+		logger.Log(
+			"%s session updated %s [%s]",
+			ref.ShortString(),
+			diff,
+			traceID,
+		)
+	} else {
+		logger.Log(
+			"%s session updated %s",
+			ref.ShortString(),
+			diff,
+		)
+	}
+}
+
+func logWatch(
+	ctx context.Context,
+	logger twelf.Logger,
+	ref ident.Ref,
+	ns string,
+	keys []string,
+) {
+	logger.Debug(
+		"%s started watching '%s' namespace (keys: %v) [%s]",
+		ref.ShortString(),
+		ns,
+		keys,
+		trace.Get(ctx),
+	)
+}
+
 func logClear(
 	ctx context.Context,
 	logger twelf.Logger,
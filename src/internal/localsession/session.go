@@ -14,10 +14,12 @@ import (
 	"github.com/rinq/rinq-go/src/internal/notify"
 	"github.com/rinq/rinq-go/src/internal/opentr"
 	"github.com/rinq/rinq-go/src/internal/revisions"
+	"github.com/rinq/rinq-go/src/internal/watch"
 	"github.com/rinq/rinq-go/src/internal/x/syncx"
 	"github.com/rinq/rinq-go/src/rinq"
 	"github.com/rinq/rinq-go/src/rinq/constraint"
 	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/options"
 	"github.com/rinq/rinq-go/src/rinq/trace"
 )
 
@@ -33,13 +35,18 @@ type Session struct {
 	listener notify.Listener
 	logger   twelf.Logger
 	tracer   opentracing.Tracer
+	quota    options.QuotaPolicy
 
 	mutex       sync.RWMutex
 	ref         ident.Ref
 	msgSeq      uint32
 	isDestroyed bool
 	attrs       attributes.Catalog
+	usage       usage
 	calls       sync.WaitGroup
+	watchers    map[*watch.Watcher]struct{}
+	expiry      *time.Timer
+	index       *index
 	done        chan struct{}
 }
 
@@ -51,6 +58,7 @@ func NewSession(
 	listener notify.Listener,
 	logger twelf.Logger,
 	tracer opentracing.Tracer,
+	quota options.QuotaPolicy,
 ) *Session {
 	logCreated(logger, id)
 
@@ -60,6 +68,7 @@ func NewSession(
 		listener: listener,
 		logger:   logger,
 		tracer:   tracer,
+		quota:    quota,
 
 		ref:  id.At(0),
 		done: make(chan struct{}),
@@ -299,9 +308,180 @@ func (s *Session) NotifyMany(ctx context.Context, ns, t string, con constraint.C
 	return err
 }
 
+// NotifyManyRetained implements rinq.Session.NotifyManyRetained()
+func (s *Session) NotifyManyRetained(
+	ctx context.Context,
+	ns, t string,
+	con constraint.Constraint,
+	ttl time.Duration,
+	p *rinq.Payload,
+) error {
+	namespaces.MustValidate(ns)
+	if ttl <= 0 {
+		panic("retained notification TTL must be positive")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.isDestroyed {
+		return rinq.NotFoundError{ID: s.ref.ID}
+	}
+
+	msgID, traceID := s.nextMessageID(ctx)
+
+	span, ctx := opentr.ChildOf(ctx, s.tracer, ext.SpanKindProducer)
+	defer span.Finish()
+
+	opentr.SetupNotification(span, msgID, ns, t)
+	opentr.AddTraceID(span, traceID)
+	opentr.LogNotifierMulticastRetained(span, s.attrs, con, ttl, p)
+
+	err := s.notifier.NotifyMulticastRetained(ctx, msgID, traceID, con, ns, t, ttl, p)
+
+	if err != nil {
+		opentr.LogNotifierError(span, err)
+	}
+
+	logNotifyManyRetained(s.logger, msgID, ns, t, con, ttl, p, err, traceID)
+
+	return err
+}
+
+// NotifyEach implements rinq.Session.NotifyEach()
+func (s *Session) NotifyEach(ctx context.Context, ns, t string, targets []ident.SessionID, p *rinq.Payload) error {
+	namespaces.MustValidate(ns)
+	for _, target := range targets {
+		ident.MustValidate(target)
+		if target.Seq == 0 {
+			panic("can not send notifications to the zero-session")
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.isDestroyed {
+		return rinq.NotFoundError{ID: s.ref.ID}
+	}
+
+	if len(targets) == 0 {
+		return nil
+	}
+
+	msgID, traceID := s.nextMessageID(ctx)
+
+	span, ctx := opentr.ChildOf(ctx, s.tracer, ext.SpanKindProducer)
+	defer span.Finish()
+
+	opentr.SetupNotification(span, msgID, ns, t)
+	opentr.AddTraceID(span, traceID)
+	opentr.LogNotifierEach(span, s.attrs, targets, p)
+
+	err := s.notifier.NotifyEach(ctx, msgID, traceID, targets, ns, t, p)
+
+	if err != nil {
+		opentr.LogNotifierError(span, err)
+	}
+
+	logNotifyEach(s.logger, msgID, ns, t, targets, p, err, traceID)
+
+	return err
+}
+
+// NotifyConfirmed implements rinq.Session.NotifyConfirmed()
+func (s *Session) NotifyConfirmed(ctx context.Context, ns, t string, target ident.SessionID, p *rinq.Payload) (bool, error) {
+	namespaces.MustValidate(ns)
+	ident.MustValidate(target)
+	if target.Seq == 0 {
+		panic("can not send notifications to the zero-session")
+	}
+
+	// the lock is not held while waiting for confirmation, so that the
+	// session remains usable, including by its own notification handlers.
+	s.mutex.Lock()
+
+	if s.isDestroyed {
+		s.mutex.Unlock()
+		return false, rinq.NotFoundError{ID: s.ref.ID}
+	}
+
+	msgID, traceID := s.nextMessageID(ctx)
+
+	span, ctx := opentr.ChildOf(ctx, s.tracer, ext.SpanKindProducer)
+	defer span.Finish()
+
+	opentr.SetupNotification(span, msgID, ns, t)
+	opentr.AddTraceID(span, traceID)
+	opentr.LogNotifierUnicast(span, s.attrs, target, p)
+
+	s.mutex.Unlock()
+
+	handled, err := s.notifier.NotifyUnicastConfirmed(ctx, msgID, traceID, target, ns, t, p)
+
+	if err != nil {
+		opentr.LogNotifierError(span, err)
+	}
+
+	logNotifyConfirmed(s.logger, msgID, ns, t, target, p, handled, err, traceID)
+
+	return handled, err
+}
+
+// NotifyManyConfirmed implements rinq.Session.NotifyManyConfirmed()
+func (s *Session) NotifyManyConfirmed(ctx context.Context, ns, t string, con constraint.Constraint, p *rinq.Payload) (int, error) {
+	namespaces.MustValidate(ns)
+
+	// the lock is not held while waiting for confirmation, so that the
+	// session remains usable, including by its own notification handlers.
+	s.mutex.Lock()
+
+	if s.isDestroyed {
+		s.mutex.Unlock()
+		return 0, rinq.NotFoundError{ID: s.ref.ID}
+	}
+
+	msgID, traceID := s.nextMessageID(ctx)
+
+	span, ctx := opentr.ChildOf(ctx, s.tracer, ext.SpanKindProducer)
+	defer span.Finish()
+
+	opentr.SetupNotification(span, msgID, ns, t)
+	opentr.AddTraceID(span, traceID)
+	opentr.LogNotifierMulticast(span, s.attrs, con, p)
+
+	s.mutex.Unlock()
+
+	n, err := s.notifier.NotifyMulticastConfirmed(ctx, msgID, traceID, con, ns, t, p)
+
+	if err != nil {
+		opentr.LogNotifierError(span, err)
+	}
+
+	logNotifyManyConfirmed(s.logger, msgID, ns, t, con, p, n, err, traceID)
+
+	return n, err
+}
+
 // Listen implements rinq.Session.Listen()
 func (s *Session) Listen(ns string, h rinq.NotificationHandler) error {
-	namespaces.MustValidate(ns)
+	return s.listen(ns, nil, h)
+}
+
+// ListenTypes implements rinq.Session.ListenTypes()
+func (s *Session) ListenTypes(ns string, types []string, h rinq.NotificationHandler) error {
+	if len(types) == 0 {
+		panic("at least one notification type must be provided")
+	}
+
+	return s.listen(ns, types, h)
+}
+
+// listen starts listening for notifications in namespaces that match ns, with
+// one of the given types. If types is empty, notifications of any type are
+// accepted.
+func (s *Session) listen(ns string, types []string, h rinq.NotificationHandler) error {
+	namespaces.MustValidatePattern(ns)
 	if h == nil {
 		panic("handler must not be nil")
 	}
@@ -319,6 +499,7 @@ func (s *Session) Listen(ns string, h rinq.NotificationHandler) error {
 	changed, err := s.listener.Listen(
 		s.ref.ID,
 		ns,
+		types,
 		func(
 			ctx context.Context,
 			target rinq.Session,
@@ -345,7 +526,7 @@ func (s *Session) Listen(ns string, h rinq.NotificationHandler) error {
 	if err != nil {
 		return err
 	} else if changed {
-		logListen(s.logger, s.ref, ns)
+		logListen(s.logger, s.ref, ns, types)
 	}
 
 	return nil
@@ -353,7 +534,7 @@ func (s *Session) Listen(ns string, h rinq.NotificationHandler) error {
 
 // Unlisten implements rinq.Session.Unlisten()
 func (s *Session) Unlisten(ns string) error {
-	namespaces.MustValidate(ns)
+	namespaces.MustValidatePattern(ns)
 
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
@@ -2,6 +2,7 @@ package localsession
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/jmalloc/twelf/src/twelf"
@@ -221,6 +222,131 @@ func logNotifyMany(
 	)
 }
 
+func logNotifyManyRetained(
+	logger twelf.Logger,
+	msgID ident.MessageID,
+	ns string,
+	t string,
+	con constraint.Constraint,
+	ttl time.Duration,
+	out *rinq.Payload,
+	err error,
+	traceID string,
+) {
+	if err != nil {
+		return // request never sent
+	}
+
+	logger.Log(
+		"%s sent '%s::%s' notification to sessions matching %s, retained for %s (%d/o) [%s]",
+		msgID.ShortString(),
+		ns,
+		t,
+		con,
+		ttl,
+		out.Len(),
+		traceID,
+	)
+}
+
+func logNotifyEach(
+	logger twelf.Logger,
+	msgID ident.MessageID,
+	ns string,
+	t string,
+	targets []ident.SessionID,
+	out *rinq.Payload,
+	err error,
+	traceID string,
+) {
+	if err != nil {
+		return // request never sent
+	}
+
+	logger.Log(
+		"%s sent '%s::%s' notification to %d session(s) (%d/o) [%s]",
+		msgID.ShortString(),
+		ns,
+		t,
+		len(targets),
+		out.Len(),
+		traceID,
+	)
+}
+
+func logNotifyConfirmed(
+	logger twelf.Logger,
+	msgID ident.MessageID,
+	ns string,
+	t string,
+	target ident.SessionID,
+	out *rinq.Payload,
+	handled bool,
+	err error,
+	traceID string,
+) {
+	switch {
+	case err == nil && handled:
+		logger.Log(
+			"%s sent '%s::%s' notification to %s (%d/o), confirmed [%s]",
+			msgID.ShortString(),
+			ns,
+			t,
+			target.ShortString(),
+			out.Len(),
+			traceID,
+		)
+	case err == nil:
+		logger.Log(
+			"%s sent '%s::%s' notification to %s (%d/o), not handled [%s]",
+			msgID.ShortString(),
+			ns,
+			t,
+			target.ShortString(),
+			out.Len(),
+			traceID,
+		)
+	default:
+		logger.Log(
+			"%s sent '%s::%s' notification to %s (%d/o), not confirmed: %s [%s]",
+			msgID.ShortString(),
+			ns,
+			t,
+			target.ShortString(),
+			out.Len(),
+			err,
+			traceID,
+		)
+	}
+}
+
+func logNotifyManyConfirmed(
+	logger twelf.Logger,
+	msgID ident.MessageID,
+	ns string,
+	t string,
+	con constraint.Constraint,
+	out *rinq.Payload,
+	n int,
+	err error,
+	traceID string,
+) {
+	if err != nil {
+		return // request never sent
+	}
+
+	logger.Log(
+		"%s sent '%s::%s' notification to sessions matching %s (%d/o), handled by %d session(s) [%s]",
+		msgID.ShortString(),
+		ns,
+		t,
+		con,
+		out.Len(),
+		n,
+		traceID,
+	)
+}
+
 func logNotifyRecv(
 	logger twelf.Logger,
 	ref ident.Ref,
@@ -242,12 +368,22 @@ func logListen(
 	logger twelf.Logger,
 	ref ident.Ref,
 	ns string,
+	types []string,
 ) {
-	logger.Debug(
-		"%s started listening for notifications in '%s' namespace",
-		ref.ShortString(),
-		ns,
-	)
+	if len(types) == 0 {
+		logger.Debug(
+			"%s started listening for notifications in '%s' namespace",
+			ref.ShortString(),
+			ns,
+		)
+	} else {
+		logger.Debug(
+			"%s started listening for '%s' notifications in '%s' namespace",
+			ref.ShortString(),
+			strings.Join(types, "', '"),
+			ns,
+		)
+	}
 }
 
 func logUnlisten(
@@ -283,3 +419,27 @@ func logSessionDestroy(
 		)
 	}
 }
+
+func logExpired(
+	logger twelf.Logger,
+	ref ident.Ref,
+	diff *attributes.MultiDiff,
+) {
+	logger.Log(
+		"%s session attributes expired %s",
+		ref.ShortString(),
+		diff,
+	)
+}
+
+func logQuotaExceeded(
+	logger twelf.Logger,
+	ref ident.Ref,
+	limit string,
+) {
+	logger.Log(
+		"%s session update rejected, the %s quota would be exceeded",
+		ref.ShortString(),
+		limit,
+	)
+}
@@ -3,8 +3,12 @@ package localsession
 import (
 	"context"
 	"errors"
+	"sort"
+	"time"
 
 	"github.com/rinq/rinq-go/src/internal/attributes"
+	"github.com/rinq/rinq-go/src/internal/revisions"
+	"github.com/rinq/rinq-go/src/internal/watch"
 	"github.com/rinq/rinq-go/src/rinq"
 	"github.com/rinq/rinq-go/src/rinq/ident"
 	"github.com/rinq/rinq-go/src/rinq/trace"
@@ -51,7 +55,8 @@ func (s *Session) AttrsIn(ns string) (ident.Ref, attributes.VTable) {
 // table and returns the new head revision.
 //
 // The operation fails if ref is not the current session-ref, attrs includes
-// changes to frozen attributes, or the session has been destroyed.
+// changes to frozen attributes, the change exceeds the session's quota, or the
+// session has been destroyed.
 func (s *Session) TryUpdate(rev ident.Revision, ns string, attrs attributes.List) (rinq.Revision, *attributes.Diff, error) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
@@ -65,22 +70,210 @@ func (s *Session) TryUpdate(rev ident.Revision, ns string, attrs attributes.List
 	}
 
 	nextRev := rev + 1
+	nextAttrs, diff, err := s.applyUpdate(nextRev, ns, attrs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nextCatalog := s.attrs
+	nextUsage := s.usage
+	if !diff.IsEmpty() {
+		nextCatalog = nextCatalog.WithNamespace(ns, nextAttrs)
+
+		nextUsage, err = s.checkQuota(nextRev, nextCatalog, diff)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	s.ref.Rev = nextRev
+	s.msgSeq = 0
+	s.attrs = nextCatalog
+	s.usage = nextUsage
+
+	s.publish(diff)
+	s.scheduleExpiry()
+
+	return &revision{
+		s.ref,
+		s,
+		s.attrs,
+		s.logger,
+	}, diff, nil
+}
+
+// TryUpdateIf adds or updates attributes in the ns namespace of the attribute
+// table and returns the new head revision, provided that each condition in
+// cond holds for the attributes in ns. Conditions requiring an attribute to be
+// unchanged are checked against rev, which need not be the current revision.
+//
+// The operation fails if any condition does not hold, attrs includes changes
+// to frozen attributes, the change exceeds the session's quota, or the session
+// has been destroyed.
+func (s *Session) TryUpdateIf(
+	rev ident.Revision,
+	ns string,
+	cond []rinq.AttrCondition,
+	attrs attributes.List,
+) (rinq.Revision, *attributes.Diff, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.isDestroyed {
+		return nil, nil, rinq.NotFoundError{ID: s.ref.ID}
+	}
+
+	if s.ref.Rev < rev {
+		return nil, nil, errors.New("revision is from the future")
+	}
+
+	current := s.attrs[ns]
+
+	for _, c := range cond {
+		entry := current[c.Key]
+
+		if c.IsUnchanged {
+			if entry.UpdatedAt > rev {
+				return nil, nil, rinq.StaleUpdateError{Ref: s.ref.ID.At(rev)}
+			}
+		} else if entry.Value != c.Value {
+			return nil, nil, rinq.StaleUpdateError{Ref: s.ref.ID.At(rev)}
+		}
+	}
+
+	nextRev := s.ref.Rev + 1
+	nextAttrs, diff, err := s.applyUpdate(nextRev, ns, attrs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nextCatalog := s.attrs
+	nextUsage := s.usage
+	if !diff.IsEmpty() {
+		nextCatalog = nextCatalog.WithNamespace(ns, nextAttrs)
+
+		nextUsage, err = s.checkQuota(nextRev, nextCatalog, diff)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	s.ref.Rev = nextRev
+	s.msgSeq = 0
+	s.attrs = nextCatalog
+	s.usage = nextUsage
+
+	s.publish(diff)
+	s.scheduleExpiry()
+
+	return &revision{
+		s.ref,
+		s,
+		s.attrs,
+		s.logger,
+	}, diff, nil
+}
+
+// TryUpdateMany adds or updates attributes in multiple namespaces of the
+// attribute table and returns the new head revision. All of the changes are
+// made in a single revision.
+//
+// The operation fails if ref is not the current session-ref, attrs includes
+// changes to frozen attributes, the change exceeds the session's quota, or the
+// session has been destroyed.
+func (s *Session) TryUpdateMany(rev ident.Revision, attrs map[string]attributes.List) (rinq.Revision, *attributes.MultiDiff, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.isDestroyed {
+		return nil, nil, rinq.NotFoundError{ID: s.ref.ID}
+	}
+
+	if rev != s.ref.Rev {
+		return nil, nil, rinq.StaleUpdateError{Ref: s.ref.ID.At(rev)}
+	}
+
+	// Sort the namespaces so that the diffs are always in the same order.
+	names := make([]string, 0, len(attrs))
+	for ns := range attrs {
+		names = append(names, ns)
+	}
+	sort.Strings(names)
+
+	nextRev := rev + 1
+	nextCatalog := s.attrs
+	diff := attributes.NewMultiDiff(nextRev)
+
+	for _, ns := range names {
+		nextAttrs, d, err := s.applyUpdate(nextRev, ns, attrs[ns])
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if !d.IsEmpty() {
+			nextCatalog = nextCatalog.WithNamespace(ns, nextAttrs)
+		}
+
+		diff.Add(d)
+	}
+
+	nextUsage := s.usage
+	if !diff.IsEmpty() {
+		var err error
+		nextUsage, err = s.checkQuota(nextRev, nextCatalog, diff.Diffs...)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	s.ref.Rev = nextRev
+	s.msgSeq = 0
+	s.attrs = nextCatalog
+	s.usage = nextUsage
+
+	s.publishMany(diff)
+	s.scheduleExpiry()
+
+	return &revision{
+		s.ref,
+		s,
+		s.attrs,
+		s.logger,
+	}, diff, nil
+}
+
+// applyUpdate returns the attributes in the ns namespace after applying attrs
+// at the revision nextRev, without modifying the session.
+// It assumes s.mutex is already locked.
+func (s *Session) applyUpdate(
+	nextRev ident.Revision,
+	ns string,
+	attrs attributes.List,
+) (attributes.VTable, *attributes.Diff, error) {
 	nextAttrs := s.attrs[ns].Clone()
 	diff := attributes.NewDiff(ns, nextRev)
 
 	for _, attr := range attrs {
 		entry, exists := nextAttrs[attr.Key]
 
-		if attr.Value == entry.Value && attr.IsFrozen == entry.IsFrozen {
+		if attr.Value == entry.Value &&
+			attr.Type == entry.Type &&
+			attr.IsFrozen == entry.IsFrozen &&
+			attr.TTL == 0 &&
+			entry.TTL == 0 {
 			continue
 		}
 
 		if entry.IsFrozen {
-			return nil, nil, rinq.FrozenAttributesError{Ref: s.ref.ID.At(rev)}
+			return nil, nil, rinq.FrozenAttributesError{Ref: s.ref}
 		}
 
 		entry.Attr = attr
 		entry.UpdatedAt = nextRev
+		entry.ExpiresAt = time.Time{}
+		if attr.TTL > 0 && attr.Value != "" {
+			entry.ExpiresAt = time.Now().Add(attr.TTL)
+		}
 		if !exists {
 			entry.CreatedAt = nextRev
 		}
@@ -89,19 +282,7 @@ func (s *Session) TryUpdate(rev ident.Revision, ns string, attrs attributes.List
 		diff.Append(entry)
 	}
 
-	s.ref.Rev = nextRev
-	s.msgSeq = 0
-
-	if !diff.IsEmpty() {
-		s.attrs = s.attrs.WithNamespace(ns, nextAttrs)
-	}
-
-	return &revision{
-		s.ref,
-		s,
-		s.attrs,
-		s.logger,
-	}, diff, nil
+	return nextAttrs, diff, nil
 }
 
 // TryClear updates all attributes in the ns namespace of the attribute
@@ -133,6 +314,8 @@ func (s *Session) TryClear(rev ident.Revision, ns string) (rinq.Revision, *attri
 			}
 
 			entry.Value = ""
+			entry.TTL = 0
+			entry.ExpiresAt = time.Time{}
 			entry.UpdatedAt = nextRev
 			diff.Append(entry)
 		}
@@ -144,9 +327,14 @@ func (s *Session) TryClear(rev ident.Revision, ns string) (rinq.Revision, *attri
 	s.msgSeq = 0
 
 	if !diff.IsEmpty() {
-		s.attrs = s.attrs.WithNamespace(ns, nextAttrs)
+		nextCatalog := s.attrs.WithNamespace(ns, nextAttrs)
+		s.usage = s.usage.apply(s.attrs, nextCatalog, diff)
+		s.attrs = nextCatalog
 	}
 
+	s.publish(diff)
+	s.scheduleExpiry()
+
 	return &revision{
 		s.ref,
 		s,
@@ -155,6 +343,93 @@ func (s *Session) TryClear(rev ident.Revision, ns string) (rinq.Revision, *attri
 	}, diff, nil
 }
 
+// Watch returns a watcher for changes to the attributes in the ns namespace
+// with the given keys, made after rev. The watcher must be removed by calling
+// Unwatch() when it is no longer required.
+//
+// If rev is not the current revision, the watcher's first change describes
+// the watched attributes that have been modified since rev.
+//
+// The operation fails if the session has been destroyed.
+func (s *Session) Watch(rev ident.Revision, ns string, keys []string) (*watch.Watcher, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.isDestroyed {
+		return nil, rinq.NotFoundError{ID: s.ref.ID}
+	}
+
+	if s.ref.Rev < rev {
+		return nil, errors.New("revision is from the future")
+	}
+
+	w := watch.NewWatcher(ns, keys)
+
+	if rev < s.ref.Rev {
+		var attrs attributes.VList
+
+		for _, attr := range s.attrs[ns] {
+			if attr.UpdatedAt > rev {
+				attrs = append(attrs, attr)
+			}
+		}
+
+		w.Push(watch.Change{
+			Ref:       s.ref,
+			Namespace: ns,
+			Attrs:     attrs,
+		})
+	}
+
+	s.addWatcher(w)
+
+	return w, nil
+}
+
+// WatchAll returns a watcher for every change made to the session after the
+// current revision, in any namespace. The watcher must be removed by calling
+// Unwatch() when it is no longer required.
+//
+// The operation fails if the session has been destroyed.
+func (s *Session) WatchAll() (*watch.Watcher, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.isDestroyed {
+		return nil, rinq.NotFoundError{ID: s.ref.ID}
+	}
+
+	w := watch.NewWatcher("", nil)
+	s.addWatcher(w)
+
+	return w, nil
+}
+
+// Unwatch removes a watcher that was returned by Watch().
+func (s *Session) Unwatch(w *watch.Watcher) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.watchers, w)
+}
+
+// ChangeRevision returns the revision at which the watched change c occurred.
+func (s *Session) ChangeRevision(c watch.Change) rinq.Revision {
+	if c.IsDestroyed {
+		return revisions.Closed(c.Ref.ID)
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return &revision{
+		c.Ref,
+		s,
+		s.attrs,
+		s.logger,
+	}
+}
+
 // TryDestroy destroys the session, preventing further updates.
 //
 // The operation fails if ref is not the current session-ref. It is not an
@@ -183,6 +458,19 @@ func (s *Session) TryDestroy(rev ident.Revision) (bool, error) {
 func (s *Session) destroy() {
 	s.isDestroyed = true
 
+	if s.expiry != nil {
+		s.expiry.Stop()
+		s.expiry = nil
+	}
+
+	for w := range s.watchers {
+		w.Push(watch.Change{
+			Ref:         s.ref,
+			IsDestroyed: true,
+		})
+	}
+	s.watchers = nil
+
 	s.invoker.SetAsyncHandler(s.ref.ID, nil)
 	_ = s.listener.UnlistenAll(s.ref.ID)
 
@@ -193,6 +481,134 @@ func (s *Session) destroy() {
 	}()
 }
 
+// setIndex sets the index that is updated when the session's attributes
+// change, and adds the current attributes to it.
+func (s *Session) setIndex(idx *index) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.index = idx
+	idx.Add(s, s.ref.ID, s.attrs)
+}
+
+// addWatcher adds w to the session's watchers.
+// It assumes s.mutex is already locked.
+func (s *Session) addWatcher(w *watch.Watcher) {
+	if s.watchers == nil {
+		s.watchers = map[*watch.Watcher]struct{}{}
+	}
+
+	s.watchers[w] = struct{}{}
+}
+
+// publish queues the change described by diff for delivery to the watchers.
+// It assumes s.mutex is already locked.
+func (s *Session) publish(diff *attributes.Diff) {
+	md := attributes.NewMultiDiff(diff.Revision)
+	md.Add(diff)
+
+	s.publishMany(md)
+}
+
+// publishMany updates the store's index and queues the change described by
+// diff for delivery to the watchers. It assumes s.mutex is already locked.
+func (s *Session) publishMany(diff *attributes.MultiDiff) {
+	if s.index != nil {
+		s.index.Update(s, s.ref.ID, diff)
+	}
+
+	for w := range s.watchers {
+		w.PushDiff(s.ref, diff)
+	}
+}
+
+// scheduleExpiry arranges for expire() to be called when the attribute with
+// the earliest expiry time expires. It assumes s.mutex is already locked.
+func (s *Session) scheduleExpiry() {
+	var next time.Time
+
+	for _, attrs := range s.attrs {
+		for _, attr := range attrs {
+			if attr.ExpiresAt.IsZero() {
+				continue
+			}
+
+			if next.IsZero() || attr.ExpiresAt.Before(next) {
+				next = attr.ExpiresAt
+			}
+		}
+	}
+
+	if s.expiry != nil {
+		s.expiry.Stop()
+		s.expiry = nil
+	}
+
+	if !next.IsZero() {
+		s.expiry = time.AfterFunc(time.Until(next), s.expire)
+	}
+}
+
+// expire clears any attributes that have outlived their TTL, producing a new
+// revision if any attributes were cleared.
+func (s *Session) expire() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.isDestroyed {
+		return
+	}
+
+	now := time.Now()
+	nextRev := s.ref.Rev + 1
+	nextCatalog := s.attrs
+	diff := attributes.NewMultiDiff(nextRev)
+
+	for _, ns := range s.attrs.NamespacesAt(s.ref.Rev) {
+		var nextAttrs attributes.VTable
+		d := attributes.NewDiff(ns, nextRev)
+
+		for _, entry := range s.attrs[ns] {
+			if entry.ExpiresAt.IsZero() || entry.ExpiresAt.After(now) {
+				continue
+			}
+
+			if nextAttrs == nil {
+				nextAttrs = s.attrs[ns].Clone()
+			}
+
+			entry.Value = ""
+			entry.TTL = 0
+			entry.ExpiresAt = time.Time{}
+			entry.UpdatedAt = nextRev
+
+			nextAttrs[entry.Key] = entry
+			d.Append(entry)
+		}
+
+		if !d.IsEmpty() {
+			nextCatalog = nextCatalog.WithNamespace(ns, nextAttrs)
+			diff.Add(d)
+		}
+	}
+
+	if !diff.IsEmpty() {
+		for _, d := range diff.Diffs {
+			s.usage = s.usage.apply(s.attrs, nextCatalog, d)
+		}
+
+		s.ref.Rev = nextRev
+		s.msgSeq = 0
+		s.attrs = nextCatalog
+
+		logExpired(s.logger, s.ref, diff)
+
+		s.publishMany(diff)
+	}
+
+	s.scheduleExpiry()
+}
+
 // nextMessageID returns a new unique message ID generated from the current
 // session-ref.
 //
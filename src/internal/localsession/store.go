@@ -5,6 +5,7 @@ import (
 
 	"github.com/rinq/rinq-go/src/internal/revisions"
 	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/constraint"
 	"github.com/rinq/rinq-go/src/rinq/ident"
 )
 
@@ -13,17 +14,21 @@ import (
 type Store struct {
 	mutex    sync.RWMutex
 	sessions map[ident.SessionID]*Session
+	index    *index
 }
 
 // NewStore returns a new session store.
 func NewStore() *Store {
 	return &Store{
 		sessions: map[ident.SessionID]*Session{},
+		index:    newIndex(),
 	}
 }
 
 // Add adds a session to the store.
 func (s *Store) Add(sess *Session) {
+	sess.setIndex(s.index)
+
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
@@ -36,6 +41,7 @@ func (s *Store) Remove(id ident.SessionID) {
 	defer s.mutex.Unlock()
 
 	delete(s.sessions, id)
+	s.index.Remove(id)
 }
 
 // Get fetches a session from the store by its ID.
@@ -57,6 +63,38 @@ func (s *Store) Each(fn func(*Session)) {
 	}
 }
 
+// EachMatching calls fn(sess) for each session in the store with attributes
+// that match con, using ns as the default namespace.
+//
+// An index of attribute values is used to avoid evaluating con against every
+// session when con requires specific values, such as with constraint.Equal().
+func (s *Store) EachMatching(ns string, con constraint.Constraint, fn func(*Session)) {
+	candidates, ok := s.index.Candidates(ns, con)
+
+	if !ok {
+		s.mutex.RLock()
+		candidates = make([]*Session, 0, len(s.sessions))
+		for _, sess := range s.sessions {
+			candidates = append(candidates, sess)
+		}
+		s.mutex.RUnlock()
+	}
+
+	for _, sess := range candidates {
+		_, attrs := sess.Attrs()
+		if attrs.MatchConstraint(ns, con) {
+			fn(sess)
+		}
+	}
+}
+
+// SetIndexObserver sets the observer that is notified when attribute values
+// are added to or removed from the store's index. o.Indexed() is called for
+// each value that is already indexed.
+func (s *Store) SetIndexObserver(o IndexObserver) {
+	s.index.SetObserver(o)
+}
+
 // GetRevision returns the session revision for the given ref.
 func (s *Store) GetRevision(ref ident.Ref) (rinq.Revision, error) {
 	s.mutex.RLock()
@@ -0,0 +1,78 @@
+package localsession_test
+
+import (
+	"strconv"
+	"testing"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/rinq/rinq-go/src/internal/attributes"
+	"github.com/rinq/rinq-go/src/internal/localsession"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/constraint"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/options"
+)
+
+// benchmarkStore returns a store containing n sessions, each with a unique
+// "user" attribute and one of ten "group" attributes.
+func benchmarkStore(b *testing.B, n int) *localsession.Store {
+	store := localsession.NewStore()
+	peerID := ident.NewPeerID()
+
+	for i := 0; i < n; i++ {
+		sess := localsession.NewSession(
+			peerID.Session(uint32(i+1)),
+			nil,
+			nil,
+			nil,
+			silentLogger{},
+			opentracing.NoopTracer{},
+			options.QuotaPolicy{},
+		)
+
+		store.Add(sess)
+
+		_, _, err := sess.TryUpdate(0, "ns", attributes.List{
+			rinq.Set("user", strconv.Itoa(i)),
+			rinq.Set("group", strconv.Itoa(i%10)),
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	return store
+}
+
+func benchmarkEachMatching(b *testing.B, n int, con constraint.Constraint) {
+	store := benchmarkStore(b, n)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		store.EachMatching("ns", con, func(*localsession.Session) {})
+	}
+}
+
+func BenchmarkEachMatching_Indexed_1k(b *testing.B) {
+	benchmarkEachMatching(b, 1000, constraint.Equal("user", "500"))
+}
+
+func BenchmarkEachMatching_Indexed_100k(b *testing.B) {
+	benchmarkEachMatching(b, 100000, constraint.Equal("user", "500"))
+}
+
+func BenchmarkEachMatching_IndexedAnd_100k(b *testing.B) {
+	benchmarkEachMatching(b, 100000, constraint.And(
+		constraint.Equal("group", "0"),
+		constraint.Equal("user", "500"),
+	))
+}
+
+func BenchmarkEachMatching_Scan_1k(b *testing.B) {
+	benchmarkEachMatching(b, 1000, constraint.Prefix("user", "500"))
+}
+
+func BenchmarkEachMatching_Scan_100k(b *testing.B) {
+	benchmarkEachMatching(b, 100000, constraint.Prefix("user", "500"))
+}
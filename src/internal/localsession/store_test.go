@@ -0,0 +1,189 @@
+package localsession_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/rinq/rinq-go/src/internal/attributes"
+	. "github.com/rinq/rinq-go/src/internal/localsession"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/constraint"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/options"
+)
+
+var _ = Describe("Store", func() {
+	var (
+		store      *Store
+		sess1      *Session
+		sess2      *Session
+		sess3      *Session
+		peerID     = ident.NewPeerID()
+		sessionSeq uint32
+	)
+
+	newSession := func(attrs ...rinq.Attr) *Session {
+		sessionSeq++
+
+		sess := NewSession(
+			peerID.Session(sessionSeq),
+			nil,
+			nil,
+			nil,
+			silentLogger{},
+			opentracing.NoopTracer{},
+			options.QuotaPolicy{},
+		)
+
+		store.Add(sess)
+
+		if len(attrs) != 0 {
+			_, _, err := sess.TryUpdate(0, "ns", attrs)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		return sess
+	}
+
+	matching := func(con constraint.Constraint) []*Session {
+		var sessions []*Session
+		store.EachMatching("ns", con, func(sess *Session) {
+			sessions = append(sessions, sess)
+		})
+		return sessions
+	}
+
+	BeforeEach(func() {
+		store = NewStore()
+		sess1 = newSession(rinq.Set("role", "admin"), rinq.Set("region", "eu-west"))
+		sess2 = newSession(rinq.Set("role", "ops"), rinq.Set("region", "eu-west"))
+		sess3 = newSession(rinq.Set("role", "admin"), rinq.Set("region", "us-east"))
+	})
+
+	Describe("EachMatching", func() {
+		It("finds sessions using an equality constraint", func() {
+			Expect(matching(constraint.Equal("role", "admin"))).To(ConsistOf(sess1, sess3))
+		})
+
+		It("finds sessions using a compound constraint", func() {
+			con := constraint.And(
+				constraint.Equal("role", "admin"),
+				constraint.Prefix("region", "eu-"),
+			)
+
+			Expect(matching(con)).To(ConsistOf(sess1))
+		})
+
+		It("finds sessions using set membership", func() {
+			Expect(matching(constraint.In("role", "admin", "ops"))).To(ConsistOf(sess1, sess2, sess3))
+		})
+
+		It("finds sessions within another namespace", func() {
+			sess := newSession()
+			_, _, err := sess.TryUpdate(0, "other", attributes.List{rinq.Set("role", "admin")})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(matching(constraint.Within("other", constraint.Equal("role", "admin")))).To(ConsistOf(sess))
+		})
+
+		It("finds sessions using constraints that are not indexed", func() {
+			Expect(matching(constraint.NotEqual("role", "admin"))).To(ConsistOf(sess2))
+		})
+
+		It("finds sessions without the attribute when matching an empty value", func() {
+			sess := newSession()
+
+			Expect(matching(constraint.Empty("role"))).To(ConsistOf(sess))
+		})
+
+		It("reflects updates to the session", func() {
+			_, _, err := sess2.TryUpdate(1, "ns", attributes.List{rinq.Set("role", "admin")})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(matching(constraint.Equal("role", "admin"))).To(ConsistOf(sess1, sess2, sess3))
+			Expect(matching(constraint.Equal("role", "ops"))).To(BeEmpty())
+		})
+
+		It("reflects cleared attributes", func() {
+			_, _, err := sess1.TryClear(1, "ns")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(matching(constraint.Equal("role", "admin"))).To(ConsistOf(sess3))
+		})
+
+		It("does not find sessions that have been removed", func() {
+			store.Remove(sess1.ID())
+
+			Expect(matching(constraint.Equal("role", "admin"))).To(ConsistOf(sess3))
+		})
+	})
+
+	Describe("SetIndexObserver", func() {
+		var observer *recordingObserver
+
+		BeforeEach(func() {
+			observer = &recordingObserver{}
+			store.SetIndexObserver(observer)
+		})
+
+		It("notifies the observer of values that are already indexed", func() {
+			Expect(observer.indexed).To(ConsistOf(
+				"ns::role=admin",
+				"ns::role=ops",
+				"ns::region=eu-west",
+				"ns::region=us-east",
+			))
+		})
+
+		It("notifies the observer when a value is first indexed", func() {
+			observer.indexed = nil
+
+			newSession(rinq.Set("role", "admin"), rinq.Set("team", "blue"))
+
+			Expect(observer.indexed).To(ConsistOf("ns::team=blue"))
+		})
+
+		It("notifies the observer when the last session with a value is removed", func() {
+			store.Remove(sess1.ID())
+			Expect(observer.unindexed).To(BeEmpty())
+
+			store.Remove(sess3.ID())
+			Expect(observer.unindexed).To(ConsistOf(
+				"ns::role=admin",
+				"ns::region=us-east",
+			))
+		})
+
+		It("notifies the observer when a value is changed", func() {
+			_, _, err := sess2.TryUpdate(1, "ns", attributes.List{rinq.Set("role", "dev")})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(observer.indexed).To(ContainElement("ns::role=dev"))
+			Expect(observer.unindexed).To(ConsistOf("ns::role=ops"))
+		})
+	})
+})
+
+// recordingObserver is an IndexObserver that records the values it is
+// notified of.
+type recordingObserver struct {
+	indexed   []string
+	unindexed []string
+}
+
+func (o *recordingObserver) Indexed(ns, k, v string) {
+	o.indexed = append(o.indexed, ns+"::"+k+"="+v)
+}
+
+func (o *recordingObserver) Unindexed(ns, k, v string) {
+	o.unindexed = append(o.unindexed, ns+"::"+k+"="+v)
+}
+
+// silentLogger is a twelf.Logger that discards all output.
+type silentLogger struct{}
+
+func (silentLogger) Log(string, ...interface{})   {}
+func (silentLogger) LogString(string)             {}
+func (silentLogger) Debug(string, ...interface{}) {}
+func (silentLogger) DebugString(string)           {}
+func (silentLogger) IsDebug() bool                { return false }
@@ -0,0 +1,120 @@
+package namespaces
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// SingleWildcard is a pattern segment that matches exactly one segment of
+	// a namespace.
+	SingleWildcard = "*"
+
+	// MultiWildcard is a pattern segment that matches zero or more segments
+	// of a namespace.
+	MultiWildcard = "#"
+)
+
+// ValidatePattern checks if p is a valid namespace pattern.
+//
+// A pattern is a namespace in which any of the period-separated segments may
+// be a wildcard. The "*" wildcard matches exactly one segment, and the "#"
+// wildcard matches zero or more segments. For example, the pattern "chat.*"
+// matches the "chat.lobby" namespace, but not "chat" or "chat.lobby.v1".
+//
+// A namespace without wildcards is also a valid pattern, which matches only
+// that namespace.
+//
+// The return value is nil if p is a valid pattern that does not match any
+// reserved namespaces.
+func ValidatePattern(p string) error {
+	if !IsPattern(p) {
+		return Validate(p)
+	}
+
+	if p[0] == '_' {
+		return fmt.Errorf("namespace pattern '%s' is reserved", p)
+	}
+
+	// a leading wildcard would match reserved namespaces
+	if strings.HasPrefix(p, SingleWildcard) || strings.HasPrefix(p, MultiWildcard) {
+		return fmt.Errorf("namespace pattern '%s' must not begin with a wildcard", p)
+	}
+
+	for _, seg := range strings.Split(p, ".") {
+		if seg != SingleWildcard && seg != MultiWildcard && !segmentPattern.MatchString(seg) {
+			return fmt.Errorf("namespace pattern '%s' contains invalid characters", p)
+		}
+	}
+
+	return nil
+}
+
+// MustValidatePattern panics if p is invalid.
+func MustValidatePattern(p string) {
+	if err := ValidatePattern(p); err != nil {
+		panic(err)
+	}
+}
+
+// IsPattern returns true if p contains any wildcard segments.
+func IsPattern(p string) bool {
+	for _, seg := range strings.Split(p, ".") {
+		if seg == SingleWildcard || seg == MultiWildcard {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Match returns true if the namespace ns matches the pattern p.
+func Match(p, ns string) bool {
+	if !IsPattern(p) {
+		return p == ns
+	}
+
+	return matchSegments(
+		strings.Split(p, "."),
+		strings.Split(ns, "."),
+	)
+}
+
+// matchSegments returns true if the namespace segments in ns match the pattern
+// segments in p.
+func matchSegments(p, ns []string) bool {
+	for len(p) != 0 {
+		switch p[0] {
+		case MultiWildcard:
+			for i := 0; i <= len(ns); i++ {
+				if matchSegments(p[1:], ns[i:]) {
+					return true
+				}
+			}
+
+			return false
+
+		case SingleWildcard:
+			if len(ns) == 0 {
+				return false
+			}
+
+		default:
+			if len(ns) == 0 || p[0] != ns[0] {
+				return false
+			}
+		}
+
+		p = p[1:]
+		ns = ns[1:]
+	}
+
+	return len(ns) == 0
+}
+
+var segmentPattern *regexp.Regexp
+
+func init() {
+	segmentPattern = regexp.MustCompile(`^[A-Za-z0-9_\-:]*$`)
+}
@@ -0,0 +1,46 @@
+package namespaces_test
+
+import (
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/rinq/rinq-go/src/internal/namespaces"
+)
+
+var _ = DescribeTable(
+	"ValidatePattern",
+	func(pattern string, expected string) {
+		err := namespaces.ValidatePattern(pattern)
+
+		if expected == "" {
+			Expect(err).ShouldNot(HaveOccurred())
+		} else {
+			Expect(err.Error()).To(Equal(expected))
+		}
+	},
+	Entry("namespace", "foo.bar.v1", ""),
+	Entry("single wildcard", "foo.*", ""),
+	Entry("multi wildcard", "foo.#", ""),
+	Entry("inner wildcard", "foo.*.v1", ""),
+	Entry("empty", "", "namespace must not be empty"),
+	Entry("reserved", "_foo.*", "namespace pattern '_foo.*' is reserved"),
+	Entry("leading wildcard", "*.foo", "namespace pattern '*.foo' must not begin with a wildcard"),
+	Entry("partial wildcard", "foo.b*", "namespace 'foo.b*' contains invalid characters"),
+	Entry("partial wildcard with other wildcards", "foo.*.b*", "namespace pattern 'foo.*.b*' contains invalid characters"),
+)
+
+var _ = DescribeTable(
+	"Match",
+	func(pattern, ns string, expected bool) {
+		Expect(namespaces.Match(pattern, ns)).To(Equal(expected))
+	},
+	Entry("equal namespace", "foo.bar", "foo.bar", true),
+	Entry("different namespace", "foo.bar", "foo.baz", false),
+	Entry("single wildcard", "foo.*", "foo.bar", true),
+	Entry("single wildcard with no segment", "foo.*", "foo", false),
+	Entry("single wildcard with many segments", "foo.*", "foo.bar.baz", false),
+	Entry("inner single wildcard", "foo.*.v1", "foo.bar.v1", true),
+	Entry("multi wildcard with no segment", "foo.#", "foo", true),
+	Entry("multi wildcard with many segments", "foo.#", "foo.bar.baz", true),
+	Entry("inner multi wildcard", "foo.#.v1", "foo.bar.baz.v1", true),
+	Entry("inner multi wildcard mismatch", "foo.#.v1", "foo.bar.baz.v2", false),
+)
@@ -10,7 +10,10 @@ import (
 type Listener interface {
 	service.Service
 
-	Listen(id ident.SessionID, ns string, h rinq.NotificationHandler) (bool, error)
+	Listen(id ident.SessionID, ns string, types []string, h rinq.NotificationHandler) (bool, error)
 	Unlisten(id ident.SessionID, ns string) (bool, error)
 	UnlistenAll(id ident.SessionID) error
+
+	Subscribe(topic string, h rinq.PublicationHandler) (bool, error)
+	Unsubscribe(topic string) (bool, error)
 }
@@ -2,6 +2,7 @@ package notify
 
 import (
 	"context"
+	"time"
 
 	"github.com/rinq/rinq-go/src/rinq"
 	"github.com/rinq/rinq-go/src/rinq/constraint"
@@ -31,4 +32,76 @@ type Notifier interface {
 		t string,
 		out *rinq.Payload,
 	) error
+
+	// NotifyMulticastRetained sends a notification to all sessions matching a
+	// constraint, and retains it so that it is replayed to matching sessions
+	// that start listening before ttl elapses.
+	NotifyMulticastRetained(
+		ctx context.Context,
+		msgID ident.MessageID,
+		traceID string,
+		con constraint.Constraint,
+		ns string,
+		t string,
+		ttl time.Duration,
+		out *rinq.Payload,
+	) error
+
+	// NotifyEach sends a notification to each of the given sessions, sending
+	// a single message to each peer that owns one or more of the sessions.
+	NotifyEach(
+		ctx context.Context,
+		msgID ident.MessageID,
+		traceID string,
+		targets []ident.SessionID,
+		ns string,
+		t string,
+		out *rinq.Payload,
+	) error
+
+	// NotifyUnicastConfirmed sends a notification to a specific session and
+	// waits for confirmation that it has been handled. handled is false if the
+	// session is not listening for the notification.
+	NotifyUnicastConfirmed(
+		ctx context.Context,
+		msgID ident.MessageID,
+		traceID string,
+		s ident.SessionID,
+		ns string,
+		t string,
+		out *rinq.Payload,
+	) (handled bool, err error)
+
+	// NotifyMulticastConfirmed sends a notification to all sessions matching a
+	// constraint and returns the number of sessions that handled it before
+	// the deadline.
+	NotifyMulticastConfirmed(
+		ctx context.Context,
+		msgID ident.MessageID,
+		traceID string,
+		con constraint.Constraint,
+		ns string,
+		t string,
+		out *rinq.Payload,
+	) (n int, err error)
+
+	// Publish sends a message to all peers that are subscribed to a topic.
+	Publish(
+		ctx context.Context,
+		msgID ident.MessageID,
+		traceID string,
+		topic string,
+		out *rinq.Payload,
+	) error
+
+	// PublishConfirmed sends a message to all peers that are subscribed to a
+	// topic and returns the number of peers that handled it before the
+	// deadline.
+	PublishConfirmed(
+		ctx context.Context,
+		msgID ident.MessageID,
+		traceID string,
+		topic string,
+		out *rinq.Payload,
+	) (n int, err error)
 }
@@ -1,13 +1,17 @@
 package opentr
 
 import (
+	"fmt"
+
 	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
 	"github.com/opentracing/opentracing-go/log"
 )
 
 var (
 	successEvent = log.String("event", "success")
 	errorEvent   = log.String("event", "error")
+	panicEvent   = log.String("event", "panic")
 )
 
 // AddTraceID configures span s to have traceID set to the given id.
@@ -16,3 +20,14 @@ func AddTraceID(s opentracing.Span, id string) {
 		s.SetTag("traceID", id)
 	}
 }
+
+// LogPanic logs information about a recovered panic to s.
+func LogPanic(s opentracing.Span, v interface{}, stack []byte) {
+	ext.Error.Set(s, true)
+
+	s.LogFields(
+		panicEvent,
+		log.String("message", fmt.Sprint(v)),
+		log.String("stack", string(stack)),
+	)
+}
@@ -23,3 +23,20 @@ var _ = Describe("AddTraceID", func() {
 		Expect(span.tags).ShouldNot(HaveKey("traceID"))
 	})
 })
+
+var _ = Describe("LogPanic", func() {
+	It("marks the span as an error and logs the panic value and stack", func() {
+		span := &mockSpan{}
+
+		opentr.LogPanic(span, "<value>", []byte("<stack>"))
+
+		Expect(span.tags).Should(HaveKeyWithValue("error", true))
+		Expect(span.log).To(Equal([]map[string]interface{}{
+			{
+				"event":   "panic",
+				"message": "<value>",
+				"stack":   "<stack>",
+			},
+		}))
+	})
+})
@@ -1,6 +1,8 @@
 package opentr
 
 import (
+	"time"
+
 	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
 	"github.com/opentracing/opentracing-go/log"
@@ -13,6 +15,8 @@ import (
 var (
 	notifierUnicastEvent   = log.String("event", "notify")
 	notifierMulticastEvent = log.String("event", "notify-many")
+	notifierEachEvent      = log.String("event", "notify-each")
+	notifierRetainedEvent  = log.String("event", "notify-many-retained")
 	listenerReceiveEvent   = log.String("event", "notification")
 )
 
@@ -51,6 +55,27 @@ func LogNotifierUnicast(
 	s.LogFields(fields...)
 }
 
+// LogNotifierEach logs information about a notification sent to a list of
+// sessions to s.
+func LogNotifierEach(
+	s opentracing.Span,
+	attrs attributes.Catalog,
+	targets []ident.SessionID,
+	p *rinq.Payload,
+) {
+	fields := []log.Field{
+		notifierEachEvent,
+		log.Int("targets", len(targets)),
+		log.Int("size", p.Len()),
+	}
+
+	if len(attrs) > 0 {
+		fields = append(fields, lazyString("attributes", attrs.String))
+	}
+
+	s.LogFields(fields...)
+}
+
 // LogNotifierMulticast logs informatin about a multicast notification to s.
 func LogNotifierMulticast(
 	s opentracing.Span,
@@ -71,6 +96,29 @@ func LogNotifierMulticast(
 	s.LogFields(fields...)
 }
 
+// LogNotifierMulticastRetained logs information about a retained multicast
+// notification to s.
+func LogNotifierMulticastRetained(
+	s opentracing.Span,
+	attrs attributes.Catalog,
+	con constraint.Constraint,
+	ttl time.Duration,
+	p *rinq.Payload,
+) {
+	fields := []log.Field{
+		notifierRetainedEvent,
+		log.String("constraint", con.String()),
+		log.String("ttl", ttl.String()),
+		log.Int("size", p.Len()),
+	}
+
+	if len(attrs) > 0 {
+		fields = append(fields, lazyString("attributes", attrs.String))
+	}
+
+	s.LogFields(fields...)
+}
+
 // LogNotifierError logs information about err to s.
 func LogNotifierError(s opentracing.Span, err error) {
 	ext.Error.Set(s, true)
@@ -1,6 +1,7 @@
 package opentr
 
 import (
+	"sort"
 	"strings"
 
 	opentracing "github.com/opentracing/opentracing-go"
@@ -13,16 +14,20 @@ import (
 
 const (
 	fetchOp   = "session fetch"
+	listOp    = "session list"
 	updateOp  = "session update"
 	clearOp   = "session clear"
 	destroyOp = "session destroy"
+	watchOp   = "session watch"
 )
 
 var (
 	fetchEvent   = log.String("event", "fetch")
+	listEvent    = log.String("event", "list")
 	updateEvent  = log.String("event", "update")
 	clearEvent   = log.String("event", "clear")
 	destroyEvent = log.String("event", "destroy")
+	watchEvent   = log.String("event", "watch")
 )
 
 func setupSessionCommand(s opentracing.Span, op string, sessID ident.SessionID) {
@@ -67,6 +72,44 @@ func LogSessionFetchSuccess(s opentracing.Span, rev ident.Revision, attrs attrib
 	s.LogFields(fields...)
 }
 
+// SetupSessionList configures s as a namespace or attribute list operation. ns
+// is empty when listing namespaces.
+func SetupSessionList(s opentracing.Span, ns string, sessID ident.SessionID) {
+	setupSessionCommand(s, listOp, sessID)
+
+	if ns != "" {
+		s.SetTag("namespace", ns)
+	}
+}
+
+// LogSessionListRequest logs information about a session list attempt to s.
+func LogSessionListRequest(s opentracing.Span, rev ident.Revision) {
+	s.LogFields(
+		listEvent,
+		log.Uint32("rev", uint32(rev)),
+	)
+}
+
+// LogSessionListSuccess logs information about a successful session list to s.
+func LogSessionListSuccess(s opentracing.Span, rev ident.Revision, names []string, attrs attributes.Collection) {
+	fields := []log.Field{
+		successEvent,
+		log.Uint32("rev", uint32(rev)),
+	}
+
+	if len(names) != 0 {
+		fields = append(fields, lazyString("namespaces", func() string {
+			return "{" + strings.Join(names, ", ") + "}"
+		}))
+	}
+
+	if !attrs.IsEmpty() {
+		fields = append(fields, lazyString("attributes", attrs.String))
+	}
+
+	s.LogFields(fields...)
+}
+
 // SetupSessionUpdate configures s as an attribute update operation.
 func SetupSessionUpdate(s opentracing.Span, ns string, sessID ident.SessionID) {
 	setupSessionCommand(s, updateOp, sessID)
@@ -87,6 +130,32 @@ func LogSessionUpdateRequest(s opentracing.Span, rev ident.Revision, attrs attri
 	s.LogFields(fields...)
 }
 
+// LogSessionUpdateIfRequest logs information about a conditional session
+// update attempt to s.
+func LogSessionUpdateIfRequest(s opentracing.Span, rev ident.Revision, cond []rinq.AttrCondition, attrs attributes.Collection) {
+	fields := []log.Field{
+		updateEvent,
+		log.Uint32("rev", uint32(rev)),
+	}
+
+	if len(cond) != 0 {
+		fields = append(fields, lazyString("conditions", func() string {
+			conditions := make([]string, 0, len(cond))
+			for _, c := range cond {
+				conditions = append(conditions, c.String())
+			}
+
+			return "{" + strings.Join(conditions, ", ") + "}"
+		}))
+	}
+
+	if !attrs.IsEmpty() {
+		fields = append(fields, lazyString("changes", attrs.String))
+	}
+
+	s.LogFields(fields...)
+}
+
 // LogSessionUpdateSuccess logs information about a successful session update to s.
 func LogSessionUpdateSuccess(s opentracing.Span, rev ident.Revision, diff *attributes.Diff) {
 	fields := []log.Field{
@@ -101,6 +170,63 @@ func LogSessionUpdateSuccess(s opentracing.Span, rev ident.Revision, diff *attri
 	s.LogFields(fields...)
 }
 
+// SetupSessionUpdateMany configures s as an attribute update operation that
+// modifies multiple namespaces.
+func SetupSessionUpdateMany(s opentracing.Span, attrs map[string]attributes.List, sessID ident.SessionID) {
+	setupSessionCommand(s, updateOp, sessID)
+	s.SetTag("namespace", strings.Join(sortedNamespaces(attrs), ", "))
+}
+
+// LogSessionUpdateManyRequest logs information about a multi-namespace session
+// update attempt to s.
+func LogSessionUpdateManyRequest(s opentracing.Span, rev ident.Revision, attrs map[string]attributes.List) {
+	fields := []log.Field{
+		updateEvent,
+		log.Uint32("rev", uint32(rev)),
+	}
+
+	if len(attrs) != 0 {
+		fields = append(fields, lazyString("changes", func() string {
+			names := sortedNamespaces(attrs)
+			changes := make([]string, 0, len(names))
+
+			for _, ns := range names {
+				changes = append(changes, ns+"::"+attrs[ns].String())
+			}
+
+			return strings.Join(changes, " ")
+		}))
+	}
+
+	s.LogFields(fields...)
+}
+
+// LogSessionUpdateManySuccess logs information about a successful
+// multi-namespace session update to s.
+func LogSessionUpdateManySuccess(s opentracing.Span, rev ident.Revision, diff *attributes.MultiDiff) {
+	fields := []log.Field{
+		successEvent,
+		log.Uint32("rev", uint32(rev)),
+	}
+
+	if !diff.IsEmpty() {
+		fields = append(fields, lazyString("diff", diff.String))
+	}
+
+	s.LogFields(fields...)
+}
+
+func sortedNamespaces(attrs map[string]attributes.List) []string {
+	names := make([]string, 0, len(attrs))
+	for ns := range attrs {
+		names = append(names, ns)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
 // SetupSessionClear configures s as an attribute update operation.
 func SetupSessionClear(s opentracing.Span, ns string, sessID ident.SessionID) {
 	setupSessionCommand(s, clearOp, sessID)
@@ -152,6 +278,35 @@ func LogSessionDestroySuccess(s opentracing.Span) {
 	)
 }
 
+// SetupSessionWatch configures s as a watch operation.
+func SetupSessionWatch(s opentracing.Span, ns string, sessID ident.SessionID) {
+	setupSessionCommand(s, watchOp, sessID)
+	s.SetTag("namespace", ns)
+}
+
+// LogSessionWatchRequest logs information about a session watch attempt to s.
+func LogSessionWatchRequest(s opentracing.Span, rev ident.Revision, keys []string) {
+	fields := []log.Field{
+		watchEvent,
+		log.Uint32("rev", uint32(rev)),
+	}
+
+	if len(keys) != 0 {
+		fields = append(fields, lazyString("keys", func() string {
+			return "{" + strings.Join(keys, ", ") + "}"
+		}))
+	}
+
+	s.LogFields(fields...)
+}
+
+// LogSessionWatchSuccess logs information about a successful watch attempt to s.
+func LogSessionWatchSuccess(s opentracing.Span) {
+	s.LogFields(
+		successEvent,
+	)
+}
+
 // LogSessionError logs information about an error during a session operation.
 func LogSessionError(s opentracing.Span, err error) {
 	switch e := err.(type) {
@@ -75,6 +75,83 @@ var _ = Describe("LogSessionFetchSuccess", func() {
 	})
 })
 
+var _ = Describe("SetupSessionList", func() {
+	It("sets the operation name", func() {
+		span := &mockSpan{}
+
+		SetupSessionList(span, "<ns>", ident.SessionID{})
+
+		Expect(span.operationName).To(Equal("session list"))
+	})
+
+	It("sets the appropriate tags", func() {
+		span := &mockSpan{}
+
+		sessID := ident.NewPeerID().Session(1)
+
+		SetupSessionList(span, "<ns>", sessID)
+
+		Expect(span.tags).To(Equal(map[string]interface{}{
+			"subsystem": "session",
+			"session":   sessID.String(),
+			"namespace": "<ns>",
+		}))
+	})
+
+	It("does not set the namespace tag when listing namespaces", func() {
+		span := &mockSpan{}
+
+		sessID := ident.NewPeerID().Session(1)
+
+		SetupSessionList(span, "", sessID)
+
+		Expect(span.tags).To(Equal(map[string]interface{}{
+			"subsystem": "session",
+			"session":   sessID.String(),
+		}))
+	})
+})
+
+var _ = Describe("LogSessionListRequest", func() {
+	It("logs the appropriate fields", func() {
+		span := &mockSpan{}
+
+		LogSessionListRequest(span, 23)
+
+		Expect(span.log).To(Equal(
+			[]map[string]interface{}{
+				{
+					"event": "list",
+					"rev":   uint32(23),
+				},
+			},
+		))
+	})
+})
+
+var _ = Describe("LogSessionListSuccess", func() {
+	It("logs the appropriate fields", func() {
+		span := &mockSpan{}
+
+		attrs := attributes.VList{
+			{Attr: rinq.Set("a", "1")},
+		}
+
+		LogSessionListSuccess(span, 23, []string{"ns1", "ns2"}, attrs)
+
+		Expect(span.log).To(Equal(
+			[]map[string]interface{}{
+				{
+					"event":      "success",
+					"rev":        uint32(23),
+					"namespaces": "{ns1, ns2}",
+					"attributes": "{a=1}",
+				},
+			},
+		))
+	})
+})
+
 var _ = Describe("SetupSessionUpdate", func() {
 	It("sets the operation name", func() {
 		span := &mockSpan{}
@@ -122,6 +199,33 @@ var _ = Describe("LogSessionUpdateRequest", func() {
 	})
 })
 
+var _ = Describe("LogSessionUpdateIfRequest", func() {
+	It("logs the appropriate fields", func() {
+		span := &mockSpan{}
+
+		cond := []rinq.AttrCondition{
+			rinq.ValueIs("a", "1"),
+			rinq.Unchanged("b"),
+		}
+		attrs := attributes.List{
+			rinq.Set("a", "2"),
+		}
+
+		LogSessionUpdateIfRequest(span, 23, cond, attrs)
+
+		Expect(span.log).To(Equal(
+			[]map[string]interface{}{
+				{
+					"event":      "update",
+					"rev":        uint32(23),
+					"conditions": "{a==1, b unchanged}",
+					"changes":    "{a=2}",
+				},
+			},
+		))
+	})
+})
+
 var _ = Describe("LogSessionUpdateSuccess", func() {
 	It("logs the appropriate fields", func() {
 		span := &mockSpan{}
@@ -146,6 +250,81 @@ var _ = Describe("LogSessionUpdateSuccess", func() {
 	})
 })
 
+var _ = Describe("SetupSessionUpdateMany", func() {
+	It("sets the operation name", func() {
+		span := &mockSpan{}
+
+		SetupSessionUpdateMany(span, nil, ident.SessionID{})
+
+		Expect(span.operationName).To(Equal("session update"))
+	})
+
+	It("sets the appropriate tags", func() {
+		span := &mockSpan{}
+
+		sessID := ident.NewPeerID().Session(1)
+		attrs := map[string]attributes.List{
+			"ns2": nil,
+			"ns1": nil,
+		}
+
+		SetupSessionUpdateMany(span, attrs, sessID)
+
+		Expect(span.tags).To(Equal(map[string]interface{}{
+			"subsystem": "session",
+			"session":   sessID.String(),
+			"namespace": "ns1, ns2",
+		}))
+	})
+})
+
+var _ = Describe("LogSessionUpdateManyRequest", func() {
+	It("logs the appropriate fields", func() {
+		span := &mockSpan{}
+
+		attrs := map[string]attributes.List{
+			"ns2": {rinq.Set("b", "2")},
+			"ns1": {rinq.Set("a", "1")},
+		}
+
+		LogSessionUpdateManyRequest(span, 23, attrs)
+
+		Expect(span.log).To(Equal(
+			[]map[string]interface{}{
+				{
+					"event":   "update",
+					"rev":     uint32(23),
+					"changes": "ns1::{a=1} ns2::{b=2}",
+				},
+			},
+		))
+	})
+})
+
+var _ = Describe("LogSessionUpdateManySuccess", func() {
+	It("logs the appropriate fields", func() {
+		span := &mockSpan{}
+
+		a := attributes.NewDiff("ns1", 23)
+		a.Append(attributes.VAttr{Attr: rinq.Set("a", "1")})
+
+		diff := attributes.NewMultiDiff(23)
+		diff.Add(a)
+
+		LogSessionUpdateManySuccess(span, 23, diff)
+
+		Expect(span.log).To(Equal(
+			[]map[string]interface{}{
+				{
+					"event": "success",
+					"rev":   uint32(23),
+					"diff":  diff.String(),
+				},
+			},
+		))
+	})
+})
+
 var _ = Describe("SetupSessionClear", func() {
 	It("sets the operation name", func() {
 		span := &mockSpan{}
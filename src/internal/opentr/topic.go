@@ -0,0 +1,45 @@
+package opentr
+
+import (
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/log"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+)
+
+var (
+	publisherPublishEvent  = log.String("event", "publish")
+	subscriberReceiveEvent = log.String("event", "publication")
+)
+
+// SetupPublication configures span as a publication-related span.
+func SetupPublication(
+	s opentracing.Span,
+	id ident.MessageID,
+	topic string,
+) {
+	s.SetOperationName(topic + " publication")
+
+	s.SetTag("subsystem", "notify")
+	s.SetTag("message_id", id.String())
+	s.SetTag("topic", topic)
+}
+
+// LogPublisherPublish logs information about a published message to s.
+func LogPublisherPublish(s opentracing.Span, confirmed bool, p *rinq.Payload) {
+	s.LogFields(
+		publisherPublishEvent,
+		log.Bool("confirmed", confirmed),
+		log.Int("size", p.Len()),
+	)
+}
+
+// LogSubscriberReceived logs information about a received publication to s.
+func LogSubscriberReceived(s opentracing.Span, peerID ident.PeerID, pub rinq.Publication) {
+	s.LogFields(
+		subscriberReceiveEvent,
+		log.String("recipient", peerID.String()),
+		log.String("source", pub.Source.String()),
+		log.Int("size", pub.Payload.Len()),
+	)
+}
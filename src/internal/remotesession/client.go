@@ -2,6 +2,7 @@ package remotesession
 
 import (
 	"context"
+	"sort"
 	"sync/atomic"
 
 	"github.com/jmalloc/twelf/src/twelf"
@@ -10,6 +11,7 @@ import (
 	"github.com/rinq/rinq-go/src/internal/attributes"
 	"github.com/rinq/rinq-go/src/internal/command"
 	"github.com/rinq/rinq-go/src/internal/opentr"
+	"github.com/rinq/rinq-go/src/internal/watch"
 	"github.com/rinq/rinq-go/src/rinq"
 	"github.com/rinq/rinq-go/src/rinq/ident"
 	"github.com/rinq/rinq-go/src/rinq/trace"
@@ -93,6 +95,64 @@ func (c *client) Fetch(
 	return rsp.Rev, rsp.Attrs, nil
 }
 
+func (c *client) List(
+	ctx context.Context,
+	ref ident.Ref,
+	ns string,
+	from ident.Revision,
+) (
+	ident.Revision,
+	[]string,
+	attributes.VList,
+	error,
+) {
+	msgID, traceID := c.nextMessageID(ctx)
+
+	span, ctx := opentr.ChildOf(ctx, c.tracer, ext.SpanKindRPCClient)
+	defer span.Finish()
+
+	opentr.SetupSessionList(span, ns, ref.ID)
+	opentr.AddTraceID(span, traceID)
+	opentr.LogSessionListRequest(span, ref.Rev)
+
+	out := rinq.NewPayload(listRequest{
+		Seq:       ref.ID.Seq,
+		Rev:       ref.Rev,
+		Namespace: ns,
+		From:      from,
+	})
+	defer out.Close()
+
+	in, err := c.invoker.CallUnicast(
+		ctx,
+		msgID,
+		traceID,
+		ref.ID.Peer,
+		sessionNamespace,
+		listCommand,
+		out,
+	)
+	defer in.Close()
+
+	if err != nil {
+		opentr.LogSessionError(span, err)
+		return 0, nil, nil, failureToError(ref, err)
+	}
+
+	var rsp listResponse
+	err = in.Decode(&rsp)
+
+	if err != nil {
+		opentr.LogSessionError(span, err)
+
+		return 0, nil, nil, err
+	}
+
+	opentr.LogSessionListSuccess(span, rsp.Rev, rsp.Namespaces, rsp.Attrs)
+
+	return rsp.Rev, rsp.Namespaces, rsp.Attrs, nil
+}
+
 func (c *client) Update(
 	ctx context.Context,
 	ref ident.Ref,
@@ -163,6 +223,152 @@ func (c *client) Update(
 	return rsp.Rev, diff.VList, nil
 }
 
+func (c *client) UpdateIf(
+	ctx context.Context,
+	ref ident.Ref,
+	ns string,
+	cond []rinq.AttrCondition,
+	attrs attributes.List,
+) (
+	ident.Revision,
+	attributes.VList,
+	error,
+) {
+	msgID, traceID := c.nextMessageID(ctx)
+
+	span, ctx := opentr.ChildOf(ctx, c.tracer, ext.SpanKindRPCClient)
+	defer span.Finish()
+
+	opentr.SetupSessionUpdate(span, ns, ref.ID)
+	opentr.AddTraceID(span, traceID)
+	opentr.LogSessionUpdateIfRequest(span, ref.Rev, cond, attrs)
+
+	out := rinq.NewPayload(updateIfRequest{
+		Seq:        ref.ID.Seq,
+		Rev:        ref.Rev,
+		Namespace:  ns,
+		Conditions: cond,
+		Attrs:      attrs,
+	})
+	defer out.Close()
+
+	in, err := c.invoker.CallUnicast(
+		ctx,
+		msgID,
+		traceID,
+		ref.ID.Peer,
+		sessionNamespace,
+		updateIfCommand,
+		out,
+	)
+	defer in.Close()
+
+	if err != nil {
+		opentr.LogSessionError(span, err)
+		return 0, nil, failureToError(ref, err)
+	}
+
+	var rsp updateResponse
+	err = in.Decode(&rsp)
+
+	if err != nil {
+		opentr.LogSessionError(span, err)
+
+		return 0, nil, err
+	}
+
+	diff := attributes.NewDiff(ns, rsp.Rev)
+
+	for index, attr := range attrs {
+		diff.Append(
+			attributes.VAttr{
+				Attr:      attr,
+				CreatedAt: rsp.CreatedRevs[index],
+				UpdatedAt: rsp.Rev,
+			},
+		)
+	}
+
+	logUpdate(ctx, c.logger, c.peerID, ref.ID.At(rsp.Rev), diff)
+	opentr.LogSessionUpdateSuccess(span, rsp.Rev, diff)
+
+	return rsp.Rev, diff.VList, nil
+}
+
+func (c *client) UpdateMany(
+	ctx context.Context,
+	ref ident.Ref,
+	attrs map[string]attributes.List,
+) (
+	ident.Revision,
+	*attributes.MultiDiff,
+	error,
+) {
+	msgID, traceID := c.nextMessageID(ctx)
+
+	span, ctx := opentr.ChildOf(ctx, c.tracer, ext.SpanKindRPCClient)
+	defer span.Finish()
+
+	opentr.SetupSessionUpdateMany(span, attrs, ref.ID)
+	opentr.AddTraceID(span, traceID)
+	opentr.LogSessionUpdateManyRequest(span, ref.Rev, attrs)
+
+	out := rinq.NewPayload(updateManyRequest{
+		Seq:   ref.ID.Seq,
+		Rev:   ref.Rev,
+		Attrs: attrs,
+	})
+	defer out.Close()
+
+	in, err := c.invoker.CallUnicast(
+		ctx,
+		msgID,
+		traceID,
+		ref.ID.Peer,
+		sessionNamespace,
+		updateManyCommand,
+		out,
+	)
+	defer in.Close()
+
+	if err != nil {
+		opentr.LogSessionError(span, err)
+		return 0, nil, failureToError(ref, err)
+	}
+
+	var rsp updateManyResponse
+	err = in.Decode(&rsp)
+
+	if err != nil {
+		opentr.LogSessionError(span, err)
+
+		return 0, nil, err
+	}
+
+	diff := attributes.NewMultiDiff(rsp.Rev)
+
+	for _, ns := range sortedNamespaces(attrs) {
+		d := attributes.NewDiff(ns, rsp.Rev)
+
+		for index, attr := range attrs[ns] {
+			d.Append(
+				attributes.VAttr{
+					Attr:      attr,
+					CreatedAt: rsp.CreatedRevs[ns][index],
+					UpdatedAt: rsp.Rev,
+				},
+			)
+		}
+
+		diff.Add(d)
+	}
+
+	logUpdateMany(ctx, c.logger, c.peerID, ref.ID.At(rsp.Rev), diff)
+	opentr.LogSessionUpdateManySuccess(span, rsp.Rev, diff)
+
+	return rsp.Rev, diff, nil
+}
+
 func (c *client) Clear(
 	ctx context.Context,
 	ref ident.Ref,
@@ -259,6 +465,200 @@ func (c *client) Destroy(
 	return nil
 }
 
+func (c *client) Watch(
+	ctx context.Context,
+	ref ident.Ref,
+	ns string,
+	keys []string,
+	watchID uint32,
+) error {
+	msgID, traceID := c.nextMessageID(ctx)
+
+	span, ctx := opentr.ChildOf(ctx, c.tracer, ext.SpanKindRPCClient)
+	defer span.Finish()
+
+	opentr.SetupSessionWatch(span, ns, ref.ID)
+	opentr.AddTraceID(span, traceID)
+	opentr.LogSessionWatchRequest(span, ref.Rev, keys)
+
+	out := rinq.NewPayload(watchRequest{
+		Seq:       ref.ID.Seq,
+		Rev:       ref.Rev,
+		Namespace: ns,
+		Keys:      keys,
+		Watch:     watchID,
+	})
+	defer out.Close()
+
+	in, err := c.invoker.CallUnicast(
+		ctx,
+		msgID,
+		traceID,
+		ref.ID.Peer,
+		sessionNamespace,
+		watchCommand,
+		out,
+	)
+	defer in.Close()
+
+	if err != nil {
+		opentr.LogSessionError(span, err)
+		return failureToError(ref, err)
+	}
+
+	opentr.LogSessionWatchSuccess(span)
+
+	return nil
+}
+
+func (c *client) Unwatch(
+	ctx context.Context,
+	peerID ident.PeerID,
+	watchID uint32,
+) error {
+	msgID, traceID := c.nextMessageID(ctx)
+
+	out := rinq.NewPayload(unwatchRequest{
+		Watch: watchID,
+	})
+	defer out.Close()
+
+	in, err := c.invoker.CallUnicast(
+		ctx,
+		msgID,
+		traceID,
+		peerID,
+		sessionNamespace,
+		unwatchCommand,
+		out,
+	)
+	in.Close()
+
+	return err
+}
+
+// CheckWatches returns the IDs of the watchers in ids that are no longer
+// being pushed changes by the peer with the given ID.
+func (c *client) CheckWatches(
+	ctx context.Context,
+	peerID ident.PeerID,
+	ids []uint32,
+) ([]uint32, error) {
+	msgID, traceID := c.nextMessageID(ctx)
+
+	out := rinq.NewPayload(checkWatchesRequest{
+		Watches: ids,
+	})
+	defer out.Close()
+
+	in, err := c.invoker.CallUnicast(
+		ctx,
+		msgID,
+		traceID,
+		peerID,
+		sessionNamespace,
+		checkWatchesCommand,
+		out,
+	)
+	defer in.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var rsp checkWatchesResponse
+	err = in.Decode(&rsp)
+
+	return rsp.Lost, err
+}
+
+func (c *client) Changed(
+	ctx context.Context,
+	peerID ident.PeerID,
+	watchID uint32,
+	change watch.Change,
+) error {
+	msgID, traceID := c.nextMessageID(ctx)
+
+	out := rinq.NewPayload(changedRequest{
+		Watch:       watchID,
+		Seq:         change.Ref.ID.Seq,
+		Rev:         change.Ref.Rev,
+		Namespace:   change.Namespace,
+		Attrs:       change.Attrs,
+		IsDestroyed: change.IsDestroyed,
+	})
+	defer out.Close()
+
+	in, err := c.invoker.CallUnicast(
+		ctx,
+		msgID,
+		traceID,
+		peerID,
+		sessionNamespace,
+		changedCommand,
+		out,
+	)
+	in.Close()
+
+	return err
+}
+
+// Invalidate publishes an invalidation describing change to all peers,
+// without waiting for a response.
+func (c *client) Invalidate(
+	ctx context.Context,
+	change watch.Change,
+) error {
+	req := invalidateRequest{
+		Seq:         change.Ref.ID.Seq,
+		Rev:         change.Ref.Rev,
+		IsDestroyed: change.IsDestroyed,
+	}
+
+	if change.Diff != nil && !change.Diff.IsEmpty() {
+		req.Keys = map[string][]string{}
+
+		for _, diff := range change.Diff.Diffs {
+			for _, attr := range diff.VList {
+				req.Keys[diff.Namespace] = append(req.Keys[diff.Namespace], attr.Key)
+			}
+		}
+	}
+
+	return c.invalidate(ctx, req)
+}
+
+// InvalidateAll publishes an invalidation to all peers indicating that any of
+// the attributes of a session may have been modified at or before the
+// session-ref ref, without waiting for a response.
+func (c *client) InvalidateAll(
+	ctx context.Context,
+	ref ident.Ref,
+) error {
+	return c.invalidate(ctx, invalidateRequest{
+		Seq:   ref.ID.Seq,
+		Rev:   ref.Rev,
+		IsAll: true,
+	})
+}
+
+func (c *client) invalidate(ctx context.Context, req invalidateRequest) error {
+	msgID, traceID := c.nextMessageID(ctx)
+
+	out := rinq.NewPayload(req)
+	defer out.Close()
+
+	return c.invoker.ExecuteMulticast(
+		ctx,
+		msgID,
+		traceID,
+		sessionNamespace,
+		invalidateCommand,
+		out,
+	)
+}
+
 func (c *client) nextMessageID(ctx context.Context) (msgID ident.MessageID, traceID string) {
 	seq := atomic.AddUint32(&c.seq, 1)
 	msgID = c.peerID.Session(0).At(0).Message(seq)
@@ -270,3 +670,15 @@ func (c *client) nextMessageID(ctx context.Context) (msgID ident.MessageID, trac
 
 	return
 }
+
+// sortedNamespaces returns the keys of attrs, in order.
+func sortedNamespaces(attrs map[string]attributes.List) []string {
+	names := make([]string, 0, len(attrs))
+	for ns := range attrs {
+		names = append(names, ns)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
@@ -25,6 +25,22 @@ func logUpdate(
 	)
 }
 
+func logUpdateMany(
+	ctx context.Context,
+	logger twelf.Logger,
+	peerID ident.PeerID,
+	ref ident.Ref,
+	diff *attributes.MultiDiff,
+) {
+	logger.Log(
+		"%s updated remote session %s %s [%s]",
+		peerID.ShortString(),
+		ref.ShortString(),
+		diff,
+		trace.Get(ctx),
+	)
+}
+
 func logClear(
 	ctx context.Context,
 	logger twelf.Logger,
@@ -0,0 +1,167 @@
+package remotesession
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jmalloc/twelf/src/twelf"
+	"github.com/rinq/rinq-go/src/internal/localsession"
+	"github.com/rinq/rinq-go/src/internal/watch"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+)
+
+// invalidator publishes a multicast invalidation for each change to a local
+// session that has recently been read by another peer, so that peers that
+// have cached its attributes can update their cache without fetching from
+// this peer.
+//
+// Invalidations are sent without waiting for a response. Peers that have not
+// cached the session ignore them. A session is no longer tracked once it has
+// not been read for trackingTimeout, after which peers that cached it fetch
+// its attributes again, rather than relying on invalidations.
+type invalidator struct {
+	ctx    context.Context
+	peerID ident.PeerID
+	client *client
+	logger twelf.Logger
+
+	mutex    sync.Mutex
+	sessions map[ident.SessionID]*tracking
+}
+
+// trackingTimeout is the period without any reads after which a session is no
+// longer tracked by the invalidator.
+const trackingTimeout = 1 * time.Minute
+
+// tracking is the state of a session that is tracked by an invalidator.
+type tracking struct {
+	watcher   *watch.Watcher
+	expiry    *time.Timer
+	lastRead  time.Time
+	isExpired bool
+}
+
+func newInvalidator(
+	ctx context.Context,
+	peerID ident.PeerID,
+	client *client,
+	logger twelf.Logger,
+) *invalidator {
+	return &invalidator{
+		ctx:      ctx,
+		peerID:   peerID,
+		client:   client,
+		logger:   logger,
+		sessions: map[ident.SessionID]*tracking{},
+	}
+}
+
+// Track arranges for invalidations to be published for changes to sess, if
+// they are not already, and postpones the expiry of the tracking.
+func (i *invalidator) Track(sess *localsession.Session) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	sessID := sess.ID()
+
+	if t, ok := i.sessions[sessID]; ok {
+		t.lastRead = time.Now()
+		return
+	}
+
+	w, err := sess.WatchAll()
+	if err != nil {
+		return
+	}
+
+	t := &tracking{
+		watcher:  w,
+		lastRead: time.Now(),
+	}
+	t.expiry = time.AfterFunc(trackingTimeout, func() { i.expire(sessID, t) })
+	i.sessions[sessID] = t
+
+	go i.run(sess, t)
+}
+
+// run publishes an invalidation for each change queued in the watcher, until
+// the session is destroyed, the tracking expires or ctx is canceled.
+//
+// If the watcher is stopped because it has fallen too far behind, a single
+// invalidation of all attributes is published in place of the changes that
+// were discarded.
+func (i *invalidator) run(sess *localsession.Session, t *tracking) {
+	sessID := sess.ID()
+
+	defer func() {
+		sess.Unwatch(t.watcher)
+
+		i.mutex.Lock()
+		t.expiry.Stop()
+		if i.sessions[sessID] == t {
+			delete(i.sessions, sessID)
+		}
+		i.mutex.Unlock()
+	}()
+
+	isDestroyed := false
+
+	t.watcher.Run(i.ctx, func(c watch.Change) bool {
+		if err := i.client.Invalidate(i.ctx, c); err != nil {
+			logInvalidateFailed(i.logger, i.peerID, c.Ref, err)
+		}
+
+		isDestroyed = c.IsDestroyed
+
+		return true
+	})
+
+	i.mutex.Lock()
+	isOverflowed := !isDestroyed && !t.isExpired && i.ctx.Err() == nil
+	i.mutex.Unlock()
+
+	if isOverflowed {
+		i.invalidateAll(sess)
+	}
+}
+
+// expire stops tracking the session with the given ID if it has not been read
+// for trackingTimeout, otherwise it checks again once the remainder of the
+// timeout has elapsed.
+func (i *invalidator) expire(sessID ident.SessionID, t *tracking) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	if d := trackingTimeout - time.Since(t.lastRead); d > 0 {
+		t.expiry.Reset(d)
+		return
+	}
+
+	t.isExpired = true
+	t.watcher.Stop()
+
+	// stop recording reads, so that a subsequent read tracks the session anew
+	if i.sessions[sessID] == t {
+		delete(i.sessions, sessID)
+	}
+}
+
+// invalidateAll publishes an invalidation of all attributes of sess at its
+// current revision.
+func (i *invalidator) invalidateAll(sess *localsession.Session) {
+	ref, _ := sess.Attrs()
+
+	var err error
+
+	select {
+	case <-sess.Done():
+		err = i.client.Invalidate(i.ctx, watch.Change{Ref: ref, IsDestroyed: true})
+	default:
+		err = i.client.InvalidateAll(i.ctx, ref)
+	}
+
+	if err != nil {
+		logInvalidateFailed(i.logger, i.peerID, ref, err)
+	}
+}
@@ -40,3 +40,28 @@ func logCacheRemove(
 		sessID.ShortString(),
 	)
 }
+
+func logWatchesLost(
+	logger twelf.Logger,
+	peerID ident.PeerID,
+	ownerID ident.PeerID,
+	count int,
+	err error,
+) {
+	if err == nil {
+		logger.Debug(
+			"%s stopped %d watcher(s) of sessions owned by %s, which is no longer pushing changes",
+			peerID.ShortString(),
+			count,
+			ownerID.ShortString(),
+		)
+	} else {
+		logger.Debug(
+			"%s stopped %d watcher(s) of sessions owned by %s, which could not be reached: %s",
+			peerID.ShortString(),
+			count,
+			ownerID.ShortString(),
+			err,
+		)
+	}
+}
@@ -19,6 +19,12 @@ func (r *revision) SessionID() ident.SessionID {
 	return r.ref.ID
 }
 
+// Ref returns the session-ref of the revision. It is not part of the
+// rinq.Revision interface, see revisions.Number().
+func (r *revision) Ref() ident.Ref {
+	return r.ref
+}
+
 func (r *revision) Refresh(ctx context.Context) (rinq.Revision, error) {
 	rev, err := r.session.Head(ctx)
 
@@ -71,8 +77,56 @@ func (r *revision) GetMany(ctx context.Context, ns string, keys ...string) (rinq
 	return table, nil
 }
 
+func (r *revision) Namespaces(ctx context.Context) ([]string, error) {
+	if r.ref.Rev == 0 {
+		return nil, nil
+	}
+
+	return r.session.Namespaces(ctx, r.ref.Rev)
+}
+
+func (r *revision) GetAll(ctx context.Context, ns string) (rinq.AttrTable, error) {
+	namespaces.MustValidate(ns)
+
+	if r.ref.Rev == 0 {
+		return attributes.Table{}, nil
+	}
+
+	table, err := r.session.FetchAll(ctx, r.ref.Rev, ns)
+	if err != nil {
+		return nil, err
+	}
+
+	return table, nil
+}
+
+func (r *revision) DiffSince(ctx context.Context, from rinq.Revision, ns string) (rinq.AttrTable, error) {
+	namespaces.MustValidate(ns)
+
+	fromRev, err := revisions.Number(r.ref.ID, from)
+	if err != nil {
+		return nil, err
+	}
+
+	if fromRev >= r.ref.Rev {
+		return attributes.Table{}, nil
+	}
+
+	table, err := r.session.FetchChanges(ctx, fromRev, r.ref.Rev, ns)
+	if err != nil {
+		return nil, err
+	}
+
+	return table, nil
+}
+
 func (r *revision) Update(ctx context.Context, ns string, attrs ...rinq.Attr) (rinq.Revision, error) {
 	namespaces.MustValidate(ns)
+	attributes.List(attrs).MustValidate()
+
+	if len(attrs) == 0 {
+		return r, nil
+	}
 
 	rev, err := r.session.TryUpdate(ctx, r.ref.Rev, ns, attrs)
 	if err != nil {
@@ -82,6 +136,46 @@ func (r *revision) Update(ctx context.Context, ns string, attrs ...rinq.Attr) (r
 	return rev, nil
 }
 
+func (r *revision) UpdateIf(ctx context.Context, ns string, cond []rinq.AttrCondition, attrs ...rinq.Attr) (rinq.Revision, error) {
+	namespaces.MustValidate(ns)
+	attributes.List(attrs).MustValidate()
+
+	if len(attrs) == 0 {
+		return r, nil
+	}
+
+	rev, err := r.session.TryUpdateIf(ctx, r.ref.Rev, ns, cond, attrs)
+	if err != nil {
+		return r, err
+	}
+
+	return rev, nil
+}
+
+func (r *revision) UpdateMany(ctx context.Context, attrs map[string][]rinq.Attr) (rinq.Revision, error) {
+	changes := make(map[string]attributes.List, len(attrs))
+
+	for ns, a := range attrs {
+		namespaces.MustValidate(ns)
+		attributes.List(a).MustValidate()
+
+		if len(a) != 0 {
+			changes[ns] = a
+		}
+	}
+
+	if len(changes) == 0 {
+		return r, nil
+	}
+
+	rev, err := r.session.TryUpdateMany(ctx, r.ref.Rev, changes)
+	if err != nil {
+		return r, err
+	}
+
+	return rev, nil
+}
+
 func (r *revision) Clear(ctx context.Context, ns string) (rinq.Revision, error) {
 	namespaces.MustValidate(ns)
 
@@ -93,6 +187,12 @@ func (r *revision) Clear(ctx context.Context, ns string) (rinq.Revision, error)
 	return rev, nil
 }
 
+func (r *revision) Watch(ctx context.Context, ns string, keys ...string) (<-chan rinq.AttrChange, error) {
+	namespaces.MustValidate(ns)
+
+	return r.session.Watch(ctx, r.ref.Rev, ns, keys)
+}
+
 func (r *revision) Destroy(ctx context.Context) error {
 	return r.session.TryDestroy(ctx, r.ref.Rev)
 }
@@ -4,12 +4,15 @@ package remotesession_test
 
 import (
 	"context"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/rinq/rinq-go/src/internal/attributes"
 	"github.com/rinq/rinq-go/src/internal/functest"
+	"github.com/rinq/rinq-go/src/internal/revisions"
 	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/options"
 )
 
 var _ = Describe("revision (functional)", func() {
@@ -102,6 +105,30 @@ var _ = Describe("revision (functional)", func() {
 			Expect(attr).To(Equal(rinq.Set("a", "1")))
 		})
 
+		It("returns an attribute cached before the owning peer modified other attributes", func() {
+			var err error
+			local, err = local.Update(ctx, ns, rinq.Set("a", "1"))
+			Expect(err).NotTo(HaveOccurred())
+
+			remote, err = remote.Refresh(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = remote.Get(ctx, ns, "a")
+			Expect(err).NotTo(HaveOccurred())
+
+			// modify a different attribute, the owning peer notifies the server
+			// that "a" is still valid at the new revision
+			local, err = local.Update(ctx, ns, rinq.Set("b", "2"))
+			Expect(err).NotTo(HaveOccurred())
+
+			// obtain the new revision via the command handler
+			functest.Must(session.Call(ctx, ns, "", nil))
+
+			attr, err := remote.Get(ctx, ns, "a")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(attr).To(Equal(rinq.Set("a", "1")))
+		})
+
 		It("returns an attribute updated on the remote peer from the cache", func() {
 			// setup a handler that updates an attribute remotely
 			functest.Must(server.Listen(ns, func(ctx context.Context, req rinq.Request, res rinq.Response) {
@@ -275,7 +302,163 @@ var _ = Describe("revision (functional)", func() {
 		})
 	})
 
+	Describe("Namespaces", func() {
+		It("returns the namespaces that contain attributes", func() {
+			other := functest.NewNamespace()
+
+			var err error
+			local, err = local.Update(ctx, ns, rinq.Set("a", "1"))
+			Expect(err).NotTo(HaveOccurred())
+
+			local, err = local.Update(ctx, other, rinq.Set("b", "2"))
+			Expect(err).NotTo(HaveOccurred())
+
+			remote, err = remote.Refresh(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			names, err := remote.Namespaces(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names).To(ConsistOf(ns, other))
+		})
+
+		It("returns a not found error if the session has been destroyed", func() {
+			session.Destroy()
+			<-session.Done()
+
+			_, err := remote.Namespaces(ctx)
+			Expect(err).To(HaveOccurred())
+			Expect(rinq.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	Describe("GetAll", func() {
+		It("returns all attributes in the namespace", func() {
+			var err error
+			local, err = local.Update(ctx, ns, rinq.Set("a", "1"), rinq.Set("b", "2"))
+			Expect(err).NotTo(HaveOccurred())
+
+			remote, err = remote.Refresh(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			attrs, err := remote.GetAll(ctx, ns)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(attrs.String()).To(SatisfyAny(
+				Equal("{a=1, b=2}"),
+				Equal("{b=2, a=1}"),
+			))
+		})
+
+		It("excludes attributes created after the revision", func() {
+			var err error
+			local, err = local.Update(ctx, ns, rinq.Set("a", "1"))
+			Expect(err).NotTo(HaveOccurred())
+
+			attrs, err := remote.GetAll(ctx, ns)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(attrs.IsEmpty()).To(BeTrue())
+		})
+
+		It("returns a stale fetch error if an attribute has been updated in a later revision", func() {
+			var err error
+			local, err = local.Update(ctx, ns, rinq.Set("a", "1"))
+			Expect(err).NotTo(HaveOccurred())
+
+			remote, err = remote.Refresh(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			local, err = local.Update(ctx, ns, rinq.Set("a", "2"))
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = remote.GetAll(ctx, ns)
+			Expect(err).To(HaveOccurred())
+			Expect(rinq.ShouldRetry(err)).To(BeTrue())
+		})
+	})
+
+	Describe("DiffSince", func() {
+		It("returns the attributes modified since the given revision", func() {
+			var err error
+			local, err = local.Update(ctx, ns, rinq.Set("a", "1"), rinq.Set("b", "2"))
+			Expect(err).NotTo(HaveOccurred())
+
+			from, err := remote.Refresh(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			local, err = local.Update(ctx, ns, rinq.Set("b", "3"), rinq.Set("c", "4"))
+			Expect(err).NotTo(HaveOccurred())
+
+			remote, err = remote.Refresh(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			attrs, err := remote.DiffSince(ctx, from, ns)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(attrs.String()).To(SatisfyAny(
+				Equal("{b=3, c=4}"),
+				Equal("{c=4, b=3}"),
+			))
+		})
+
+		It("returns a stale fetch error if a modified attribute has been updated since the revision", func() {
+			var err error
+			local, err = local.Update(ctx, ns, rinq.Set("a", "1"))
+			Expect(err).NotTo(HaveOccurred())
+
+			from, err := remote.Refresh(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			local, err = local.Update(ctx, ns, rinq.Set("b", "2"))
+			Expect(err).NotTo(HaveOccurred())
+
+			remote, err = remote.Refresh(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			local, err = local.Update(ctx, ns, rinq.Set("b", "3"))
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = remote.DiffSince(ctx, from, ns)
+			Expect(err).To(HaveOccurred())
+			Expect(rinq.ShouldRetry(err)).To(BeTrue())
+		})
+
+		It("returns an empty table if the given revision is not older", func() {
+			var err error
+			local, err = local.Update(ctx, ns, rinq.Set("a", "1"))
+			Expect(err).NotTo(HaveOccurred())
+
+			remote, err = remote.Refresh(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			attrs, err := remote.DiffSince(ctx, remote, ns)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(attrs.IsEmpty()).To(BeTrue())
+		})
+
+		It("panics if the given revision belongs to a different session", func() {
+			other := client.Session()
+			defer other.Destroy()
+
+			Expect(func() {
+				remote.DiffSince(ctx, other.CurrentRevision(), ns)
+			}).To(Panic())
+		})
+	})
+
 	Describe("Update", func() {
+		It("does not produce a new revision if there are no attributes", func() {
+			var err error
+			local, err = local.Update(ctx, ns, rinq.Set("a", "1"))
+			Expect(err).NotTo(HaveOccurred())
+
+			// neither revision is checked for staleness, as there is no update
+			rev, err := remote.Update(ctx, ns)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rev).To(BeIdenticalTo(remote))
+
+			rev, err = local.Update(ctx, ns)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rev).To(BeIdenticalTo(local))
+		})
+
 		It("returns a stale update error if session is at a later revision", func() {
 			var err error
 			local, err = local.Update(ctx, ns, rinq.Set("a", "1"))
@@ -295,6 +478,188 @@ var _ = Describe("revision (functional)", func() {
 			Expect(err).To(HaveOccurred())
 			Expect(rinq.IsNotFound(err)).To(BeTrue())
 		})
+
+		It("clears attributes once their TTL has elapsed", func() {
+			var err error
+			remote, err = remote.Update(ctx, ns, rinq.SetTTL("a", "1", 50*time.Millisecond))
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(func() string {
+				rev, err := remote.Refresh(ctx)
+				Expect(err).NotTo(HaveOccurred())
+
+				attr, err := rev.Get(ctx, ns, "a")
+				Expect(err).NotTo(HaveOccurred())
+
+				return attr.Value
+			}).Should(Equal(""))
+		})
+
+		It("panics if a frozen attribute has a TTL", func() {
+			Expect(func() {
+				attr := rinq.Freeze("a", "1")
+				attr.TTL = time.Second
+				remote.Update(ctx, ns, attr)
+			}).To(Panic())
+		})
+	})
+
+	Describe("UpdateIf", func() {
+		It("succeeds if the session has been modified by unrelated updates", func() {
+			var err error
+			local, err = local.Update(ctx, ns, rinq.Set("b", "1"))
+			Expect(err).NotTo(HaveOccurred())
+
+			remote, err = remote.UpdateIf(
+				ctx,
+				ns,
+				[]rinq.AttrCondition{rinq.Unchanged("a"), rinq.ValueIs("b", "1")},
+				rinq.Set("a", "2"),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			local, err = local.Refresh(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			attr, err := local.Get(ctx, ns, "a")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(attr).To(Equal(rinq.Set("a", "2")))
+		})
+
+		It("returns a stale update error if an attribute has been modified", func() {
+			var err error
+			local, err = local.Update(ctx, ns, rinq.Set("a", "1"))
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = remote.UpdateIf(
+				ctx,
+				ns,
+				[]rinq.AttrCondition{rinq.Unchanged("a")},
+				rinq.Set("a", "2"),
+			)
+			Expect(err).To(HaveOccurred())
+			Expect(rinq.ShouldRetry(err)).To(BeTrue())
+		})
+
+		It("returns a stale update error if an attribute has an unexpected value", func() {
+			var err error
+			local, err = local.Update(ctx, ns, rinq.Set("a", "1"))
+			Expect(err).NotTo(HaveOccurred())
+
+			remote, err = remote.Refresh(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = remote.UpdateIf(
+				ctx,
+				ns,
+				[]rinq.AttrCondition{rinq.ValueIs("a", "2")},
+				rinq.Set("a", "3"),
+			)
+			Expect(err).To(HaveOccurred())
+			Expect(rinq.ShouldRetry(err)).To(BeTrue())
+		})
+
+		It("returns a not found error if the session has been destroyed", func() {
+			session.Destroy()
+			<-session.Done()
+
+			_, err := remote.UpdateIf(ctx, ns, nil, rinq.Set("a", "1"))
+			Expect(err).To(HaveOccurred())
+			Expect(rinq.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	Describe("UpdateMany", func() {
+		It("updates attributes in each namespace in a single revision", func() {
+			other := functest.NewNamespace()
+
+			var err error
+			remote, err = remote.UpdateMany(ctx, map[string][]rinq.Attr{
+				ns:    {rinq.Set("a", "1")},
+				other: {rinq.Set("b", "2")},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			local, err = local.Refresh(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			a, err := local.Get(ctx, ns, "a")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(a).To(Equal(rinq.Set("a", "1")))
+
+			b, err := local.Get(ctx, other, "b")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(b).To(Equal(rinq.Set("b", "2")))
+		})
+
+		It("does not update any namespace if an attribute is frozen", func() {
+			other := functest.NewNamespace()
+
+			var err error
+			local, err = local.Update(ctx, other, rinq.Freeze("b", "1"))
+			Expect(err).NotTo(HaveOccurred())
+
+			remote, err = remote.Refresh(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = remote.UpdateMany(ctx, map[string][]rinq.Attr{
+				ns:    {rinq.Set("a", "1")},
+				other: {rinq.Set("b", "2")},
+			})
+			Expect(err).To(BeAssignableToTypeOf(rinq.FrozenAttributesError{}))
+
+			local, err = local.Refresh(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			a, err := local.Get(ctx, ns, "a")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(a.Value).To(BeEmpty())
+		})
+
+		It("does not produce a new revision if there are no attributes", func() {
+			rev, err := remote.UpdateMany(ctx, map[string][]rinq.Attr{
+				ns: {},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rev).To(BeIdenticalTo(remote))
+
+			local, err = local.Refresh(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			localRev, err := revisions.Number(session.ID(), local)
+			Expect(err).NotTo(HaveOccurred())
+
+			remoteRev, err := revisions.Number(session.ID(), remote)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(localRev).To(Equal(remoteRev))
+		})
+
+		It("does not check for staleness if there are no attributes", func() {
+			var err error
+			local, err = local.Update(ctx, ns, rinq.Set("a", "1"))
+			Expect(err).NotTo(HaveOccurred())
+
+			rev, err := remote.UpdateMany(ctx, map[string][]rinq.Attr{ns: {}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rev).To(BeIdenticalTo(remote))
+
+			rev, err = local.UpdateMany(ctx, map[string][]rinq.Attr{ns: {}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rev).To(BeIdenticalTo(local))
+		})
+
+		It("returns a stale update error if session is at a later revision", func() {
+			var err error
+			local, err = local.Update(ctx, ns, rinq.Set("a", "1"))
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = remote.UpdateMany(ctx, map[string][]rinq.Attr{
+				ns: {rinq.Set("a", "2")},
+			})
+			Expect(err).To(HaveOccurred())
+			Expect(rinq.ShouldRetry(err)).To(BeTrue())
+		})
 	})
 
 	Describe("Clear", func() {
@@ -380,4 +745,71 @@ var _ = Describe("revision (functional)", func() {
 			Expect(rinq.IsNotFound(err)).To(BeTrue())
 		})
 	})
+
+	Describe("Watch", func() {
+		It("delivers changes made on the owning peer", func() {
+			changes, err := remote.Watch(ctx, ns, "a")
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = local.Update(ctx, ns, rinq.Set("a", "1"), rinq.Set("b", "2"))
+			Expect(err).NotTo(HaveOccurred())
+
+			var c rinq.AttrChange
+			Eventually(changes).Should(Receive(&c))
+			Expect(c.Namespace).To(Equal(ns))
+			Expect(c.IsDestroyed).To(BeFalse())
+
+			a, ok := c.Attrs.Get("a")
+			Expect(ok).To(BeTrue())
+			Expect(a.Value).To(Equal("1"))
+
+			_, ok = c.Attrs.Get("b")
+			Expect(ok).To(BeFalse())
+		})
+
+		It("closes the channel when the session is destroyed", func() {
+			changes, err := remote.Watch(ctx, ns)
+			Expect(err).NotTo(HaveOccurred())
+
+			session.Destroy()
+
+			var c rinq.AttrChange
+			Eventually(changes).Should(Receive(&c))
+			Expect(c.IsDestroyed).To(BeTrue())
+			Eventually(changes).Should(BeClosed())
+		})
+
+		It("closes the channel when the owning peer stops", func() {
+			watcher := functest.NewPeer(options.PruneInterval(100 * time.Millisecond))
+			defer func() {
+				watcher.Stop()
+				<-watcher.Done()
+			}()
+
+			watchNS := functest.NewNamespace()
+			revs := make(chan rinq.Revision, 1)
+			functest.Must(watcher.Listen(watchNS, func(ctx context.Context, req rinq.Request, res rinq.Response) {
+				revs <- req.Source
+				res.Close()
+			}))
+			functest.Must(session.Call(ctx, watchNS, "", nil))
+
+			changes, err := (<-revs).Watch(ctx, ns)
+			Expect(err).NotTo(HaveOccurred())
+
+			client.Stop()
+			<-client.Done()
+
+			Eventually(changes, 10*time.Second).Should(BeClosed())
+		})
+
+		It("returns a not found error if the session has been destroyed", func() {
+			session.Destroy()
+			<-session.Done()
+
+			_, err := remote.Watch(ctx, ns)
+			Expect(err).To(HaveOccurred())
+			Expect(rinq.IsNotFound(err)).To(BeTrue())
+		})
+	})
 })
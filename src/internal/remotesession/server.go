@@ -3,6 +3,8 @@ package remotesession
 import (
 	"context"
 	"errors"
+	"sync"
+	"time"
 
 	"github.com/jmalloc/twelf/src/twelf"
 	opentracing "github.com/opentracing/opentracing-go"
@@ -10,31 +12,72 @@ import (
 	"github.com/rinq/rinq-go/src/internal/command"
 	"github.com/rinq/rinq-go/src/internal/localsession"
 	"github.com/rinq/rinq-go/src/internal/opentr"
+	"github.com/rinq/rinq-go/src/internal/watch"
 	"github.com/rinq/rinq-go/src/rinq"
 	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/options"
 	"github.com/rinq/rinq-go/src/rinq/trace"
 )
 
 type server struct {
 	peerID   ident.PeerID
 	sessions *localsession.Store
+	remote   *store
+	inv      *invalidator
 	logger   twelf.Logger
+	ctx      context.Context // canceled when the remote store stops
+
+	mutex   sync.Mutex
+	watches map[watchKey]func() // map of remote watcher to cancel function
+}
+
+const (
+	// pushAttempts is the number of times the server attempts to push a change
+	// to a remote watcher before it stops pushing changes to that watcher.
+	pushAttempts = 3
+
+	// pushRetryDelay is the delay between attempts to push a change.
+	pushRetryDelay = 500 * time.Millisecond
+)
+
+// watchKey uniquely identifies a watcher created by a remote peer.
+type watchKey struct {
+	Peer  ident.PeerID
+	Watch uint32
 }
 
 // Listen attaches a new remote session service to the given command server.
+//
+// remote must be the store returned by NewStore(). It is used to deliver
+// changes and invalidations pushed by other peers, and to push changes to
+// local sessions to watchers and caches on other peers.
 func Listen(
 	svr command.Server,
 	peerID ident.PeerID,
 	sessions *localsession.Store,
+	remote Store,
 	logger twelf.Logger,
 ) error {
 	s := &server{
 		peerID:   peerID,
 		sessions: sessions,
 		logger:   logger,
+		watches:  map[watchKey]func(){},
 	}
 
-	_, err := svr.Listen(sessionNamespace, s.handle)
+	s.remote = remote.(*store)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.ctx = ctx
+
+	go func() {
+		<-s.remote.Done()
+		cancel()
+	}()
+
+	s.inv = newInvalidator(ctx, peerID, s.remote.client, logger)
+
+	_, err := svr.Listen(sessionNamespace, s.handle, options.ListenOptions{})
 	return err
 }
 
@@ -48,12 +91,28 @@ func (s *server) handle(
 	switch req.Command {
 	case fetchCommand:
 		s.fetch(ctx, req, res)
+	case listCommand:
+		s.list(ctx, req, res)
 	case updateCommand:
 		s.update(ctx, req, res)
+	case updateIfCommand:
+		s.updateIf(ctx, req, res)
+	case updateManyCommand:
+		s.updateMany(ctx, req, res)
 	case clearCommand:
 		s.clear(ctx, req, res)
 	case destroyCommand:
 		s.destroy(ctx, req, res)
+	case watchCommand:
+		s.watch(ctx, req, res)
+	case unwatchCommand:
+		s.unwatch(ctx, req, res)
+	case checkWatchesCommand:
+		s.checkWatches(ctx, req, res)
+	case changedCommand:
+		s.changed(ctx, req, res)
+	case invalidateCommand:
+		s.invalidate(ctx, req, res)
 	default:
 		res.Error(errors.New("unknown command"))
 	}
@@ -87,6 +146,10 @@ func (s *server) fetch(
 		return
 	}
 
+	// Track before reading the attributes, so that an invalidation is
+	// published for any change made after the revision in the response.
+	s.inv.Track(sess)
+
 	ref, attrs := sess.AttrsIn(args.Namespace)
 	rsp := fetchResponse{Rev: ref.Rev}
 	count := len(args.Keys)
@@ -108,6 +171,62 @@ func (s *server) fetch(
 	opentr.LogSessionFetchSuccess(span, rsp.Rev, rsp.Attrs)
 }
 
+func (s *server) list(
+	ctx context.Context,
+	req rinq.Request,
+	res rinq.Response,
+) {
+	span := opentracing.SpanFromContext(ctx)
+
+	var args listRequest
+
+	if err := req.Payload.Decode(&args); err != nil {
+		res.Error(err)
+		opentr.LogSessionError(span, err)
+		return
+	}
+
+	sessID := s.peerID.Session(args.Seq)
+
+	opentr.SetupSessionList(span, args.Namespace, sessID)
+	opentr.AddTraceID(span, trace.Get(ctx))
+	opentr.LogSessionListRequest(span, args.Rev)
+
+	sess, ok := s.sessions.Get(sessID)
+	if !ok {
+		err := res.Fail(notFoundFailure, "")
+		opentr.LogSessionError(span, err)
+		return
+	}
+
+	// The listed attributes are cached by the requesting peer.
+	if args.Namespace != "" {
+		s.inv.Track(sess)
+	}
+
+	ref, catalog := sess.Attrs()
+	rsp := listResponse{Rev: ref.Rev}
+
+	if args.Namespace == "" {
+		rsp.Namespaces = catalog.NamespacesAt(args.Rev)
+	} else {
+		attrs := catalog[args.Namespace]
+		rsp.Attrs = make(attributes.VList, 0, len(attrs))
+		for _, attr := range attrs {
+			if attr.UpdatedAt > args.From {
+				rsp.Attrs = append(rsp.Attrs, attr)
+			}
+		}
+	}
+
+	payload := rinq.NewPayload(rsp)
+	defer payload.Close()
+
+	res.Done(payload)
+
+	opentr.LogSessionListSuccess(span, rsp.Rev, rsp.Namespaces, rsp.Attrs)
+}
+
 func (s *server) update(
 	ctx context.Context,
 	req rinq.Request,
@@ -136,6 +255,8 @@ func (s *server) update(
 		return
 	}
 
+	s.inv.Track(sess)
+
 	_, diff, err := sess.TryUpdate(args.Rev, args.Namespace, args.Attrs)
 	if err != nil {
 		res.Error(errorToFailure(err))
@@ -166,6 +287,129 @@ func (s *server) update(
 	opentr.LogSessionUpdateSuccess(span, rsp.Rev, diff)
 }
 
+func (s *server) updateIf(
+	ctx context.Context,
+	req rinq.Request,
+	res rinq.Response,
+) {
+	span := opentracing.SpanFromContext(ctx)
+
+	var args updateIfRequest
+
+	if err := req.Payload.Decode(&args); err != nil {
+		res.Error(err)
+		opentr.LogSessionError(span, err)
+		return
+	}
+
+	sessID := s.peerID.Session(args.Seq)
+
+	opentr.SetupSessionUpdate(span, args.Namespace, sessID)
+	opentr.AddTraceID(span, trace.Get(ctx))
+	opentr.LogSessionUpdateIfRequest(span, args.Rev, args.Conditions, args.Attrs)
+
+	sess, ok := s.sessions.Get(sessID)
+	if !ok {
+		err := res.Fail(notFoundFailure, "")
+		opentr.LogSessionError(span, err)
+		return
+	}
+
+	s.inv.Track(sess)
+
+	_, diff, err := sess.TryUpdateIf(args.Rev, args.Namespace, args.Conditions, args.Attrs)
+	if err != nil {
+		res.Error(errorToFailure(err))
+		opentr.LogSessionError(span, err)
+		return
+	}
+
+	logRemoteUpdate(ctx, s.logger, sessID.At(diff.Revision), req.ID.Ref.ID.Peer, diff)
+
+	rsp := updateResponse{
+		Rev:         diff.Revision,
+		CreatedRevs: make([]ident.Revision, 0, len(args.Attrs)),
+	}
+	_, attrs := sess.AttrsIn(args.Namespace)
+
+	for _, attr := range args.Attrs {
+		rsp.CreatedRevs = append(
+			rsp.CreatedRevs,
+			attrs[attr.Key].CreatedAt,
+		)
+	}
+
+	payload := rinq.NewPayload(rsp)
+	defer payload.Close()
+
+	res.Done(payload)
+
+	opentr.LogSessionUpdateSuccess(span, rsp.Rev, diff)
+}
+
+func (s *server) updateMany(
+	ctx context.Context,
+	req rinq.Request,
+	res rinq.Response,
+) {
+	span := opentracing.SpanFromContext(ctx)
+
+	var args updateManyRequest
+
+	if err := req.Payload.Decode(&args); err != nil {
+		res.Error(err)
+		opentr.LogSessionError(span, err)
+		return
+	}
+
+	sessID := s.peerID.Session(args.Seq)
+
+	opentr.SetupSessionUpdateMany(span, args.Attrs, sessID)
+	opentr.AddTraceID(span, trace.Get(ctx))
+	opentr.LogSessionUpdateManyRequest(span, args.Rev, args.Attrs)
+
+	sess, ok := s.sessions.Get(sessID)
+	if !ok {
+		err := res.Fail(notFoundFailure, "")
+		opentr.LogSessionError(span, err)
+		return
+	}
+
+	s.inv.Track(sess)
+
+	_, diff, err := sess.TryUpdateMany(args.Rev, args.Attrs)
+	if err != nil {
+		res.Error(errorToFailure(err))
+		opentr.LogSessionError(span, err)
+		return
+	}
+
+	logRemoteUpdateMany(ctx, s.logger, sessID.At(diff.Revision), req.ID.Ref.ID.Peer, diff)
+
+	rsp := updateManyResponse{
+		Rev:         diff.Revision,
+		CreatedRevs: make(map[string][]ident.Revision, len(args.Attrs)),
+	}
+	_, catalog := sess.Attrs()
+
+	for ns, attrs := range args.Attrs {
+		revs := make([]ident.Revision, 0, len(attrs))
+
+		for _, attr := range attrs {
+			revs = append(revs, catalog[ns][attr.Key].CreatedAt)
+		}
+
+		rsp.CreatedRevs[ns] = revs
+	}
+
+	payload := rinq.NewPayload(rsp)
+	defer payload.Close()
+
+	res.Done(payload)
+
+	opentr.LogSessionUpdateManySuccess(span, rsp.Rev, diff)
+}
+
 func (s *server) clear(
 	ctx context.Context,
 	req rinq.Request,
@@ -258,3 +502,243 @@ func (s *server) destroy(
 
 	opentr.LogSessionDestroySuccess(span)
 }
+
+// watch starts pushing changes to a local session to a watcher on another
+// peer.
+func (s *server) watch(
+	ctx context.Context,
+	req rinq.Request,
+	res rinq.Response,
+) {
+	span := opentracing.SpanFromContext(ctx)
+
+	var args watchRequest
+
+	if err := req.Payload.Decode(&args); err != nil {
+		res.Error(err)
+		opentr.LogSessionError(span, err)
+		return
+	}
+
+	sessID := s.peerID.Session(args.Seq)
+
+	opentr.SetupSessionWatch(span, args.Namespace, sessID)
+	opentr.AddTraceID(span, trace.Get(ctx))
+	opentr.LogSessionWatchRequest(span, args.Rev, args.Keys)
+
+	sess, ok := s.sessions.Get(sessID)
+	if !ok {
+		err := res.Fail(notFoundFailure, "")
+		opentr.LogSessionError(span, err)
+		return
+	}
+
+	w, err := sess.Watch(args.Rev, args.Namespace, args.Keys)
+	if err != nil {
+		res.Error(errorToFailure(err))
+		opentr.LogSessionError(span, err)
+		return
+	}
+
+	key := watchKey{req.ID.Ref.ID.Peer, args.Watch}
+	watchCtx, cancel := context.WithCancel(s.ctx)
+
+	s.mutex.Lock()
+	s.watches[key] = cancel
+	s.mutex.Unlock()
+
+	go s.push(watchCtx, key, sess, w)
+
+	logRemoteWatch(ctx, s.logger, sessID.At(args.Rev), key.Peer, args.Namespace, args.Keys)
+
+	res.Close()
+
+	opentr.LogSessionWatchSuccess(span)
+}
+
+// push sends the changes queued in w to the remote watcher identified by key,
+// until the watcher is removed, the remote store is stopped, or a change can
+// not be delivered.
+//
+// The watching peer periodically checks that its watchers are still known to
+// this peer, so it detects that changes are no longer being pushed.
+func (s *server) push(
+	ctx context.Context,
+	key watchKey,
+	sess *localsession.Session,
+	w *watch.Watcher,
+) {
+	defer func() {
+		sess.Unwatch(w)
+		s.stopWatch(key)
+	}()
+
+	w.Run(ctx, func(c watch.Change) bool {
+		err := s.pushChange(ctx, key, c)
+		if err != nil && ctx.Err() == nil {
+			logRemoteWatchFailed(s.logger, s.peerID, c.Ref, key.Peer, err)
+			return false
+		}
+
+		return err == nil
+	})
+}
+
+// pushChange sends c to the remote watcher identified by key, retrying up to
+// pushAttempts times.
+func (s *server) pushChange(
+	ctx context.Context,
+	key watchKey,
+	c watch.Change,
+) (err error) {
+	for i := 0; i < pushAttempts; i++ {
+		if i != 0 {
+			select {
+			case <-time.After(pushRetryDelay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		err = s.remote.client.Changed(ctx, key.Peer, key.Watch, c)
+
+		// a not-found failure means the watcher has been removed by the
+		// watching peer, there is no point retrying
+		if err == nil || rinq.IsFailureType(notFoundFailure, err) {
+			return
+		}
+	}
+
+	return
+}
+
+// unwatch stops pushing changes to a watcher on another peer.
+func (s *server) unwatch(
+	ctx context.Context,
+	req rinq.Request,
+	res rinq.Response,
+) {
+	var args unwatchRequest
+
+	if err := req.Payload.Decode(&args); err != nil {
+		res.Error(err)
+		return
+	}
+
+	s.stopWatch(watchKey{req.ID.Ref.ID.Peer, args.Watch})
+
+	res.Close()
+}
+
+// checkWatches responds with the watchers in the request that this peer is no
+// longer pushing changes to.
+func (s *server) checkWatches(
+	ctx context.Context,
+	req rinq.Request,
+	res rinq.Response,
+) {
+	var args checkWatchesRequest
+
+	if err := req.Payload.Decode(&args); err != nil {
+		res.Error(err)
+		return
+	}
+
+	var rsp checkWatchesResponse
+
+	s.mutex.Lock()
+	for _, id := range args.Watches {
+		if _, ok := s.watches[watchKey{req.ID.Ref.ID.Peer, id}]; !ok {
+			rsp.Lost = append(rsp.Lost, id)
+		}
+	}
+	s.mutex.Unlock()
+
+	payload := rinq.NewPayload(rsp)
+	defer payload.Close()
+
+	res.Done(payload)
+}
+
+// stopWatch cancels the context used to push changes to the remote watcher
+// identified by key.
+func (s *server) stopWatch(key watchKey) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if cancel, ok := s.watches[key]; ok {
+		cancel()
+		delete(s.watches, key)
+	}
+}
+
+// changed delivers a change pushed by another peer to a watcher created by
+// this peer.
+func (s *server) changed(
+	ctx context.Context,
+	req rinq.Request,
+	res rinq.Response,
+) {
+	var args changedRequest
+
+	if err := req.Payload.Decode(&args); err != nil {
+		res.Error(err)
+		return
+	}
+
+	sessID := req.ID.Ref.ID.Peer.Session(args.Seq)
+
+	rw, ok := s.remote.watches.get(args.Watch)
+	if !ok || rw.session.id != sessID {
+		res.Fail(notFoundFailure, "")
+		return
+	}
+
+	if args.IsDestroyed {
+		rw.session.markClosed()
+	}
+
+	rw.watcher.Push(watch.Change{
+		Ref:         sessID.At(args.Rev),
+		Namespace:   args.Namespace,
+		Attrs:       args.Attrs,
+		IsDestroyed: args.IsDestroyed,
+	})
+
+	res.Close()
+}
+
+// invalidate updates the cache of a remote session after it has been changed
+// on the owning peer.
+func (s *server) invalidate(
+	ctx context.Context,
+	req rinq.Request,
+	res rinq.Response,
+) {
+	var args invalidateRequest
+
+	if err := req.Payload.Decode(&args); err != nil {
+		res.Error(err)
+		return
+	}
+
+	sessID := req.ID.Ref.ID.Peer.Session(args.Seq)
+
+	// Invalidations are published to all peers, only sessions that are
+	// already in the store are updated.
+	sess, ok := s.remote.findSession(sessID)
+	if !ok {
+		res.Close()
+		return
+	}
+
+	if args.IsDestroyed {
+		sess.markClosed()
+	} else if args.IsAll {
+		sess.invalidateAll(args.Rev)
+	} else {
+		sess.invalidate(args.Rev, args.Keys)
+	}
+
+	res.Close()
+}
@@ -26,6 +26,22 @@ func logRemoteUpdate(
 	)
 }
 
+func logRemoteUpdateMany(
+	ctx context.Context,
+	logger twelf.Logger,
+	ref ident.Ref,
+	peerID ident.PeerID,
+	diff *attributes.MultiDiff,
+) {
+	logger.Log(
+		"%s session updated by %s %s [%s]",
+		ref.ShortString(),
+		peerID.ShortString(),
+		diff,
+		trace.Get(ctx),
+	)
+}
+
 func logRemoteClear(
 	ctx context.Context,
 	logger twelf.Logger,
@@ -42,6 +58,54 @@ func logRemoteClear(
 	)
 }
 
+func logRemoteWatch(
+	ctx context.Context,
+	logger twelf.Logger,
+	ref ident.Ref,
+	peerID ident.PeerID,
+	ns string,
+	keys []string,
+) {
+	logger.Debug(
+		"%s session is being watched by %s in '%s' namespace (keys: %v) [%s]",
+		ref.ShortString(),
+		peerID.ShortString(),
+		ns,
+		keys,
+		trace.Get(ctx),
+	)
+}
+
+func logRemoteWatchFailed(
+	logger twelf.Logger,
+	peerID ident.PeerID,
+	ref ident.Ref,
+	watcherID ident.PeerID,
+	err error,
+) {
+	logger.Debug(
+		"%s stopped pushing changes to %s session to %s: %s",
+		peerID.ShortString(),
+		ref.ShortString(),
+		watcherID.ShortString(),
+		err,
+	)
+}
+
+func logInvalidateFailed(
+	logger twelf.Logger,
+	peerID ident.PeerID,
+	ref ident.Ref,
+	err error,
+) {
+	logger.Debug(
+		"%s could not publish invalidation for %s session: %s",
+		peerID.ShortString(),
+		ref.ShortString(),
+		err,
+	)
+}
+
 func logRemoteDestroy(
 	ctx context.Context,
 	logger twelf.Logger,
@@ -6,14 +6,16 @@ import (
 
 	"github.com/rinq/rinq-go/src/internal/attributes"
 	"github.com/rinq/rinq-go/src/internal/revisions"
+	"github.com/rinq/rinq-go/src/internal/watch"
 	"github.com/rinq/rinq-go/src/internal/x/syncx"
 	"github.com/rinq/rinq-go/src/rinq"
 	"github.com/rinq/rinq-go/src/rinq/ident"
 )
 
 type session struct {
-	id     ident.SessionID
-	client *client
+	id      ident.SessionID
+	client  *client
+	watches *watchSet
 
 	mutex      sync.RWMutex
 	highestRev ident.Revision
@@ -21,10 +23,11 @@ type session struct {
 	isClosed   bool
 }
 
-func newSession(id ident.SessionID, client *client) *session {
+func newSession(id ident.SessionID, client *client, watches *watchSet) *session {
 	return &session{
-		id:     id,
-		client: client,
+		id:      id,
+		client:  client,
+		watches: watches,
 
 		cache: attrTableCache{},
 	}
@@ -147,6 +150,103 @@ func (s *session) Fetch(
 	return solvedAttrs, nil
 }
 
+// Namespaces returns the namespaces that contain attributes at rev.
+func (s *session) Namespaces(ctx context.Context, rev ident.Revision) ([]string, error) {
+	s.mutex.RLock()
+	isClosed := s.isClosed
+	s.mutex.RUnlock()
+
+	if isClosed {
+		return nil, rinq.NotFoundError{ID: s.id}
+	}
+
+	fetchedRev, names, _, err := s.client.List(ctx, s.id.At(rev), "", 0)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.updateState(fetchedRev, err)
+
+	return names, err
+}
+
+// FetchAll returns all attributes in the ns namespace at rev.
+func (s *session) FetchAll(ctx context.Context, rev ident.Revision, ns string) (attributes.Table, error) {
+	attrs, err := s.list(ctx, rev, 0, ns)
+	if err != nil {
+		return nil, err
+	}
+
+	table, ok := attrs.At(rev)
+	if !ok {
+		return nil, rinq.StaleFetchError{Ref: s.id.At(rev)}
+	}
+
+	return table, nil
+}
+
+// FetchChanges returns the attributes in the ns namespace that were modified
+// after from, as they were at rev.
+func (s *session) FetchChanges(ctx context.Context, from, rev ident.Revision, ns string) (attributes.Table, error) {
+	attrs, err := s.list(ctx, rev, from, ns)
+	if err != nil {
+		return nil, err
+	}
+
+	table, ok := attrs.ChangedBetween(from, rev)
+	if !ok {
+		return nil, rinq.StaleFetchError{Ref: s.id.At(rev)}
+	}
+
+	return table, nil
+}
+
+// list fetches the attributes in the ns namespace from the owning peer, as
+// they are at the latest revision, and adds them to the cache. If from is
+// non-zero, only the attributes modified after from are fetched.
+func (s *session) list(ctx context.Context, rev, from ident.Revision, ns string) (attributes.VTable, error) {
+	s.mutex.RLock()
+	isClosed := s.isClosed
+	s.mutex.RUnlock()
+
+	if isClosed {
+		return nil, rinq.NotFoundError{ID: s.id}
+	}
+
+	fetchedRev, _, fetchedAttrs, err := s.client.List(ctx, s.id.At(rev), ns, from)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.updateState(fetchedRev, err)
+
+	if err != nil {
+		return nil, err
+	}
+
+	cache, isExistingNamespace := s.cache[ns]
+	attrs := make(attributes.VTable, len(fetchedAttrs))
+
+	for _, attr := range fetchedAttrs {
+		attrs[attr.Key] = attr
+
+		// Update the cache entry if the fetched revision is newer.
+		if entry := cache[attr.Key]; fetchedRev > entry.FetchedAt {
+			if cache == nil {
+				cache = attrNamespaceCache{}
+			}
+
+			cache[attr.Key] = cachedAttr{attr, fetchedRev}
+		}
+	}
+
+	if !isExistingNamespace && cache != nil {
+		s.cache[ns] = cache
+	}
+
+	return attrs, nil
+}
+
 func (s *session) TryUpdate(
 	ctx context.Context,
 	rev ident.Revision,
@@ -180,7 +280,9 @@ func (s *session) TryUpdate(
 				return nil, rinq.FrozenAttributesError{Ref: ref}
 			}
 
-			if entry.FetchedAt == rev && attr == entry.Attr.Attr {
+			// attributes with a TTL are always sent, so that their expiry is
+			// extended.
+			if entry.FetchedAt == rev && attr == entry.Attr.Attr && attr.TTL == 0 {
 				continue
 			}
 		}
@@ -224,6 +326,152 @@ func (s *session) TryUpdate(
 	}, nil
 }
 
+func (s *session) TryUpdateIf(
+	ctx context.Context,
+	rev ident.Revision,
+	ns string,
+	cond []rinq.AttrCondition,
+	attrs attributes.List,
+) (rinq.Revision, error) {
+	unlock := syncx.RLock(&s.mutex)
+	defer unlock()
+
+	if s.isClosed {
+		return nil, rinq.NotFoundError{ID: s.id}
+	}
+
+	ref := s.id.At(rev)
+
+	// Unlike TryUpdate(), the update is sent to the owning peer even if this
+	// revision is known to be stale, as the conditions are checked against
+	// the current state of the session.
+	for _, attr := range attrs {
+		if entry, ok := s.cache[ns][attr.Key]; ok && entry.Attr.IsFrozen {
+			if attr != entry.Attr.Attr {
+				return nil, rinq.FrozenAttributesError{Ref: ref}
+			}
+		}
+	}
+
+	unlock()
+
+	updatedRev, returnedAttrs, err := s.client.UpdateIf(ctx, ref, ns, cond, attrs)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.updateState(updatedRev, err)
+
+	if err != nil {
+		return nil, err
+	}
+
+	cache, isExistingNamespace := s.cache[ns]
+
+	for _, attr := range returnedAttrs {
+		entry := cache[attr.Key]
+		if updatedRev > entry.FetchedAt {
+			if cache == nil {
+				cache = attrNamespaceCache{}
+			}
+
+			cache[attr.Key] = cachedAttr{attr, updatedRev}
+		}
+	}
+
+	if !isExistingNamespace && cache != nil {
+		s.cache[ns] = cache
+	}
+
+	return &revision{
+		s.id.At(s.highestRev),
+		s,
+	}, nil
+}
+
+func (s *session) TryUpdateMany(
+	ctx context.Context,
+	rev ident.Revision,
+	attrs map[string]attributes.List,
+) (rinq.Revision, error) {
+	unlock := syncx.RLock(&s.mutex)
+	defer unlock()
+
+	if s.isClosed {
+		return nil, rinq.NotFoundError{ID: s.id}
+	}
+
+	ref := s.id.At(rev)
+
+	if s.highestRev > rev {
+		return nil, rinq.StaleUpdateError{Ref: ref}
+	}
+
+	updateAttrs := make(map[string]attributes.List, len(attrs))
+
+	for ns, nsAttrs := range attrs {
+		cache := s.cache[ns]
+		list := make(attributes.List, 0, len(nsAttrs))
+
+		for _, attr := range nsAttrs {
+			if entry, ok := cache[attr.Key]; ok {
+				if entry.Attr.IsFrozen {
+					if attr == entry.Attr.Attr {
+						continue
+					}
+
+					return nil, rinq.FrozenAttributesError{Ref: ref}
+				}
+
+				if entry.FetchedAt == rev && attr == entry.Attr.Attr && attr.TTL == 0 {
+					continue
+				}
+			}
+
+			list = append(list, attr)
+		}
+
+		updateAttrs[ns] = list
+	}
+
+	unlock()
+
+	updatedRev, diff, err := s.client.UpdateMany(ctx, ref, updateAttrs)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.updateState(updatedRev, err)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, d := range diff.Diffs {
+		cache, isExistingNamespace := s.cache[d.Namespace]
+
+		for _, attr := range d.VList {
+			entry := cache[attr.Key]
+			if updatedRev > entry.FetchedAt {
+				if cache == nil {
+					cache = attrNamespaceCache{}
+				}
+
+				cache[attr.Key] = cachedAttr{attr, updatedRev}
+			}
+		}
+
+		if !isExistingNamespace && cache != nil {
+			s.cache[d.Namespace] = cache
+		}
+	}
+
+	return &revision{
+		s.id.At(s.highestRev),
+		s,
+	}, nil
+}
+
 func (s *session) TryClear(
 	ctx context.Context,
 	rev ident.Revision,
@@ -313,6 +561,114 @@ func (s *session) TryDestroy(
 	return nil
 }
 
+func (s *session) Watch(
+	ctx context.Context,
+	rev ident.Revision,
+	ns string,
+	keys []string,
+) (<-chan rinq.AttrChange, error) {
+	s.mutex.RLock()
+	isClosed := s.isClosed
+	s.mutex.RUnlock()
+
+	if isClosed {
+		return nil, rinq.NotFoundError{ID: s.id}
+	}
+
+	// The watcher is added to the set before the request is sent, as the
+	// owning peer may push changes before the response is received.
+	w := watch.NewWatcher(ns, keys)
+	id := s.watches.add(s, w)
+
+	err := s.client.Watch(ctx, s.id.At(rev), ns, keys, id)
+	if err != nil {
+		s.watches.remove(id)
+
+		s.mutex.Lock()
+		s.updateState(rev, err)
+		s.mutex.Unlock()
+
+		return nil, err
+	}
+
+	s.watches.activate(id)
+
+	return watch.Deliver(
+		ctx,
+		w,
+		s.changeRevision,
+		func() {
+			s.watches.remove(id)
+
+			// Tell the owning peer to stop pushing changes. This is not
+			// strictly necessary, as the owning peer stops when a push fails.
+			go s.client.Unwatch(context.Background(), s.id.Peer, id)
+		},
+	), nil
+}
+
+// changeRevision returns the revision at which the watched change c occurred.
+func (s *session) changeRevision(c watch.Change) rinq.Revision {
+	if c.IsDestroyed {
+		return revisions.Closed(s.id)
+	}
+
+	return s.At(c.Ref.Rev)
+}
+
+// markClosed records that the session has been destroyed.
+func (s *session) markClosed() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.isClosed = true
+}
+
+// invalidate records that the owning peer has produced revision rev by
+// modifying the attributes with the given keys. keys is a map of namespace to
+// the keys modified within that namespace.
+//
+// Cached attributes that were known to be valid at the previous revision, and
+// that were not modified, are known to be valid at rev, and so can be served
+// without fetching them from the owning peer. Invalidations that are missed
+// or received out of order leave the cache behind, but never incorrect.
+func (s *session) invalidate(rev ident.Revision, keys map[string][]string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.updateState(rev, nil)
+
+	for ns, cache := range s.cache {
+		modified := keys[ns]
+
+	entries:
+		for key, entry := range cache {
+			if entry.FetchedAt+1 != rev {
+				continue
+			}
+
+			for _, k := range modified {
+				if k == key {
+					continue entries
+				}
+			}
+
+			entry.FetchedAt = rev
+			cache[key] = entry
+		}
+	}
+}
+
+// invalidateAll records that the owning peer has produced revision rev, and
+// that any of the session's attributes may have been modified at or before
+// rev, such that no cached attributes are known to be valid at rev.
+func (s *session) invalidateAll(rev ident.Revision) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.updateState(rev, nil)
+}
+
 func (s *session) fetchLocal(
 	rev ident.Revision,
 	ns string,
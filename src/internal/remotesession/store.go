@@ -1,6 +1,7 @@
 package remotesession
 
 import (
+	"context"
 	"sync"
 	"time"
 
@@ -27,6 +28,7 @@ type store struct {
 	client   *client
 	interval time.Duration
 	logger   twelf.Logger
+	watches  *watchSet
 
 	mutex sync.Mutex
 	cache map[ident.SessionID]*cacheEntry
@@ -45,6 +47,7 @@ func NewStore(
 		client:   newClient(peerID, invoker, logger, tracer),
 		interval: pruneInterval,
 		logger:   logger,
+		watches:  newWatchSet(),
 		cache:    map[ident.SessionID]*cacheEntry{},
 	}
 
@@ -75,28 +78,62 @@ func (s *store) getSession(id ident.SessionID) *session {
 		return entry.Session
 	}
 
-	sess := newSession(id, s.client)
+	sess := newSession(id, s.client, s.watches)
 	s.cache[id] = &cacheEntry{sess, false}
 	logCacheAdd(s.logger, s.peerID, id)
 
 	return sess
 }
 
+// findSession returns the session with the given ID, if it is in the store.
+func (s *store) findSession(id ident.SessionID) (*session, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if entry, ok := s.cache[id]; ok {
+		return entry.Session, true
+	}
+
+	return nil, false
+}
+
 func (s *store) run() (service.State, error) {
 	for {
 		select {
 		case <-time.After(s.interval):
 			s.prune()
+			s.checkWatches()
 
 		case <-s.sm.Graceful:
+			s.watches.stopAll()
 			return nil, nil
 
 		case <-s.sm.Forceful:
+			s.watches.stopAll()
 			return nil, nil
 		}
 	}
 }
 
+// checkWatches asks each peer that owns a watched session whether it is still
+// pushing changes to this peer. Watchers are stopped if the owning peer does
+// not know about them, or can not be reached.
+func (s *store) checkWatches() {
+	for peerID, ids := range s.watches.byPeer() {
+		go func(peerID ident.PeerID, ids []uint32) {
+			lost, err := s.client.CheckWatches(context.Background(), peerID, ids)
+			if err != nil {
+				lost = ids
+			}
+
+			if len(lost) != 0 {
+				s.watches.stop(lost)
+				logWatchesLost(s.logger, s.peerID, peerID, len(lost), err)
+			}
+		}(peerID, ids)
+	}
+}
+
 func (s *store) prune() {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
@@ -11,10 +11,18 @@ const (
 )
 
 const (
-	fetchCommand   = "fetch"
-	updateCommand  = "update"
-	clearCommand   = "clear"
-	destroyCommand = "destroy"
+	fetchCommand        = "fetch"
+	listCommand         = "list"
+	updateCommand       = "update"
+	updateManyCommand   = "update-many"
+	updateIfCommand     = "update-if"
+	clearCommand        = "clear"
+	destroyCommand      = "destroy"
+	watchCommand        = "watch"
+	unwatchCommand      = "unwatch"
+	checkWatchesCommand = "check-watches"
+	changedCommand      = "changed"
+	invalidateCommand   = "invalidate"
 )
 
 type fetchRequest struct {
@@ -28,6 +36,22 @@ type fetchResponse struct {
 	Attrs attributes.VList `json:"a,omitempty"`
 }
 
+// listRequest is used to list the namespaces of a session, or if Namespace is
+// non-empty, the attributes within that namespace. If From is non-zero, only
+// the attributes modified after that revision are listed.
+type listRequest struct {
+	Seq       uint32         `json:"s"`
+	Rev       ident.Revision `json:"r"`
+	Namespace string         `json:"ns,omitempty"`
+	From      ident.Revision `json:"f,omitempty"`
+}
+
+type listResponse struct {
+	Rev        ident.Revision   `json:"r"`
+	Namespaces []string         `json:"n,omitempty"` // the namespaces at the requested revision
+	Attrs      attributes.VList `json:"a,omitempty"` // the attributes at the current revision
+}
+
 type updateRequest struct {
 	Seq       uint32          `json:"s"`
 	Rev       ident.Revision  `json:"r"`
@@ -40,26 +64,87 @@ type updateResponse struct {
 	CreatedRevs []ident.Revision `json:"cr,omitempty"`
 }
 
+type updateIfRequest struct {
+	Seq        uint32               `json:"s"`
+	Rev        ident.Revision       `json:"r"`
+	Namespace  string               `json:"ns"`
+	Conditions []rinq.AttrCondition `json:"c,omitempty"`
+	Attrs      attributes.List      `json:"a,omitempty"`
+}
+
+type updateManyRequest struct {
+	Seq   uint32                     `json:"s"`
+	Rev   ident.Revision             `json:"r"`
+	Attrs map[string]attributes.List `json:"a"`
+}
+
+type updateManyResponse struct {
+	Rev         ident.Revision              `json:"r"`
+	CreatedRevs map[string][]ident.Revision `json:"cr,omitempty"`
+}
+
 type destroyRequest struct {
 	Seq uint32         `json:"s"`
 	Rev ident.Revision `json:"r"`
 }
 
+type watchRequest struct {
+	Seq       uint32         `json:"s"`
+	Rev       ident.Revision `json:"r"`
+	Namespace string         `json:"ns"`
+	Keys      []string       `json:"k,omitempty"`
+	Watch     uint32         `json:"w"` // allocated by the watching peer
+}
+
+type unwatchRequest struct {
+	Watch uint32 `json:"w"`
+}
+
+// checkWatchesRequest is used to check that the owning peer is still pushing
+// changes to the given watchers.
+type checkWatchesRequest struct {
+	Watches []uint32 `json:"w"`
+}
+
+type checkWatchesResponse struct {
+	Lost []uint32 `json:"l,omitempty"` // the watchers that are unknown to the owning peer
+}
+
+type changedRequest struct {
+	Watch       uint32           `json:"w"`
+	Seq         uint32           `json:"s"`
+	Rev         ident.Revision   `json:"r"`
+	Namespace   string           `json:"ns,omitempty"`
+	Attrs       attributes.VList `json:"a,omitempty"`
+	IsDestroyed bool             `json:"d,omitempty"`
+}
+
+type invalidateRequest struct {
+	Seq         uint32              `json:"s"`
+	Rev         ident.Revision      `json:"r"`
+	Keys        map[string][]string `json:"k,omitempty"` // map of namespace to the keys modified in this revision
+	IsAll       bool                `json:"a,omitempty"` // any attribute may have been modified at or before this revision
+	IsDestroyed bool                `json:"d,omitempty"`
+}
+
 const (
 	notFoundFailure         = "not-found"
 	staleUpdateFailure      = "stale"
 	frozenAttributesFailure = "frozen"
+	quotaExceededFailure    = "quota"
 )
 
 // errorToFailure returns the appropriate failure type based on the type of err.
 func errorToFailure(err error) error {
-	switch err.(type) {
+	switch e := err.(type) {
 	case rinq.NotFoundError:
 		return rinq.Failure{Type: notFoundFailure}
 	case rinq.StaleUpdateError:
 		return rinq.Failure{Type: staleUpdateFailure}
 	case rinq.FrozenAttributesError:
 		return rinq.Failure{Type: frozenAttributesFailure}
+	case rinq.QuotaExceededError:
+		return rinq.Failure{Type: quotaExceededFailure, Message: e.Limit}
 	default:
 		return err
 	}
@@ -74,6 +159,8 @@ func failureToError(ref ident.Ref, err error) error {
 		return rinq.StaleUpdateError{Ref: ref}
 	case frozenAttributesFailure:
 		return rinq.FrozenAttributesError{Ref: ref}
+	case quotaExceededFailure:
+		return rinq.QuotaExceededError{Ref: ref, Limit: err.(rinq.Failure).Message}
 	}
 
 	return err
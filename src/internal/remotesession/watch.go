@@ -0,0 +1,111 @@
+package remotesession
+
+import (
+	"sync"
+
+	"github.com/rinq/rinq-go/src/internal/watch"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+)
+
+// watchSet is the set of watchers created by this peer for remote sessions.
+//
+// Each watcher is assigned an ID that is unique within this peer. The owning
+// peer includes the ID when it pushes a change to this peer, so that the
+// change can be routed to the correct watcher.
+type watchSet struct {
+	mutex    sync.Mutex
+	seq      uint32
+	watchers map[uint32]remoteWatcher
+}
+
+type remoteWatcher struct {
+	session  *session
+	watcher  *watch.Watcher
+	isActive bool // true once the owning peer has accepted the watch
+}
+
+func newWatchSet() *watchSet {
+	return &watchSet{
+		watchers: map[uint32]remoteWatcher{},
+	}
+}
+
+// add adds a watcher for sess to the set and returns its ID.
+func (ws *watchSet) add(sess *session, w *watch.Watcher) uint32 {
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+
+	ws.seq++
+	ws.watchers[ws.seq] = remoteWatcher{session: sess, watcher: w}
+
+	return ws.seq
+}
+
+// activate records that the owning peer has accepted the watcher with the
+// given ID.
+func (ws *watchSet) activate(id uint32) {
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+
+	if rw, ok := ws.watchers[id]; ok {
+		rw.isActive = true
+		ws.watchers[id] = rw
+	}
+}
+
+// remove removes the watcher with the given ID from the set.
+func (ws *watchSet) remove(id uint32) {
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+
+	delete(ws.watchers, id)
+}
+
+// get returns the watcher with the given ID.
+func (ws *watchSet) get(id uint32) (remoteWatcher, bool) {
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+
+	rw, ok := ws.watchers[id]
+	return rw, ok
+}
+
+// byPeer returns the IDs of the active watchers, grouped by the peer that owns
+// the watched session.
+func (ws *watchSet) byPeer() map[ident.PeerID][]uint32 {
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+
+	ids := map[ident.PeerID][]uint32{}
+	for id, rw := range ws.watchers {
+		if rw.isActive {
+			peerID := rw.session.id.Peer
+			ids[peerID] = append(ids[peerID], id)
+		}
+	}
+
+	return ids
+}
+
+// stop stops the watchers with the given IDs. Each watcher is removed from
+// the set once its remaining changes have been delivered.
+func (ws *watchSet) stop(ids []uint32) {
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+
+	for _, id := range ids {
+		if rw, ok := ws.watchers[id]; ok {
+			rw.watcher.Stop()
+		}
+	}
+}
+
+// stopAll stops all of the watchers in the set.
+func (ws *watchSet) stopAll() {
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+
+	for _, rw := range ws.watchers {
+		rw.watcher.Stop()
+	}
+}
@@ -0,0 +1,26 @@
+package revisions
+
+import (
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+)
+
+// Number returns the revision number of r, which must be a revision of the
+// session sessID. It panics if r belongs to a different session.
+//
+// rinq.Revision does not expose revision numbers, so r must be one of the
+// implementations provided by this module. If r is a revision of a session
+// that is known to have been destroyed, a NotFoundError is returned.
+func Number(sessID ident.SessionID, r rinq.Revision) (ident.Revision, error) {
+	if r.SessionID() != sessID {
+		panic("revision belongs to a different session")
+	}
+
+	if v, ok := r.(interface {
+		Ref() ident.Ref
+	}); ok {
+		return v.Ref().Rev, nil
+	}
+
+	return 0, rinq.NotFoundError{ID: sessID}
+}
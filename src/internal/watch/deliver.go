@@ -0,0 +1,54 @@
+package watch
+
+import (
+	"context"
+
+	"github.com/rinq/rinq-go/src/internal/attributes"
+	"github.com/rinq/rinq-go/src/rinq"
+)
+
+// Deliver starts a goroutine that sends the changes queued in w to the
+// returned channel, as per the semantics of rinq.Revision.Watch().
+//
+// rev is called to obtain the revision at each change's session-ref. done is
+// called when the watcher stops, before the channel is closed.
+func Deliver(
+	ctx context.Context,
+	w *Watcher,
+	rev func(Change) rinq.Revision,
+	done func(),
+) <-chan rinq.AttrChange {
+	changes := make(chan rinq.AttrChange)
+
+	go func() {
+		defer close(changes)
+		defer done()
+
+		w.Run(ctx, func(c Change) bool {
+			select {
+			case changes <- c.AttrChange(rev(c)):
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+
+	return changes
+}
+
+// AttrChange returns c as a rinq.AttrChange, where rev is the revision at
+// c.Ref.
+func (c Change) AttrChange(rev rinq.Revision) rinq.AttrChange {
+	attrs := attributes.Table{}
+	for _, attr := range c.Attrs {
+		attrs[attr.Key] = attr.Attr
+	}
+
+	return rinq.AttrChange{
+		Revision:    rev,
+		Namespace:   c.Namespace,
+		Attrs:       attrs,
+		IsDestroyed: c.IsDestroyed,
+	}
+}
@@ -0,0 +1,3 @@
+// Package watch contains a queue of changes to session attributes, used to
+// implement rinq.Revision.Watch() for both local and remote sessions.
+package watch
@@ -0,0 +1,224 @@
+package watch
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rinq/rinq-go/src/internal/attributes"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+)
+
+// Change is a change to the attributes of a session.
+type Change struct {
+	// Ref is the session-ref produced by the change.
+	Ref ident.Ref
+
+	// Namespace is the namespace of the changed attributes.
+	Namespace string
+
+	// Attrs contains the changed attributes.
+	Attrs attributes.VList
+
+	// Diff is the complete change produced by the revision, across all
+	// namespaces. It is only populated for watchers of all changes, in which
+	// case Namespace and Attrs are empty.
+	Diff *attributes.MultiDiff
+
+	// IsDestroyed is true if the change is the destruction of the session.
+	IsDestroyed bool
+}
+
+// Watcher is a subscription to changes to the attributes of a single session.
+//
+// Changes are queued by Push() without blocking, and passed to the consumer
+// in order by Run(). If the consumer falls more than MaxQueueSize changes
+// behind, the watcher is stopped.
+type Watcher struct {
+	namespace string              // empty matches all changes
+	keys      map[string]struct{} // nil matches all keys
+
+	mutex     sync.Mutex
+	queue     []Change
+	isStopped bool
+	ready     chan struct{}
+}
+
+// MaxQueueSize is the maximum number of changes that may be queued for
+// delivery to the consumer.
+const MaxQueueSize = 1024
+
+// NewWatcher returns a watcher for changes to the attributes in the ns
+// namespace with the given keys. If keys is empty, changes to all attributes
+// in ns are watched.
+//
+// If ns is empty, keys is ignored and every change is watched, including
+// those that produce a new revision without modifying any attributes. Such
+// watchers should be passed changes using PushDiff().
+func NewWatcher(ns string, keys []string) *Watcher {
+	w := &Watcher{
+		namespace: ns,
+		ready:     make(chan struct{}, 1),
+	}
+
+	if ns != "" && len(keys) != 0 {
+		w.keys = make(map[string]struct{}, len(keys))
+		for _, k := range keys {
+			w.keys[k] = struct{}{}
+		}
+	}
+
+	return w
+}
+
+// Namespace returns the namespace that is being watched, or an empty string
+// if all changes are watched.
+func (w *Watcher) Namespace() string {
+	return w.namespace
+}
+
+// Keys returns the keys that are being watched, or nil if all keys in the
+// namespace are watched.
+func (w *Watcher) Keys() []string {
+	if w.keys == nil {
+		return nil
+	}
+
+	keys := make([]string, 0, len(w.keys))
+	for k := range w.keys {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
+// Push queues c for delivery to the consumer.
+//
+// Attributes in c that are not being watched are discarded. If no attributes
+// remain, and c is not the destruction of the session, c is not queued.
+//
+// If the queue is full, c is discarded and the watcher is stopped.
+func (w *Watcher) Push(c Change) {
+	if w.namespace != "" && !c.IsDestroyed {
+		if c.Namespace != w.namespace {
+			return
+		}
+
+		var attrs attributes.VList
+		for _, attr := range c.Attrs {
+			if w.matches(attr.Key) {
+				attrs = append(attrs, attr)
+			}
+		}
+
+		if len(attrs) == 0 {
+			return
+		}
+
+		c.Attrs = attrs
+	}
+
+	w.mutex.Lock()
+	if !w.isStopped {
+		if len(w.queue) < MaxQueueSize {
+			w.queue = append(w.queue, c)
+		} else {
+			w.isStopped = true
+		}
+	}
+	w.mutex.Unlock()
+
+	w.notify()
+}
+
+// Stop stops the watcher. Changes that are already queued are still passed to
+// the consumer, after which Run() returns. Subsequent changes are discarded.
+func (w *Watcher) Stop() {
+	w.mutex.Lock()
+	w.isStopped = true
+	w.mutex.Unlock()
+
+	w.notify()
+}
+
+// PushDiff queues the change described by d, which produced the session-ref
+// ref, for delivery to the consumer.
+//
+// Watchers of all changes receive d as a single change. Otherwise, the diff
+// for the watched namespace, if any, is passed to Push().
+func (w *Watcher) PushDiff(ref ident.Ref, d *attributes.MultiDiff) {
+	if w.namespace == "" {
+		w.Push(Change{Ref: ref, Diff: d})
+		return
+	}
+
+	for _, diff := range d.Diffs {
+		if diff.Namespace == w.namespace {
+			w.Push(Change{
+				Ref:       ref,
+				Namespace: diff.Namespace,
+				Attrs:     diff.VList,
+			})
+		}
+	}
+}
+
+// Run calls fn for each queued change, in order, until ctx is canceled, fn
+// returns false, the destruction of the session has been passed to fn, or the
+// watcher is stopped and its queue is empty.
+func (w *Watcher) Run(ctx context.Context, fn func(Change) bool) {
+	for {
+		select {
+		case <-w.ready:
+		case <-ctx.Done():
+			return
+		}
+
+		for {
+			c, ok, done := w.pop()
+			if done {
+				return
+			} else if !ok {
+				break
+			}
+
+			if !fn(c) || c.IsDestroyed {
+				return
+			}
+		}
+	}
+}
+
+// notify wakes Run() without blocking.
+func (w *Watcher) notify() {
+	select {
+	case w.ready <- struct{}{}:
+	default:
+	}
+}
+
+// pop removes the next change from the queue. done is true if the queue is
+// empty and the watcher has been stopped.
+func (w *Watcher) pop() (c Change, ok, done bool) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if len(w.queue) == 0 {
+		done = w.isStopped
+		return
+	}
+
+	c = w.queue[0]
+	w.queue[0] = Change{} // release references held by the change
+	w.queue = w.queue[1:]
+
+	return c, true, false
+}
+
+func (w *Watcher) matches(k string) bool {
+	if w.keys == nil {
+		return true
+	}
+
+	_, ok := w.keys[k]
+	return ok
+}
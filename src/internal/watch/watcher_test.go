@@ -0,0 +1,193 @@
+package watch_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinq/rinq-go/src/internal/attributes"
+	. "github.com/rinq/rinq-go/src/internal/watch"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+)
+
+var _ = Describe("Watcher", func() {
+	var (
+		ctx     context.Context
+		cancel  func()
+		foo     = attributes.VAttr{Attr: rinq.Set("foo", "1"), CreatedAt: 1, UpdatedAt: 1}
+		bar     = attributes.VAttr{Attr: rinq.Set("bar", "2"), CreatedAt: 1, UpdatedAt: 1}
+		changes []Change
+		collect = func(c Change) bool {
+			changes = append(changes, c)
+			return true
+		}
+	)
+
+	BeforeEach(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+		changes = nil
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	Describe("Push", func() {
+		It("discards attributes that are not watched", func() {
+			subject := NewWatcher("ns", []string{"foo"})
+
+			subject.Push(Change{Namespace: "ns", Attrs: attributes.VList{foo, bar}})
+			subject.Push(Change{IsDestroyed: true})
+			subject.Run(ctx, collect)
+
+			Expect(changes).To(Equal([]Change{
+				{Namespace: "ns", Attrs: attributes.VList{foo}},
+				{IsDestroyed: true},
+			}))
+		})
+
+		It("discards changes that do not affect any watched attributes", func() {
+			subject := NewWatcher("ns", []string{"foo"})
+
+			subject.Push(Change{Namespace: "ns", Attrs: attributes.VList{bar}})
+			subject.Push(Change{Namespace: "other", Attrs: attributes.VList{foo}})
+			subject.Push(Change{IsDestroyed: true})
+			subject.Run(ctx, collect)
+
+			Expect(changes).To(Equal([]Change{
+				{IsDestroyed: true},
+			}))
+		})
+
+		It("keeps all attributes if no keys are specified", func() {
+			subject := NewWatcher("ns", nil)
+
+			subject.Push(Change{Namespace: "ns", Attrs: attributes.VList{foo, bar}})
+			subject.Push(Change{IsDestroyed: true})
+			subject.Run(ctx, collect)
+
+			Expect(changes).To(Equal([]Change{
+				{Namespace: "ns", Attrs: attributes.VList{foo, bar}},
+				{IsDestroyed: true},
+			}))
+		})
+
+		It("stops the watcher when the queue is full", func() {
+			subject := NewWatcher("ns", nil)
+
+			for i := 0; i <= MaxQueueSize; i++ {
+				subject.Push(Change{Namespace: "ns", Attrs: attributes.VList{foo}})
+			}
+			subject.Push(Change{IsDestroyed: true})
+			subject.Run(ctx, collect)
+
+			Expect(changes).To(HaveLen(MaxQueueSize))
+			Expect(changes[len(changes)-1].IsDestroyed).To(BeFalse())
+		})
+	})
+
+	Describe("Stop", func() {
+		It("delivers the queued changes before Run returns", func() {
+			subject := NewWatcher("ns", nil)
+
+			subject.Push(Change{Namespace: "ns", Attrs: attributes.VList{foo}})
+			subject.Stop()
+			subject.Push(Change{Namespace: "ns", Attrs: attributes.VList{bar}})
+			subject.Run(ctx, collect)
+
+			Expect(changes).To(Equal([]Change{
+				{Namespace: "ns", Attrs: attributes.VList{foo}},
+			}))
+		})
+
+		It("causes Run to return if there are no queued changes", func() {
+			subject := NewWatcher("ns", nil)
+
+			subject.Stop()
+			subject.Run(ctx, collect)
+
+			Expect(changes).To(BeEmpty())
+		})
+	})
+
+	Describe("PushDiff", func() {
+		var diff *attributes.MultiDiff
+
+		BeforeEach(func() {
+			a := attributes.NewDiff("ns", 1)
+			a.Append(foo)
+
+			b := attributes.NewDiff("other", 1)
+			b.Append(bar)
+
+			diff = attributes.NewMultiDiff(1)
+			diff.Add(a)
+			diff.Add(b)
+		})
+
+		It("pushes the diff for the watched namespace", func() {
+			subject := NewWatcher("other", nil)
+
+			subject.PushDiff(ident.Ref{Rev: 1}, diff)
+			subject.Push(Change{IsDestroyed: true})
+			subject.Run(ctx, collect)
+
+			Expect(changes).To(Equal([]Change{
+				{Ref: ident.Ref{Rev: 1}, Namespace: "other", Attrs: attributes.VList{bar}},
+				{IsDestroyed: true},
+			}))
+		})
+
+		It("pushes the entire diff if no namespace is specified", func() {
+			subject := NewWatcher("", nil)
+
+			subject.PushDiff(ident.Ref{Rev: 1}, diff)
+			subject.PushDiff(ident.Ref{Rev: 2}, attributes.NewMultiDiff(2))
+			subject.Push(Change{IsDestroyed: true})
+			subject.Run(ctx, collect)
+
+			Expect(changes).To(Equal([]Change{
+				{Ref: ident.Ref{Rev: 1}, Diff: diff},
+				{Ref: ident.Ref{Rev: 2}, Diff: attributes.NewMultiDiff(2)},
+				{IsDestroyed: true},
+			}))
+		})
+	})
+
+	Describe("Run", func() {
+		It("stops when the context is canceled", func() {
+			subject := NewWatcher("ns", nil)
+			cancel()
+
+			subject.Run(ctx, collect)
+
+			Expect(changes).To(BeEmpty())
+		})
+
+		It("stops when fn returns false", func() {
+			subject := NewWatcher("ns", nil)
+
+			subject.Push(Change{Namespace: "ns", Attrs: attributes.VList{foo}})
+			subject.Push(Change{Namespace: "ns", Attrs: attributes.VList{bar}})
+			subject.Run(ctx, func(c Change) bool {
+				changes = append(changes, c)
+				return false
+			})
+
+			Expect(changes).To(HaveLen(1))
+		})
+
+		It("stops after the destruction of the session", func() {
+			subject := NewWatcher("ns", nil)
+
+			subject.Push(Change{IsDestroyed: true})
+			subject.Push(Change{Namespace: "ns", Attrs: attributes.VList{foo}})
+			subject.Run(ctx, collect)
+
+			Expect(changes).To(Equal([]Change{
+				{IsDestroyed: true},
+			}))
+		})
+	})
+})
@@ -0,0 +1,53 @@
+package watchdog
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+)
+
+// goroutineID returns the ID of the current goroutine.
+//
+// The ID is parsed from the stack trace header, which takes the form
+// "goroutine 123 [running]:".
+func goroutineID() uint64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+
+	fields := bytes.Fields(buf[:n])
+	if len(fields) < 2 {
+		return 0
+	}
+
+	id, _ := strconv.ParseUint(string(fields[1]), 10, 64)
+	return id
+}
+
+// allStacks returns the stack traces of all goroutines.
+func allStacks() []byte {
+	buf := make([]byte, 64*1024)
+
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+// findStack returns the stack trace of the goroutine with the given ID from
+// stacks, as returned by allStacks(). It returns nil if the goroutine is not
+// present.
+func findStack(stacks []byte, id uint64) []byte {
+	prefix := []byte("goroutine " + strconv.FormatUint(id, 10) + " ")
+
+	for _, s := range bytes.Split(stacks, []byte("\n\n")) {
+		if bytes.HasPrefix(s, prefix) {
+			return s
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package watchdog
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jmalloc/twelf/src/twelf"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+)
+
+// Watchdog tracks running command and notification handlers, and logs a
+// warning when a handler runs for longer than a threshold.
+//
+// A nil watchdog is valid, it tracks nothing.
+type Watchdog struct {
+	peerID    ident.PeerID
+	threshold time.Duration
+	logger    twelf.Logger
+
+	mutex   sync.Mutex
+	seq     uint64
+	running map[uint64]*entry
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// entry is a running handler.
+type entry struct {
+	info      rinq.LongRunningHandler
+	goroutine uint64 // ID of the goroutine running the handler
+	warned    bool   // true once a warning has been logged
+}
+
+// New creates and starts a watchdog that warns about handlers that have been
+// running for longer than threshold.
+func New(
+	peerID ident.PeerID,
+	threshold time.Duration,
+	logger twelf.Logger,
+) *Watchdog {
+	w := &Watchdog{
+		peerID:    peerID,
+		threshold: threshold,
+		logger:    logger,
+		running:   map[uint64]*entry{},
+		stop:      make(chan struct{}),
+	}
+
+	go w.run()
+
+	return w
+}
+
+// Track records that the handler described by h has started running on the
+// current goroutine. h.StartedAt is set to the current time.
+//
+// The returned function must be called when the handler returns.
+func (w *Watchdog) Track(h rinq.LongRunningHandler) (done func()) {
+	if w == nil {
+		return func() {}
+	}
+
+	h.StartedAt = time.Now()
+	e := &entry{
+		info:      h,
+		goroutine: goroutineID(),
+	}
+
+	w.mutex.Lock()
+	w.seq++
+	id := w.seq
+	w.running[id] = e
+	w.mutex.Unlock()
+
+	return func() {
+		w.mutex.Lock()
+		delete(w.running, id)
+		w.mutex.Unlock()
+	}
+}
+
+// LongRunning returns the handlers that have been running for longer than the
+// threshold, oldest first.
+func (w *Watchdog) LongRunning() []rinq.LongRunningHandler {
+	if w == nil {
+		return nil
+	}
+
+	now := time.Now()
+	var handlers []rinq.LongRunningHandler
+
+	w.mutex.Lock()
+	for _, e := range w.running {
+		if now.Sub(e.info.StartedAt) >= w.threshold {
+			handlers = append(handlers, e.info)
+		}
+	}
+	w.mutex.Unlock()
+
+	sort.Slice(handlers, func(i, j int) bool {
+		return handlers[i].StartedAt.Before(handlers[j].StartedAt)
+	})
+
+	return handlers
+}
+
+// Stop stops the watchdog. Handlers are still tracked, but no more warnings
+// are logged.
+func (w *Watchdog) Stop() {
+	if w == nil {
+		return
+	}
+
+	w.stopOnce.Do(func() {
+		close(w.stop)
+	})
+}
+
+func (w *Watchdog) run() {
+	interval := w.threshold / 2
+	if interval <= 0 {
+		interval = w.threshold
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			w.check(now)
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+// check logs a warning for each handler that has exceeded the threshold since
+// the last check.
+func (w *Watchdog) check(now time.Time) {
+	var exceeded []entry
+
+	w.mutex.Lock()
+	for _, e := range w.running {
+		if !e.warned && now.Sub(e.info.StartedAt) >= w.threshold {
+			e.warned = true
+			exceeded = append(exceeded, *e)
+		}
+	}
+	w.mutex.Unlock()
+
+	if len(exceeded) == 0 {
+		return
+	}
+
+	// capture the stacks of all goroutines once, rather than once per handler
+	stacks := allStacks()
+
+	for _, e := range exceeded {
+		logLongRunning(
+			w.logger,
+			w.peerID,
+			e.info,
+			now.Sub(e.info.StartedAt),
+			findStack(stacks, e.goroutine),
+		)
+	}
+}
@@ -0,0 +1,52 @@
+package watchdog
+
+import (
+	"time"
+
+	"github.com/jmalloc/twelf/src/twelf"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+)
+
+func logLongRunning(
+	logger twelf.Logger,
+	peerID ident.PeerID,
+	h rinq.LongRunningHandler,
+	elapsed time.Duration,
+	stack []byte,
+) {
+	if h.Topic != "" {
+		logger.Log(
+			"%s watchdog: '%s' publication handler for publication %s has been running for %s [%s]\n%s",
+			peerID.ShortString(),
+			h.Topic,
+			h.ID.ShortString(),
+			elapsed,
+			h.TraceID,
+			stack,
+		)
+	} else if h.Session == (ident.SessionID{}) {
+		logger.Log(
+			"%s watchdog: '%s::%s' command handler for request %s has been running for %s [%s]\n%s",
+			peerID.ShortString(),
+			h.Namespace,
+			h.Command,
+			h.ID.ShortString(),
+			elapsed,
+			h.TraceID,
+			stack,
+		)
+	} else {
+		logger.Log(
+			"%s watchdog: '%s::%s' notification handler of session %s for notification %s has been running for %s [%s]\n%s",
+			peerID.ShortString(),
+			h.Namespace,
+			h.Type,
+			h.Session.ShortString(),
+			h.ID.ShortString(),
+			elapsed,
+			h.TraceID,
+			stack,
+		)
+	}
+}
@@ -0,0 +1,86 @@
+package watchdog_test
+
+import (
+	"time"
+
+	"github.com/jmalloc/twelf/src/twelf"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinq/rinq-go/src/internal/watchdog"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+)
+
+var _ = Describe("Watchdog", func() {
+	var subject *watchdog.Watchdog
+
+	BeforeEach(func() {
+		subject = watchdog.New(
+			ident.NewPeerID(),
+			20*time.Millisecond,
+			&twelf.StandardLogger{},
+		)
+	})
+
+	AfterEach(func() {
+		subject.Stop()
+	})
+
+	Describe("LongRunning", func() {
+		It("returns handlers that have exceeded the threshold", func() {
+			done := subject.Track(rinq.LongRunningHandler{Namespace: "ns", Command: "cmd"})
+			defer done()
+
+			Eventually(subject.LongRunning).Should(HaveLen(1))
+
+			h := subject.LongRunning()[0]
+			Expect(h.Namespace).To(Equal("ns"))
+			Expect(h.Command).To(Equal("cmd"))
+			Expect(h.StartedAt).NotTo(BeZero())
+		})
+
+		It("does not return handlers that have not exceeded the threshold", func() {
+			done := subject.Track(rinq.LongRunningHandler{})
+			defer done()
+
+			Expect(subject.LongRunning()).To(BeEmpty())
+		})
+
+		It("does not return handlers that have returned", func() {
+			done := subject.Track(rinq.LongRunningHandler{})
+			Eventually(subject.LongRunning).Should(HaveLen(1))
+
+			done()
+
+			Expect(subject.LongRunning()).To(BeEmpty())
+		})
+
+		It("returns the oldest handlers first", func() {
+			done := subject.Track(rinq.LongRunningHandler{Command: "first"})
+			defer done()
+
+			time.Sleep(5 * time.Millisecond)
+
+			done = subject.Track(rinq.LongRunningHandler{Command: "second"})
+			defer done()
+
+			Eventually(subject.LongRunning).Should(HaveLen(2))
+
+			handlers := subject.LongRunning()
+			Expect(handlers[0].Command).To(Equal("first"))
+			Expect(handlers[1].Command).To(Equal("second"))
+		})
+	})
+
+	Context("when nil", func() {
+		It("does not track handlers", func() {
+			var w *watchdog.Watchdog
+
+			done := w.Track(rinq.LongRunningHandler{})
+			done()
+
+			Expect(w.LongRunning()).To(BeNil())
+			w.Stop()
+		})
+	})
+})
@@ -0,0 +1,111 @@
+package aimd
+
+import (
+	"sync"
+	"time"
+)
+
+// Limit is a concurrency limit that adapts to observed latency.
+//
+// The limit is increased by one each time a full "window" of operations
+// completes within the target latency, where the window size is equal to the
+// current limit. It is halved whenever an operation exceeds the target latency,
+// or Decrease() is called. After each decrease, further decreases are ignored
+// until the target latency has elapsed, giving the system time to respond to
+// the new limit. Increases can be suspended by calling Hold().
+type Limit struct {
+	min, max uint
+	target   time.Duration
+
+	mutex       sync.Mutex
+	current     uint
+	successes   uint
+	decreasedAt time.Time
+	held        bool
+}
+
+// NewLimit returns a new limit that is adjusted between min and max, based
+// on the target latency. The initial limit is max.
+func NewLimit(min, max uint, target time.Duration) *Limit {
+	if min == 0 {
+		min = 1
+	}
+
+	if max < min {
+		max = min
+	}
+
+	return &Limit{
+		min:     min,
+		max:     max,
+		target:  target,
+		current: max,
+	}
+}
+
+// Current returns the current limit.
+func (l *Limit) Current() uint {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.current
+}
+
+// Observe adjusts the limit based on the latency of a completed operation.
+func (l *Limit) Observe(latency time.Duration, now time.Time) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if latency > l.target {
+		l.decrease(now)
+		return
+	}
+
+	if l.held {
+		return
+	}
+
+	l.successes++
+
+	if l.successes >= l.current {
+		l.successes = 0
+
+		if l.current < l.max {
+			l.current++
+		}
+	}
+}
+
+// Hold prevents the limit from being increased while held is true. Decreases
+// are still applied.
+func (l *Limit) Hold(held bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.held = held
+	if held {
+		l.successes = 0
+	}
+}
+
+// Decrease halves the limit, unless it has already been decreased recently.
+func (l *Limit) Decrease(now time.Time) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.decrease(now)
+}
+
+func (l *Limit) decrease(now time.Time) {
+	if now.Sub(l.decreasedAt) < l.target {
+		return
+	}
+
+	l.decreasedAt = now
+	l.successes = 0
+	l.current /= 2
+
+	if l.current < l.min {
+		l.current = l.min
+	}
+}
@@ -0,0 +1,108 @@
+package aimd_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/rinq/rinq-go/src/internal/x/aimd"
+)
+
+var _ = Describe("Limit", func() {
+	var (
+		now     time.Time
+		subject *Limit
+	)
+
+	BeforeEach(func() {
+		now = time.Now()
+		subject = NewLimit(2, 10, time.Second)
+	})
+
+	It("starts at the maximum", func() {
+		Expect(subject.Current()).To(Equal(uint(10)))
+	})
+
+	Describe("Observe", func() {
+		It("halves the limit when the latency exceeds the target", func() {
+			subject.Observe(2*time.Second, now)
+			Expect(subject.Current()).To(Equal(uint(5)))
+		})
+
+		It("ignores further decreases until the target latency has elapsed", func() {
+			subject.Observe(2*time.Second, now)
+			subject.Observe(2*time.Second, now.Add(500*time.Millisecond))
+			Expect(subject.Current()).To(Equal(uint(5)))
+
+			subject.Observe(2*time.Second, now.Add(time.Second))
+			Expect(subject.Current()).To(Equal(uint(2)))
+		})
+
+		It("does not decrease below the minimum", func() {
+			for i := 0; i < 5; i++ {
+				subject.Observe(2*time.Second, now.Add(time.Duration(i)*time.Second))
+			}
+
+			Expect(subject.Current()).To(Equal(uint(2)))
+		})
+
+		It("increases the limit by one after a full window within the target", func() {
+			subject.Observe(2*time.Second, now)
+
+			for i := 0; i < 4; i++ {
+				subject.Observe(time.Millisecond, now)
+			}
+			Expect(subject.Current()).To(Equal(uint(5)))
+
+			subject.Observe(time.Millisecond, now)
+			Expect(subject.Current()).To(Equal(uint(6)))
+		})
+
+		It("does not increase above the maximum", func() {
+			for i := 0; i < 20; i++ {
+				subject.Observe(time.Millisecond, now)
+			}
+
+			Expect(subject.Current()).To(Equal(uint(10)))
+		})
+	})
+
+	Describe("Decrease", func() {
+		It("halves the limit", func() {
+			subject.Decrease(now)
+			Expect(subject.Current()).To(Equal(uint(5)))
+		})
+	})
+
+	Describe("Hold", func() {
+		It("prevents the limit from increasing while held", func() {
+			subject.Decrease(now)
+			subject.Hold(true)
+
+			for i := 0; i < 20; i++ {
+				subject.Observe(time.Millisecond, now)
+			}
+
+			Expect(subject.Current()).To(Equal(uint(5)))
+		})
+
+		It("allows the limit to increase once released", func() {
+			subject.Decrease(now)
+			subject.Hold(true)
+			subject.Hold(false)
+
+			for i := 0; i < 5; i++ {
+				subject.Observe(time.Millisecond, now)
+			}
+
+			Expect(subject.Current()).To(Equal(uint(6)))
+		})
+
+		It("does not prevent the limit from decreasing", func() {
+			subject.Hold(true)
+			subject.Observe(2*time.Second, now)
+
+			Expect(subject.Current()).To(Equal(uint(5)))
+		})
+	})
+})
@@ -0,0 +1,3 @@
+// Package aimd contains a concurrency limit that is adjusted using an
+// additive-increase/multiplicative-decrease algorithm.
+package aimd
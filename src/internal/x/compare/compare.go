@@ -0,0 +1,170 @@
+package compare
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Numbers compares a and b as decimal numbers, such as "-1.5". It returns a
+// negative number if a < b, zero if a == b and a positive number if a > b.
+//
+// ok is false if either value is not a finite number.
+func Numbers(a, b string) (c int, ok bool) {
+	x, ok := parseNumber(a)
+	if !ok {
+		return 0, false
+	}
+
+	y, ok := parseNumber(b)
+	if !ok {
+		return 0, false
+	}
+
+	switch {
+	case x < y:
+		return -1, true
+	case x > y:
+		return +1, true
+	default:
+		return 0, true
+	}
+}
+
+// Versions compares a and b as semantic versions, such as "1.2.3-beta.1". It
+// returns a negative number if a < b, zero if a == b and a positive number if
+// a > b.
+//
+// The minor and patch components are optional, so "1.10" is greater than
+// "1.2", and "2" is equal to "2.0.0".
+//
+// ok is false if either value is not a semantic version.
+func Versions(a, b string) (c int, ok bool) {
+	x, ok := parseVersion(a)
+	if !ok {
+		return 0, false
+	}
+
+	y, ok := parseVersion(b)
+	if !ok {
+		return 0, false
+	}
+
+	return x.compare(y), true
+}
+
+// IsNumber returns true if v is a finite number, and hence may be compared
+// with other numbers using Numbers().
+func IsNumber(v string) bool {
+	_, ok := parseNumber(v)
+	return ok
+}
+
+// IsVersion returns true if v is a semantic version, and hence may be
+// compared with other versions using Versions().
+func IsVersion(v string) bool {
+	_, ok := parseVersion(v)
+	return ok
+}
+
+// parseNumber parses a finite floating-point number.
+func parseNumber(v string) (float64, bool) {
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0, false
+	}
+
+	return n, true
+}
+
+// version is a parsed semantic version.
+type version struct {
+	parts      [3]uint64
+	prerelease []string
+}
+
+// parseVersion parses a semantic version. The "v" prefix, and the minor and
+// patch components are optional. Build metadata is ignored.
+func parseVersion(s string) (v version, ok bool) {
+	s = strings.TrimPrefix(s, "v")
+
+	if i := strings.IndexByte(s, '+'); i != -1 {
+		s = s[:i]
+	}
+
+	if i := strings.IndexByte(s, '-'); i != -1 {
+		v.prerelease = strings.Split(s[i+1:], ".")
+		s = s[:i]
+
+		for _, id := range v.prerelease {
+			if id == "" {
+				return v, false
+			}
+		}
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) > len(v.parts) {
+		return v, false
+	}
+
+	for i, p := range parts {
+		n, err := strconv.ParseUint(p, 10, 64)
+		if err != nil {
+			return v, false
+		}
+
+		v.parts[i] = n
+	}
+
+	return v, true
+}
+
+// compare returns a negative number if v < o, zero if v == o and a positive
+// number if v > o, according to semantic versioning precedence rules.
+func (v version) compare(o version) int {
+	for i := range v.parts {
+		if v.parts[i] < o.parts[i] {
+			return -1
+		} else if v.parts[i] > o.parts[i] {
+			return +1
+		}
+	}
+
+	// a version without a pre-release has a higher precedence than one with a
+	// pre-release.
+	if len(v.prerelease) == 0 || len(o.prerelease) == 0 {
+		return len(o.prerelease) - len(v.prerelease)
+	}
+
+	for i := 0; i < len(v.prerelease) && i < len(o.prerelease); i++ {
+		if c := compareIdentifier(v.prerelease[i], o.prerelease[i]); c != 0 {
+			return c
+		}
+	}
+
+	return len(v.prerelease) - len(o.prerelease)
+}
+
+// compareIdentifier compares two pre-release identifiers. Numeric identifiers
+// are compared numerically and have a lower precedence than non-numeric ones.
+func compareIdentifier(a, b string) int {
+	x, errA := strconv.ParseUint(a, 10, 64)
+	y, errB := strconv.ParseUint(b, 10, 64)
+
+	switch {
+	case errA == nil && errB == nil:
+		if x < y {
+			return -1
+		} else if x > y {
+			return +1
+		}
+		return 0
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return +1
+	default:
+		return strings.Compare(a, b)
+	}
+}
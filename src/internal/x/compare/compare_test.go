@@ -0,0 +1,112 @@
+package compare_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/rinq/rinq-go/src/internal/x/compare"
+)
+
+// expectOrder asserts that c is consistent with the expected order.
+func expectOrder(c, expected int) {
+	switch {
+	case expected < 0:
+		Expect(c).To(BeNumerically("<", 0))
+	case expected > 0:
+		Expect(c).To(BeNumerically(">", 0))
+	default:
+		Expect(c).To(Equal(0))
+	}
+}
+
+var _ = Describe("Numbers", func() {
+	DescribeTable(
+		"returns the expected order",
+		func(a, b string, expected int) {
+			c, ok := compare.Numbers(a, b)
+			Expect(ok).To(BeTrue())
+			expectOrder(c, expected)
+		},
+		Entry("integers", "2", "10", -1),
+		Entry("decimals", "1.5", "1.25", +1),
+		Entry("decimals with leading zeros", "0.5", "0.10", +1),
+		Entry("negative decimals", "-2.5", "-2.25", -1),
+		Entry("negative and positive", "-1", "0.5", -1),
+		Entry("exponents", "2e3", "1.5e3", +1),
+		Entry("equal numbers", "3", "3.0", 0),
+	)
+
+	DescribeTable(
+		"returns false if the values can not be compared",
+		func(a, b string) {
+			_, ok := compare.Numbers(a, b)
+			Expect(ok).To(BeFalse())
+		},
+		Entry("empty", "", "1"),
+		Entry("non-numeric", "abc", "1"),
+		Entry("version", "1.2.3", "1"),
+		Entry("NaN", "NaN", "1"),
+		Entry("infinity", "-Inf", "1"),
+	)
+})
+
+var _ = Describe("Versions", func() {
+	DescribeTable(
+		"returns the expected order",
+		func(a, b string, expected int) {
+			c, ok := compare.Versions(a, b)
+			Expect(ok).To(BeTrue())
+			expectOrder(c, expected)
+		},
+		Entry("versions", "1.2.3", "1.10.0", -1),
+		Entry("versions with two components", "1.10", "1.2", +1),
+		Entry("versions with one component", "3.0.1", "3", +1),
+		Entry("equal versions with omitted components", "2", "2.0.0", 0),
+		Entry("versions with prefix", "v2.0.0", "1.9.9", +1),
+		Entry("pre-release", "1.0.0-beta", "1.0.0", -1),
+		Entry("numeric pre-release identifiers", "1.0.0-beta.2", "1.0.0-beta.11", -1),
+		Entry("alphanumeric pre-release identifiers", "1.0.0-alpha", "1.0.0-beta", -1),
+		Entry("additional pre-release identifiers", "1.0.0-alpha.1", "1.0.0-alpha", +1),
+		Entry("build metadata", "1.0.0+abc", "1.0.0+def", 0),
+	)
+
+	DescribeTable(
+		"returns false if the values can not be compared",
+		func(a, b string) {
+			_, ok := compare.Versions(a, b)
+			Expect(ok).To(BeFalse())
+		},
+		Entry("empty", "", "1"),
+		Entry("non-numeric", "abc", "1"),
+		Entry("too many components", "1.2.3.4", "1"),
+		Entry("empty pre-release identifier", "1.0.0-", "1"),
+		Entry("negative", "-1", "1"),
+		Entry("exponent", "1e3", "1"),
+	)
+})
+
+var _ = Describe("IsNumber", func() {
+	It("returns true for finite numbers", func() {
+		Expect(compare.IsNumber("-1.5")).To(BeTrue())
+		Expect(compare.IsNumber("0.10")).To(BeTrue())
+	})
+
+	It("returns false for other values", func() {
+		Expect(compare.IsNumber("1.2.3")).To(BeFalse())
+		Expect(compare.IsNumber("abc")).To(BeFalse())
+		Expect(compare.IsNumber("NaN")).To(BeFalse())
+		Expect(compare.IsNumber("+Inf")).To(BeFalse())
+	})
+})
+
+var _ = Describe("IsVersion", func() {
+	It("returns true for semantic versions", func() {
+		Expect(compare.IsVersion("1.2.3-rc.1")).To(BeTrue())
+		Expect(compare.IsVersion("v2")).To(BeTrue())
+	})
+
+	It("returns false for other values", func() {
+		Expect(compare.IsVersion("-1.5")).To(BeFalse())
+		Expect(compare.IsVersion("abc")).To(BeFalse())
+	})
+})
@@ -0,0 +1,3 @@
+// Package compare contains utilities for ordering string values that
+// represent numbers or semantic versions.
+package compare
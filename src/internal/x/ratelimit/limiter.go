@@ -0,0 +1,152 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Limiter is a set of token buckets, each identified by an arbitrary string
+// key.
+//
+// Each bucket holds at most n tokens and is refilled at a rate of n tokens per
+// Interval, where n is the capacity given to Take(). Buckets are created on
+// demand and discarded once they have refilled completely.
+type Limiter struct {
+	Interval time.Duration
+
+	mutex    sync.Mutex
+	buckets  map[string]*bucket
+	prunedAt time.Time
+}
+
+// bucket is a single token bucket within a limiter.
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// NewLimiter returns a limiter that refills its buckets once per interval.
+func NewLimiter(interval time.Duration) *Limiter {
+	if interval <= 0 {
+		panic("rate limit interval must be positive")
+	}
+
+	return &Limiter{
+		Interval: interval,
+		buckets:  map[string]*bucket{},
+	}
+}
+
+// Take removes a token from the bucket identified by k, which has a capacity
+// of n tokens.
+//
+// If the bucket is empty, ok is false and wait is the amount of time until the
+// next token becomes available.
+func (l *Limiter) Take(k string, n uint, now time.Time) (ok bool, wait time.Duration) {
+	if n == 0 {
+		return false, l.Interval
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.prune(now)
+
+	capacity := float64(n)
+	b, exists := l.buckets[k]
+
+	if exists {
+		b.refill(capacity, l.rate(capacity), now)
+	} else {
+		b = &bucket{capacity, now}
+		l.buckets[k] = b
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	return false, l.wait(capacity, b.tokens)
+}
+
+// Peek returns the result that Take() would return, without removing a token
+// from the bucket.
+func (l *Limiter) Peek(k string, n uint, now time.Time) (ok bool, wait time.Duration) {
+	if n == 0 {
+		return false, l.Interval
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	capacity := float64(n)
+
+	b, exists := l.buckets[k]
+	if !exists {
+		return true, 0
+	}
+
+	// refill a copy, so that the bucket itself is unchanged
+	c := *b
+	c.refill(capacity, l.rate(capacity), now)
+
+	if c.tokens >= 1 {
+		return true, 0
+	}
+
+	return false, l.wait(capacity, c.tokens)
+}
+
+// Len returns the number of buckets currently held by the limiter.
+func (l *Limiter) Len() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return len(l.buckets)
+}
+
+// rate returns the number of tokens added to a bucket with the given capacity
+// per nanosecond.
+func (l *Limiter) rate(capacity float64) float64 {
+	return capacity / float64(l.Interval)
+}
+
+// wait returns the amount of time until a bucket with the given capacity
+// holds a whole token, given that it currently holds the given number of
+// tokens. It is rounded up to the nearest nanosecond, so that the token is
+// always available once the wait has elapsed.
+func (l *Limiter) wait(capacity, tokens float64) time.Duration {
+	return time.Duration(math.Ceil(
+		(1 - tokens) * float64(l.Interval) / capacity,
+	))
+}
+
+// prune discards any buckets that are full, at most once per interval. A full
+// bucket behaves identically to a bucket that does not exist.
+func (l *Limiter) prune(now time.Time) {
+	if now.Sub(l.prunedAt) < l.Interval {
+		return
+	}
+
+	l.prunedAt = now
+
+	for k, b := range l.buckets {
+		if now.Sub(b.updatedAt) >= l.Interval {
+			delete(l.buckets, k)
+		}
+	}
+}
+
+// refill adds tokens to b based on the time elapsed since it was last updated.
+func (b *bucket) refill(capacity, rate float64, now time.Time) {
+	if elapsed := now.Sub(b.updatedAt); elapsed > 0 {
+		b.tokens += float64(elapsed) * rate
+		b.updatedAt = now
+	}
+
+	if b.tokens > capacity {
+		b.tokens = capacity
+	}
+}
@@ -0,0 +1,100 @@
+package ratelimit_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/rinq/rinq-go/src/internal/x/ratelimit"
+)
+
+var _ = Describe("Limiter", func() {
+	var (
+		now     time.Time
+		subject *Limiter
+	)
+
+	BeforeEach(func() {
+		now = time.Now()
+		subject = NewLimiter(time.Second)
+	})
+
+	Describe("Take", func() {
+		It("allows up to n requests within the interval", func() {
+			for i := 0; i < 3; i++ {
+				ok, _ := subject.Take("k", 3, now)
+				Expect(ok).To(BeTrue())
+			}
+
+			ok, wait := subject.Take("k", 3, now)
+			Expect(ok).To(BeFalse())
+			Expect(wait).To(Equal(time.Second/3 + 1)) // rounded up
+		})
+
+		It("returns a wait after which a token is available", func() {
+			for i := 0; i < 3; i++ {
+				subject.Take("k", 3, now)
+			}
+
+			_, wait := subject.Take("k", 3, now)
+
+			ok, _ := subject.Take("k", 3, now.Add(wait))
+			Expect(ok).To(BeTrue())
+		})
+
+		It("refills the bucket over time", func() {
+			subject.Take("k", 2, now)
+			subject.Take("k", 2, now)
+
+			ok, _ := subject.Take("k", 2, now.Add(500*time.Millisecond))
+			Expect(ok).To(BeTrue())
+
+			ok, _ = subject.Take("k", 2, now.Add(500*time.Millisecond))
+			Expect(ok).To(BeFalse())
+		})
+
+		It("maintains a separate bucket for each key", func() {
+			subject.Take("a", 1, now)
+
+			ok, _ := subject.Take("b", 1, now)
+			Expect(ok).To(BeTrue())
+		})
+
+		It("never allows requests when n is zero", func() {
+			ok, wait := subject.Take("k", 0, now)
+			Expect(ok).To(BeFalse())
+			Expect(wait).To(Equal(time.Second))
+		})
+
+		It("discards buckets that have refilled", func() {
+			subject.Take("a", 1, now)
+			subject.Take("b", 1, now.Add(2*time.Second))
+
+			Expect(subject.Len()).To(Equal(1))
+		})
+	})
+
+	Describe("Peek", func() {
+		It("returns the same result as Take() without taking a token", func() {
+			subject.Take("k", 2, now)
+
+			ok, _ := subject.Peek("k", 2, now)
+			Expect(ok).To(BeTrue())
+
+			ok, _ = subject.Peek("k", 2, now)
+			Expect(ok).To(BeTrue())
+
+			subject.Take("k", 2, now)
+
+			ok, wait := subject.Peek("k", 2, now)
+			Expect(ok).To(BeFalse())
+			Expect(wait).To(Equal(time.Second / 2))
+		})
+
+		It("never allows requests when n is zero", func() {
+			ok, wait := subject.Peek("k", 0, now)
+			Expect(ok).To(BeFalse())
+			Expect(wait).To(Equal(time.Second))
+		})
+	})
+})
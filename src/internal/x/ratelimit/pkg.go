@@ -0,0 +1,2 @@
+// Package ratelimit contains a keyed token-bucket rate limiter.
+package ratelimit
@@ -1,6 +1,8 @@
 package rinq
 
 import (
+	"time"
+
 	"github.com/rinq/rinq-go/src/internal/x/bufferpool"
 	"github.com/rinq/rinq-go/src/internal/x/repr"
 )
@@ -22,6 +24,21 @@ type Attr struct {
 	// IsFrozen is true if the attribute is "frozen" such that it can never be
 	// altered again (for a given session).
 	IsFrozen bool `json:"f,omitempty"`
+
+	// TTL is the time-to-live of the attribute's value. If it is non-zero, the
+	// attribute is cleared once TTL has elapsed since the update that set it.
+	// Clearing an expired attribute produces a new revision, as per
+	// Revision.Clear().
+	//
+	// Frozen attributes can not have a TTL. Attempting to update a session
+	// with such an attribute causes a panic.
+	TTL time.Duration `json:"t,omitempty"`
+
+	// Type describes how Value is encoded. Use the typed constructors, such
+	// as SetInt(), to create attributes with a type other than
+	// AttrTypeString, and the typed accessors, such as Attr.Int(), to convert
+	// the value back.
+	Type AttrType `json:"y,omitempty"`
 }
 
 // Set is a convenience method that creates an Attr with the specified key and
@@ -36,6 +53,12 @@ func Freeze(key, value string) Attr {
 	return Attr{Key: key, Value: value, IsFrozen: true}
 }
 
+// SetTTL is a convenience method that creates an Attr with the specified key
+// and value, that is cleared once ttl has elapsed.
+func SetTTL(key, value string, ttl time.Duration) Attr {
+	return Attr{Key: key, Value: value, TTL: ttl}
+}
+
 func (attr Attr) String() string {
 	buf := bufferpool.Get()
 	defer bufferpool.Put(buf)
@@ -72,5 +95,25 @@ type AttrTable interface {
 	// IsEmpty returns true if there are no attributes in the table.
 	IsEmpty() bool
 
+	// GetInt returns the value of the attribute with key k as an integer.
+	// ok is false if the attribute does not exist. err is non-nil if the
+	// value can not be converted.
+	GetInt(k string) (v int64, ok bool, err error)
+
+	// GetBool returns the value of the attribute with key k as a boolean.
+	// ok is false if the attribute does not exist. err is non-nil if the
+	// value can not be converted.
+	GetBool(k string) (v bool, ok bool, err error)
+
+	// GetTime returns the value of the attribute with key k as a time.
+	// ok is false if the attribute does not exist. err is non-nil if the
+	// value can not be converted.
+	GetTime(k string) (v time.Time, ok bool, err error)
+
+	// GetJSON unmarshals the value of the attribute with key k into v.
+	// ok is false if the attribute does not exist. err is non-nil if the
+	// value can not be unmarshaled.
+	GetJSON(k string, v interface{}) (ok bool, err error)
+
 	String() string
 }
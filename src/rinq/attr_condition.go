@@ -0,0 +1,54 @@
+package rinq
+
+import (
+	"github.com/rinq/rinq-go/src/internal/x/bufferpool"
+	"github.com/rinq/rinq-go/src/internal/x/repr"
+)
+
+// AttrCondition is a requirement on the current state of a session attribute.
+// Conditions are checked by Revision.UpdateIf() before any attributes are
+// updated.
+type AttrCondition struct {
+	// Key is the key of the attribute that the condition applies to.
+	Key string `json:"k"`
+
+	// Value is the expected current value of the attribute. It is ignored if
+	// IsUnchanged is true.
+	Value string `json:"v,omitempty"`
+
+	// IsUnchanged is true if the condition requires that the attribute has
+	// not been modified since the revision on which the update is performed,
+	// rather than requiring a specific value.
+	IsUnchanged bool `json:"u,omitempty"`
+}
+
+// ValueIs is a convenience method that returns an AttrCondition that requires
+// the attribute with the specified key to have the specified value.
+//
+// Attributes that have never been set have a value of the empty string.
+func ValueIs(key, value string) AttrCondition {
+	return AttrCondition{Key: key, Value: value}
+}
+
+// Unchanged is a convenience method that returns an AttrCondition that
+// requires the attribute with the specified key to have not been modified
+// since the revision on which the update is performed.
+func Unchanged(key string) AttrCondition {
+	return AttrCondition{Key: key, IsUnchanged: true}
+}
+
+func (c AttrCondition) String() string {
+	buf := bufferpool.Get()
+	defer bufferpool.Put(buf)
+
+	buf.WriteString(repr.Escape(c.Key))
+
+	if c.IsUnchanged {
+		buf.WriteString(" unchanged")
+	} else {
+		buf.WriteString("==")
+		buf.WriteString(repr.Escape(c.Value))
+	}
+
+	return buf.String()
+}
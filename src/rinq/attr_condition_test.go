@@ -0,0 +1,47 @@
+package rinq_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinq/rinq-go/src/rinq"
+)
+
+var _ = Describe("AttrCondition", func() {
+	Describe("String", func() {
+		It("uses 'double equals' syntax for value conditions", func() {
+			c := rinq.AttrCondition{Key: "foo", Value: "bar"}
+			Expect(c.String()).To(Equal("foo==bar"))
+		})
+
+		It("quotes empty values", func() {
+			c := rinq.AttrCondition{Key: "foo"}
+			Expect(c.String()).To(Equal(`foo==""`))
+		})
+
+		It("uses 'unchanged' syntax for unchanged conditions", func() {
+			c := rinq.AttrCondition{Key: "foo", IsUnchanged: true}
+			Expect(c.String()).To(Equal("foo unchanged"))
+		})
+
+		It("escapes conditions that contain certain characters", func() {
+			c := rinq.AttrCondition{Key: "foo key", Value: "bar value"}
+			Expect(c.String()).To(Equal(`"foo key"=="bar value"`))
+		})
+	})
+})
+
+var _ = Describe("ValueIs", func() {
+	It("returns a value condition", func() {
+		c := rinq.ValueIs("foo", "bar")
+		expected := rinq.AttrCondition{Key: "foo", Value: "bar"}
+		Expect(c).To(Equal(expected))
+	})
+})
+
+var _ = Describe("Unchanged", func() {
+	It("returns an unchanged condition", func() {
+		c := rinq.Unchanged("foo")
+		expected := rinq.AttrCondition{Key: "foo", IsUnchanged: true}
+		Expect(c).To(Equal(expected))
+	})
+})
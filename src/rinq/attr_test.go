@@ -1,6 +1,8 @@
 package rinq_test
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/rinq/rinq-go/src/rinq"
@@ -43,6 +45,14 @@ var _ = Describe("Set", func() {
 	})
 })
 
+var _ = Describe("SetTTL", func() {
+	It("returns an attribute with a TTL", func() {
+		attr := rinq.SetTTL("foo", "bar", time.Second)
+		expected := rinq.Attr{Key: "foo", Value: "bar", TTL: time.Second}
+		Expect(attr).To(Equal(expected))
+	})
+})
+
 var _ = Describe("Freeze", func() {
 	It("returns a frozen attribute", func() {
 		attr := rinq.Freeze("foo", "bar")
@@ -0,0 +1,234 @@
+package rinq
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// AttrType describes how the value of a session attribute is encoded.
+//
+// Attribute values are always transmitted as strings. The type allows a value
+// to be converted back to the Go type it was created from.
+type AttrType uint8
+
+const (
+	// AttrTypeString is the type of attributes with an arbitrary string value.
+	AttrTypeString AttrType = iota
+
+	// AttrTypeInt is the type of attributes with a base-10 integer value.
+	AttrTypeInt
+
+	// AttrTypeBool is the type of attributes with a value of "true" or "false".
+	AttrTypeBool
+
+	// AttrTypeTime is the type of attributes with an RFC 3339 timestamp value.
+	AttrTypeTime
+
+	// AttrTypeJSON is the type of attributes with a JSON value.
+	AttrTypeJSON
+)
+
+func (t AttrType) String() string {
+	switch t {
+	case AttrTypeString:
+		return "string"
+	case AttrTypeInt:
+		return "int"
+	case AttrTypeBool:
+		return "bool"
+	case AttrTypeTime:
+		return "time"
+	case AttrTypeJSON:
+		return "json"
+	}
+
+	return fmt.Sprintf("<unknown %d>", t)
+}
+
+// SetInt is a convenience method that creates an Attr with the specified key
+// and integer value.
+func SetInt(key string, value int64) Attr {
+	return Attr{
+		Key:   key,
+		Value: strconv.FormatInt(value, 10),
+		Type:  AttrTypeInt,
+	}
+}
+
+// SetBool is a convenience method that creates an Attr with the specified key
+// and boolean value.
+func SetBool(key string, value bool) Attr {
+	return Attr{
+		Key:   key,
+		Value: strconv.FormatBool(value),
+		Type:  AttrTypeBool,
+	}
+}
+
+// SetTime is a convenience method that creates an Attr with the specified key
+// and time value.
+func SetTime(key string, value time.Time) Attr {
+	return Attr{
+		Key:   key,
+		Value: value.Format(time.RFC3339Nano),
+		Type:  AttrTypeTime,
+	}
+}
+
+// SetJSON is a convenience method that creates an Attr with the specified key
+// and the JSON representation of value.
+//
+// It panics if value can not be represented as JSON.
+func SetJSON(key string, value interface{}) Attr {
+	buf, err := json.Marshal(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return Attr{
+		Key:   key,
+		Value: string(buf),
+		Type:  AttrTypeJSON,
+	}
+}
+
+// Int returns the attribute's value as an integer.
+//
+// Attributes with an empty value are treated as zero. An error is returned if
+// the attribute is of some other type, or its value can not be parsed.
+func (attr Attr) Int() (int64, error) {
+	if attr.Value == "" {
+		return 0, nil
+	}
+
+	if err := attr.checkType(AttrTypeInt); err != nil {
+		return 0, err
+	}
+
+	v, err := strconv.ParseInt(attr.Value, 10, 64)
+	if err != nil {
+		return 0, attr.conversionError(AttrTypeInt, err)
+	}
+
+	return v, nil
+}
+
+// Bool returns the attribute's value as a boolean.
+//
+// Attributes with an empty value are treated as false. An error is returned if
+// the attribute is of some other type, or its value can not be parsed.
+func (attr Attr) Bool() (bool, error) {
+	if attr.Value == "" {
+		return false, nil
+	}
+
+	if err := attr.checkType(AttrTypeBool); err != nil {
+		return false, err
+	}
+
+	v, err := strconv.ParseBool(attr.Value)
+	if err != nil {
+		return false, attr.conversionError(AttrTypeBool, err)
+	}
+
+	return v, nil
+}
+
+// Time returns the attribute's value as a time.
+//
+// Attributes with an empty value are treated as the zero time. An error is
+// returned if the attribute is of some other type, or its value can not be
+// parsed.
+func (attr Attr) Time() (time.Time, error) {
+	if attr.Value == "" {
+		return time.Time{}, nil
+	}
+
+	if err := attr.checkType(AttrTypeTime); err != nil {
+		return time.Time{}, err
+	}
+
+	v, err := time.Parse(time.RFC3339Nano, attr.Value)
+	if err != nil {
+		return time.Time{}, attr.conversionError(AttrTypeTime, err)
+	}
+
+	return v, nil
+}
+
+// JSON unmarshals the attribute's value into v.
+//
+// Attributes with an empty value leave v unchanged. An error is returned if
+// the attribute is of some other type, or its value can not be unmarshaled.
+func (attr Attr) JSON(v interface{}) error {
+	if attr.Value == "" {
+		return nil
+	}
+
+	if err := attr.checkType(AttrTypeJSON); err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal([]byte(attr.Value), v); err != nil {
+		return attr.conversionError(AttrTypeJSON, err)
+	}
+
+	return nil
+}
+
+// checkType returns an error if the attribute's value can not be converted to
+// t. Values of type AttrTypeString can be converted to any type.
+func (attr Attr) checkType(t AttrType) error {
+	if attr.Type == t || attr.Type == AttrTypeString {
+		return nil
+	}
+
+	return attr.conversionError(t, nil)
+}
+
+func (attr Attr) conversionError(t AttrType, cause error) AttrConversionError {
+	return AttrConversionError{
+		Key:   attr.Key,
+		Type:  attr.Type,
+		Want:  t,
+		Cause: cause,
+	}
+}
+
+// AttrConversionError indicates a failure to convert an attribute's value to
+// a specific type.
+type AttrConversionError struct {
+	// Key is the key of the attribute.
+	Key string
+
+	// Type is the type of the attribute.
+	Type AttrType
+
+	// Want is the type that the value could not be converted to.
+	Want AttrType
+
+	// Cause is the error that occurred while parsing the value. It is nil if
+	// the attribute's type is incompatible with Want.
+	Cause error
+}
+
+func (err AttrConversionError) Error() string {
+	if err.Cause == nil {
+		return fmt.Sprintf(
+			"can not convert the '%s' attribute from %s to %s",
+			err.Key,
+			err.Type,
+			err.Want,
+		)
+	}
+
+	return fmt.Sprintf(
+		"can not convert the '%s' attribute from %s to %s: %s",
+		err.Key,
+		err.Type,
+		err.Want,
+		err.Cause,
+	)
+}
@@ -0,0 +1,139 @@
+package rinq_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinq/rinq-go/src/rinq"
+)
+
+var _ = Describe("SetInt", func() {
+	It("returns an integer attribute", func() {
+		attr := rinq.SetInt("foo", -123)
+		expected := rinq.Attr{Key: "foo", Value: "-123", Type: rinq.AttrTypeInt}
+		Expect(attr).To(Equal(expected))
+	})
+})
+
+var _ = Describe("SetBool", func() {
+	It("returns a boolean attribute", func() {
+		attr := rinq.SetBool("foo", true)
+		expected := rinq.Attr{Key: "foo", Value: "true", Type: rinq.AttrTypeBool}
+		Expect(attr).To(Equal(expected))
+	})
+})
+
+var _ = Describe("SetTime", func() {
+	It("returns a time attribute", func() {
+		t := time.Date(2018, 2, 3, 4, 5, 6, 7, time.UTC)
+		attr := rinq.SetTime("foo", t)
+		expected := rinq.Attr{Key: "foo", Value: "2018-02-03T04:05:06.000000007Z", Type: rinq.AttrTypeTime}
+		Expect(attr).To(Equal(expected))
+	})
+})
+
+var _ = Describe("SetJSON", func() {
+	It("returns a JSON attribute", func() {
+		attr := rinq.SetJSON("foo", map[string]int{"a": 1})
+		expected := rinq.Attr{Key: "foo", Value: `{"a":1}`, Type: rinq.AttrTypeJSON}
+		Expect(attr).To(Equal(expected))
+	})
+
+	It("panics if the value can not be represented as JSON", func() {
+		Expect(func() {
+			rinq.SetJSON("foo", make(chan int))
+		}).To(Panic())
+	})
+})
+
+var _ = Describe("Attr", func() {
+	Describe("Int", func() {
+		It("returns the value of an integer attribute", func() {
+			v, err := rinq.SetInt("foo", 123).Int()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v).To(BeEquivalentTo(123))
+		})
+
+		It("parses the value of a string attribute", func() {
+			v, err := rinq.Set("foo", "123").Int()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v).To(BeEquivalentTo(123))
+		})
+
+		It("returns zero for an empty attribute", func() {
+			v, err := rinq.Attr{Key: "foo", Type: rinq.AttrTypeBool}.Int()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v).To(BeEquivalentTo(0))
+		})
+
+		It("returns an error if the attribute is of another type", func() {
+			_, err := rinq.SetBool("foo", true).Int()
+			Expect(err).To(Equal(rinq.AttrConversionError{
+				Key:  "foo",
+				Type: rinq.AttrTypeBool,
+				Want: rinq.AttrTypeInt,
+			}))
+		})
+
+		It("returns an error if the value can not be parsed", func() {
+			_, err := rinq.Set("foo", "bar").Int()
+			Expect(err).To(BeAssignableToTypeOf(rinq.AttrConversionError{}))
+			Expect(err.(rinq.AttrConversionError).Cause).To(HaveOccurred())
+		})
+	})
+
+	Describe("Bool", func() {
+		It("returns the value of a boolean attribute", func() {
+			v, err := rinq.SetBool("foo", true).Bool()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v).To(BeTrue())
+		})
+
+		It("returns an error if the attribute is of another type", func() {
+			_, err := rinq.SetInt("foo", 1).Bool()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Time", func() {
+		It("returns the value of a time attribute", func() {
+			t := time.Date(2018, 2, 3, 4, 5, 6, 7, time.UTC)
+			v, err := rinq.SetTime("foo", t).Time()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v.Equal(t)).To(BeTrue())
+		})
+
+		It("returns an error if the value can not be parsed", func() {
+			_, err := rinq.Set("foo", "yesterday").Time()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("JSON", func() {
+		It("unmarshals the value of a JSON attribute", func() {
+			var v map[string]int
+			err := rinq.SetJSON("foo", map[string]int{"a": 1}).JSON(&v)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v).To(Equal(map[string]int{"a": 1}))
+		})
+
+		It("returns an error if the value can not be unmarshaled", func() {
+			var v map[string]int
+			err := rinq.SetJSON("foo", []int{1}).JSON(&v)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("AttrConversionError", func() {
+	It("includes the types in the error message", func() {
+		err := rinq.AttrConversionError{
+			Key:  "foo",
+			Type: rinq.AttrTypeBool,
+			Want: rinq.AttrTypeInt,
+		}
+
+		Expect(err.Error()).To(Equal("can not convert the 'foo' attribute from bool to int"))
+	})
+})
@@ -3,6 +3,7 @@ package rinq
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/rinq/rinq-go/src/rinq/ident"
 )
@@ -140,6 +141,32 @@ func FailureType(err error) string {
 	return f.Type
 }
 
+// RateLimitedFailure is the failure type used when a command request is
+// rejected because it exceeds a rate limit configured on the server.
+//
+// The failure payload contains a hint indicating how long the caller should
+// wait before retrying the request. See RetryAfter().
+const RateLimitedFailure = "rate-limited"
+
+// RetryAfter returns the amount of time to wait before retrying a command
+// request that failed because it exceeded a rate limit.
+//
+// ok is false if err is not a failure of type RateLimitedFailure, or if the
+// failure does not contain a hint.
+func RetryAfter(err error) (d time.Duration, ok bool) {
+	f, isFailure := err.(Failure)
+	if !isFailure || f.Type != RateLimitedFailure || f.Payload == nil {
+		return 0, false
+	}
+
+	var ms uint64
+	if f.Payload.Decode(&ms) != nil {
+		return 0, false
+	}
+
+	return time.Duration(ms) * time.Millisecond, true
+}
+
 // IsCommandError returns true if err was sent in response to a command request,
 // as opposed to a local error that occurred when attempting to send the request.
 func IsCommandError(err error) bool {
@@ -2,6 +2,7 @@ package rinq_test
 
 import (
 	"errors"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -89,6 +90,35 @@ var _ = Describe("IsCommandError", func() {
 	})
 })
 
+var _ = Describe("RetryAfter", func() {
+	It("returns the hint from a rate-limited failure", func() {
+		p := rinq.NewPayload(250)
+		defer p.Close()
+
+		d, ok := rinq.RetryAfter(rinq.Failure{Type: rinq.RateLimitedFailure, Payload: p})
+		Expect(ok).To(BeTrue())
+		Expect(d).To(Equal(250 * time.Millisecond))
+	})
+
+	It("returns false for rate-limited failures without a hint", func() {
+		_, ok := rinq.RetryAfter(rinq.Failure{Type: rinq.RateLimitedFailure})
+		Expect(ok).To(BeFalse())
+	})
+
+	It("returns false for other failure types", func() {
+		p := rinq.NewPayload(250)
+		defer p.Close()
+
+		_, ok := rinq.RetryAfter(rinq.Failure{Type: "other", Payload: p})
+		Expect(ok).To(BeFalse())
+	})
+
+	It("returns false for other error types", func() {
+		_, ok := rinq.RetryAfter(errors.New(""))
+		Expect(ok).To(BeFalse())
+	})
+})
+
 var _ = Describe("CommandError", func() {
 	Describe("Error", func() {
 		It("returns the message", func() {
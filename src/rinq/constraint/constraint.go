@@ -7,10 +7,11 @@ import "github.com/rinq/rinq-go/src/internal/x/bufferpool"
 //
 // See Session.NotifyMany() to send a multicast notification.
 type Constraint struct {
-	Op    op           `json:"o,omitempty"`
-	Terms []Constraint `json:"t,omitempty"`
-	Key   string       `json:"k,omitempty"`
-	Value string       `json:"v,omitempty"`
+	Op     op           `json:"o,omitempty"`
+	Terms  []Constraint `json:"t,omitempty"`
+	Key    string       `json:"k,omitempty"`
+	Value  string       `json:"v,omitempty"`
+	Values []string     `json:"vs,omitempty"`
 }
 
 // And returns a Constraint that evaluates to true if both c and con evaluate to
@@ -50,6 +51,22 @@ func (c Constraint) Accept(v Visitor, args ...interface{}) (interface{}, error)
 		return v.And(c.Terms, args...)
 	case orOp:
 		return v.Or(c.Terms, args...)
+	case inOp:
+		return v.In(c.Key, c.Values, args...)
+	case prefixOp:
+		return v.Prefix(c.Key, c.Value, args...)
+	case matchesOp:
+		return v.Matches(c.Key, c.Value, args...)
+	case existsOp:
+		return v.Exists(c.Key, args...)
+	case lessOp:
+		return v.Less(c.Key, c.Value, args...)
+	case greaterOp:
+		return v.Greater(c.Key, c.Value, args...)
+	case versionLessOp:
+		return v.VersionLess(c.Key, c.Value, args...)
+	case versionGreaterOp:
+		return v.VersionGreater(c.Key, c.Value, args...)
 	default:
 		panic("unrecognized constraint operation: " + c.Op)
 	}
@@ -143,3 +160,106 @@ func Or(cons ...Constraint) Constraint {
 		Terms: cons,
 	}
 }
+
+// In returns a Constraint that evaluates to true when the attribute k is equal
+// to any of the values in vs.
+func In(k string, vs ...string) Constraint {
+	return Constraint{
+		Op:     inOp,
+		Key:    k,
+		Values: vs,
+	}
+}
+
+// Prefix returns a Constraint that evaluates to true when the attribute k
+// begins with p.
+func Prefix(k, p string) Constraint {
+	return Constraint{
+		Op:    prefixOp,
+		Key:   k,
+		Value: p,
+	}
+}
+
+// MaxPatternLength is the maximum length of the regular expression used by a
+// Matches() constraint, in bytes.
+const MaxPatternLength = 256
+
+// Matches returns a Constraint that evaluates to true when the attribute k
+// contains a match of the regular expression pattern. Use the ^ and $ anchors
+// to match the entire value.
+//
+// The pattern uses the syntax accepted by the regexp package, and can not be
+// longer than MaxPatternLength.
+func Matches(k, pattern string) Constraint {
+	return Constraint{
+		Op:    matchesOp,
+		Key:   k,
+		Value: pattern,
+	}
+}
+
+// Exists returns a Constraint that evaluates to true when the attribute k has
+// a value other than the empty string. It is equivalent to NotEmpty(), as an
+// attribute that has never been set is indistinguishable from one that has
+// been set to the empty string.
+func Exists(k string) Constraint {
+	return Constraint{
+		Op:  existsOp,
+		Key: k,
+	}
+}
+
+// Less returns a Constraint that evaluates to true when the attribute k is
+// numerically less than v.
+//
+// v must be a decimal number, such as "-1.5". The constraint evaluates to false
+// if the attribute is not a number. Use VersionLess() to compare versions.
+func Less(k, v string) Constraint {
+	return Constraint{
+		Op:    lessOp,
+		Key:   k,
+		Value: v,
+	}
+}
+
+// Greater returns a Constraint that evaluates to true when the attribute k is
+// numerically greater than v.
+//
+// v must be a decimal number, such as "-1.5". The constraint evaluates to false
+// if the attribute is not a number. Use VersionGreater() to compare versions.
+func Greater(k, v string) Constraint {
+	return Constraint{
+		Op:    greaterOp,
+		Key:   k,
+		Value: v,
+	}
+}
+
+// VersionLess returns a Constraint that evaluates to true when the attribute k
+// is a semantic version with a lower precedence than v.
+//
+// v must be a semantic version, such as "1.2.3". The minor and patch
+// components are optional, so "1.10" is compared as "1.10.0", not as a decimal
+// number. The constraint evaluates to false if the attribute is not a version.
+func VersionLess(k, v string) Constraint {
+	return Constraint{
+		Op:    versionLessOp,
+		Key:   k,
+		Value: v,
+	}
+}
+
+// VersionGreater returns a Constraint that evaluates to true when the attribute
+// k is a semantic version with a higher precedence than v.
+//
+// v must be a semantic version, such as "1.2.3". The minor and patch
+// components are optional, so "1.10" is compared as "1.10.0", not as a decimal
+// number. The constraint evaluates to false if the attribute is not a version.
+func VersionGreater(k, v string) Constraint {
+	return Constraint{
+		Op:    versionGreaterOp,
+		Key:   k,
+		Value: v,
+	}
+}
@@ -1,6 +1,8 @@
 package constraint_test
 
 import (
+	"strings"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
@@ -84,6 +86,76 @@ var _ = Describe("Constraint", func() {
 
 			Expect(err).To(HaveOccurred())
 		})
+
+		It("returns nil when the comparison constraints are valid", func() {
+			con := constraint.And(
+				constraint.In("a", "1", "2"),
+				constraint.Prefix("b", "x"),
+				constraint.Matches("c", "^x+$"),
+				constraint.Exists("d"),
+				constraint.Less("e", "1.5"),
+				constraint.Greater("f", "-2"),
+				constraint.VersionLess("g", "1.10"),
+				constraint.VersionGreater("h", "v1.2.3-beta"),
+			)
+			err := con.Validate()
+
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns an error if an IN constraint has no values", func() {
+			con := constraint.In("a")
+			err := con.Validate()
+
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("returns an error if a MATCHES constraint has an invalid pattern", func() {
+			con := constraint.Matches("a", "(")
+			err := con.Validate()
+
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("returns an error if a MATCHES constraint has a pattern that is too long", func() {
+			con := constraint.Matches("a", strings.Repeat("x", constraint.MaxPatternLength+1))
+			err := con.Validate()
+
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("returns an error if a LESS constraint has a value that is not a number", func() {
+			con := constraint.Less("a", "1.2.3")
+			err := con.Validate()
+
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("returns an error if a GREATER constraint has a value that is not a number", func() {
+			con := constraint.Greater("a", "x")
+			err := con.Validate()
+
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("returns an error if a VERSION LESS constraint has a value that is not a version", func() {
+			con := constraint.VersionLess("a", "-1.5")
+			err := con.Validate()
+
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("returns an error if a VERSION GREATER constraint has a value that is not a version", func() {
+			con := constraint.VersionGreater("a", "x")
+			err := con.Validate()
+
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("returns an error if a LESS or GREATER constraint has a value that is not finite", func() {
+			Expect(constraint.Less("a", "NaN").Validate()).To(HaveOccurred())
+			Expect(constraint.Greater("a", "Inf").Validate()).To(HaveOccurred())
+		})
 	})
 
 	Describe("And", func() {
@@ -213,6 +285,47 @@ var _ = Describe("Constraint", func() {
 				"{a=1|b=2}",
 			),
 
+			Entry(
+				"In",
+				constraint.In("a", "1", "2"),
+				"{a in [1, 2]}",
+			),
+			Entry(
+				"Prefix",
+				constraint.Prefix("a", "eu-"),
+				"{a^=eu-}",
+			),
+			Entry(
+				"Matches",
+				constraint.Matches("a", "^eu-"),
+				`{a~="^eu-"}`,
+			),
+			Entry(
+				"Exists",
+				constraint.Exists("a"),
+				"{?a}",
+			),
+			Entry(
+				"Less",
+				constraint.Less("a", "3"),
+				"{a<3}",
+			),
+			Entry(
+				"Greater",
+				constraint.Greater("a", "-1.5"),
+				"{a>-1.5}",
+			),
+			Entry(
+				"VersionLess",
+				constraint.VersionLess("a", "1.10"),
+				"{a~<1.10}",
+			),
+			Entry(
+				"VersionGreater",
+				constraint.VersionGreater("a", "1.2.3"),
+				"{a~>1.2.3}",
+			),
+
 			Entry(
 				"nested compound expression",
 				constraint.And(
@@ -0,0 +1,410 @@
+package constraint
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Parse returns the constraint represented by s, which uses the syntax
+// produced by Constraint.String().
+//
+// For example, Parse("ns::{a=1, !b}") returns a constraint equivalent to
+// Within("ns", Equal("a", "1"), Empty("b")).
+//
+// The returned constraint is not validated, see Constraint.Validate().
+func Parse(s string) (Constraint, error) {
+	p := &parser{s: s}
+
+	con, err := p.parseTerm()
+	if err != nil {
+		return Constraint{}, err
+	}
+
+	p.skipSpace()
+
+	if !p.isEOF() {
+		return Constraint{}, p.errorf("unexpected %q after constraint", p.peek())
+	}
+
+	return con, nil
+}
+
+// MustParse returns the constraint represented by s, or panics if s is not a
+// valid constraint. It is intended for constraints that are hard-coded.
+func MustParse(s string) Constraint {
+	con, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return con
+}
+
+// ParseError indicates a failure to parse the string representation of a
+// constraint.
+type ParseError struct {
+	// Offset is the position within the input at which the error occurred,
+	// in bytes.
+	Offset int
+
+	// Message describes the error.
+	Message string
+}
+
+func (err ParseError) Error() string {
+	return fmt.Sprintf(
+		"can not parse constraint, %s at offset %d",
+		err.Message,
+		err.Offset,
+	)
+}
+
+// parser is a recursive-descent parser for the syntax produced by stringer.
+type parser struct {
+	s   string
+	pos int
+}
+
+// parseTerm parses a single constraint, which may be a compound constraint
+// enclosed in braces.
+func (p *parser) parseTerm() (Constraint, error) {
+	p.skipSpace()
+
+	if p.isEOF() {
+		return Constraint{}, p.errorf("unexpected end of input")
+	}
+
+	switch p.peek() {
+	case '{':
+		return p.parseGroup()
+
+	case '*':
+		p.pos++
+		return None, nil
+
+	case '!':
+		p.pos++
+
+		// a NOT constraint is always followed by whitespace or a group,
+		// whereas an EMPTY constraint is followed directly by the key.
+		if p.isEOF() || p.peek() == ' ' || p.peek() == '{' {
+			con, err := p.parseTerm()
+			if err != nil {
+				return Constraint{}, err
+			}
+
+			return Not(con), nil
+		}
+
+		k, err := p.parseString("key")
+		if err != nil {
+			return Constraint{}, err
+		}
+
+		return Empty(k), nil
+
+	case '?':
+		p.pos++
+
+		k, err := p.parseString("key")
+		if err != nil {
+			return Constraint{}, err
+		}
+
+		return Exists(k), nil
+	}
+
+	return p.parseAttrTerm()
+}
+
+// parseGroup parses a sequence of constraints enclosed in braces, separated
+// by either commas or pipes.
+func (p *parser) parseGroup() (Constraint, error) {
+	start := p.pos
+	p.pos++ // skip the opening brace
+
+	var (
+		cons []Constraint
+		sep  byte
+	)
+
+	p.skipSpace()
+
+	if p.consume("}") {
+		return And(), nil
+	}
+
+	for {
+		con, err := p.parseTerm()
+		if err != nil {
+			return Constraint{}, err
+		}
+
+		cons = append(cons, con)
+
+		p.skipSpace()
+
+		if p.isEOF() {
+			p.pos = start
+			return Constraint{}, p.errorf("unclosed brace")
+		}
+
+		c := p.peek()
+		p.pos++
+
+		switch c {
+		case '}':
+			if len(cons) == 1 {
+				return cons[0], nil
+			} else if sep == '|' {
+				return Or(cons...), nil
+			}
+
+			return And(cons...), nil
+
+		case ',', '|':
+			if sep != 0 && sep != c {
+				p.pos--
+				return Constraint{}, p.errorf("can not mix ',' and '|' in the same group")
+			}
+
+			sep = c
+
+		default:
+			p.pos--
+			return Constraint{}, p.errorf("expected ',', '|' or '}', found %q", c)
+		}
+	}
+}
+
+// parseAttrTerm parses a constraint that begins with an attribute key, or a
+// WITHIN constraint that begins with a namespace.
+func (p *parser) parseAttrTerm() (Constraint, error) {
+	// The namespace of a WITHIN constraint ends at the first "::". Namespaces
+	// that contain "::", or that end with a colon, are quoted.
+	start := p.pos
+
+	if p.peek() == '"' {
+		ns, err := p.parseQuotedString()
+		if err != nil {
+			return Constraint{}, err
+		}
+
+		if p.consume("::") {
+			return p.parseWithin(ns)
+		}
+
+		p.pos = start // the quoted string is a key
+	} else {
+		end := start
+		for end < len(p.s) && (isBare(p.s[end]) || p.s[end] == ':') {
+			end++
+		}
+
+		if i := strings.Index(p.s[start:end], "::"); i > 0 {
+			p.pos = start + i + 2
+			return p.parseWithin(p.s[start : start+i])
+		}
+	}
+
+	k, err := p.parseString("key")
+	if err != nil {
+		return Constraint{}, err
+	}
+
+	var fn func(k, v string) Constraint
+
+	switch {
+	case p.consume("!="):
+		fn = NotEqual
+	case p.consume("^="):
+		fn = Prefix
+	case p.consume("~="):
+		fn = Matches
+	case p.consume("~<"):
+		fn = VersionLess
+	case p.consume("~>"):
+		fn = VersionGreater
+	case p.consume("="):
+		fn = Equal
+	case p.consume("<"):
+		fn = Less
+	case p.consume(">"):
+		fn = Greater
+	default:
+		if p.consumeKeyword("in") {
+			return p.parseIn(k)
+		}
+
+		return NotEmpty(k), nil
+	}
+
+	v, err := p.parseString("value")
+	if err != nil {
+		return Constraint{}, err
+	}
+
+	return fn(k, v), nil
+}
+
+// parseWithin parses the constraints that follow the "::" of a WITHIN
+// constraint for the ns namespace.
+func (p *parser) parseWithin(ns string) (Constraint, error) {
+	con, err := p.parseTerm()
+	if err != nil {
+		return Constraint{}, err
+	}
+
+	if con.Op == andOp {
+		return Within(ns, con.Terms...), nil
+	}
+
+	return Within(ns, con), nil
+}
+
+// parseIn parses the list of values of an IN constraint for the attribute k.
+func (p *parser) parseIn(k string) (Constraint, error) {
+	p.skipSpace()
+
+	if !p.consume("[") {
+		return Constraint{}, p.errorf("expected '['")
+	}
+
+	var vs []string
+
+	p.skipSpace()
+
+	if p.consume("]") {
+		return In(k, vs...), nil
+	}
+
+	for {
+		p.skipSpace()
+
+		v, err := p.parseString("value")
+		if err != nil {
+			return Constraint{}, err
+		}
+
+		vs = append(vs, v)
+
+		p.skipSpace()
+
+		if p.consume("]") {
+			return In(k, vs...), nil
+		} else if !p.consume(",") {
+			return Constraint{}, p.errorf("expected ',' or ']'")
+		}
+	}
+}
+
+// parseString parses a bare or quoted string. desc describes the string in
+// error messages.
+func (p *parser) parseString(desc string) (string, error) {
+	if p.isEOF() {
+		return "", p.errorf("expected %s, found end of input", desc)
+	}
+
+	if p.peek() == '"' {
+		return p.parseQuotedString()
+	}
+
+	start := p.pos
+	for !p.isEOF() && isBare(p.peek()) {
+		p.pos++
+	}
+
+	if p.pos == start {
+		return "", p.errorf("expected %s, found %q", desc, p.peek())
+	}
+
+	return p.s[start:p.pos], nil
+}
+
+// parseQuotedString parses a JSON-encoded string, as produced by repr.Escape().
+func (p *parser) parseQuotedString() (string, error) {
+	start := p.pos
+	end := start + 1
+
+	for {
+		if end >= len(p.s) {
+			return "", p.errorf("unterminated string")
+		}
+
+		c := p.s[end]
+		end++
+
+		if c == '\\' {
+			end++
+		} else if c == '"' {
+			break
+		}
+	}
+
+	var v string
+	if err := json.Unmarshal([]byte(p.s[start:end]), &v); err != nil {
+		return "", p.errorf("invalid string: %s", err)
+	}
+
+	p.pos = end
+
+	return v, nil
+}
+
+// consume advances past tok if it occurs at the current position.
+func (p *parser) consume(tok string) bool {
+	if strings.HasPrefix(p.s[p.pos:], tok) {
+		p.pos += len(tok)
+		return true
+	}
+
+	return false
+}
+
+// consumeKeyword advances past the keyword kw if it occurs after whitespace at
+// the current position, and is not part of a longer bare string.
+func (p *parser) consumeKeyword(kw string) bool {
+	start := p.pos
+	p.skipSpace()
+
+	if p.pos != start && p.consume(kw) {
+		if p.isEOF() || !isBare(p.peek()) {
+			return true
+		}
+	}
+
+	p.pos = start
+
+	return false
+}
+
+func (p *parser) skipSpace() {
+	for !p.isEOF() && strings.IndexByte(" \t\r\n", p.peek()) != -1 {
+		p.pos++
+	}
+}
+
+func (p *parser) isEOF() bool {
+	return p.pos >= len(p.s)
+}
+
+func (p *parser) peek() byte {
+	return p.s[p.pos]
+}
+
+func (p *parser) errorf(f string, v ...interface{}) error {
+	return ParseError{
+		Offset:  p.pos,
+		Message: fmt.Sprintf(f, v...),
+	}
+}
+
+// isBare returns true if c may appear in a string without quotes. It must be
+// kept in sync with the pattern used by repr.Escape().
+func isBare(c byte) bool {
+	return c >= 'a' && c <= 'z' ||
+		c >= 'A' && c <= 'Z' ||
+		c >= '0' && c <= '9' ||
+		c == '_' || c == '.' || c == '-'
+}
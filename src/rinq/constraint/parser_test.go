@@ -0,0 +1,185 @@
+package constraint_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/rinq/rinq-go/src/rinq/constraint"
+)
+
+var _ = Describe("Parse", func() {
+	DescribeTable(
+		"returns the constraint represented by the string",
+		func(s string, con constraint.Constraint) {
+			c, err := constraint.Parse(s)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c).To(Equal(con))
+			Expect(c.String()).To(Equal(s))
+		},
+
+		Entry("None", "{*}", constraint.None),
+		Entry("Within", "ns::{a=1}", constraint.Within("ns", constraint.Equal("a", "1"))),
+		Entry(
+			"Within with multiple values",
+			"ns::{a=1, b=2}",
+			constraint.Within("ns", constraint.Equal("a", "1"), constraint.Equal("b", "2")),
+		),
+		Entry(
+			"Within with namespace containing a colon",
+			"acme:users::{a=1}",
+			constraint.Within("acme:users", constraint.Equal("a", "1")),
+		),
+		Entry(
+			"Within with namespace containing '::'",
+			`"acme::users"::{a=1}`,
+			constraint.Within("acme::users", constraint.Equal("a", "1")),
+		),
+		Entry(
+			"Within with namespace ending in a colon",
+			`"acme:"::{a=1}`,
+			constraint.Within("acme:", constraint.Equal("a", "1")),
+		),
+		Entry(
+			"nested Within",
+			"ns::{other::a=1}",
+			constraint.Within("ns", constraint.Within("other", constraint.Equal("a", "1"))),
+		),
+		Entry(
+			"nested Within with multiple values",
+			"ns::{other::{a=1, b=2}}",
+			constraint.Within(
+				"ns",
+				constraint.Within("other", constraint.Equal("a", "1"), constraint.Equal("b", "2")),
+			),
+		),
+		Entry(
+			"nested Within alongside other constraints",
+			"ns::{other::a=1, b=2}",
+			constraint.Within(
+				"ns",
+				constraint.Within("other", constraint.Equal("a", "1")),
+				constraint.Equal("b", "2"),
+			),
+		),
+		Entry(
+			"Within with Or",
+			"ns::{a=1|b=2}",
+			constraint.Within("ns", constraint.Or(constraint.Equal("a", "1"), constraint.Equal("b", "2"))),
+		),
+		Entry(
+			"Within with nested And and Or",
+			"ns::{a=1, {b=2|c=3}}",
+			constraint.Within(
+				"ns",
+				constraint.Equal("a", "1"),
+				constraint.Or(constraint.Equal("b", "2"), constraint.Equal("c", "3")),
+			),
+		),
+		Entry(
+			"Or of Within",
+			"{ns::a=1|other::b=2}",
+			constraint.Or(
+				constraint.Within("ns", constraint.Equal("a", "1")),
+				constraint.Within("other", constraint.Equal("b", "2")),
+			),
+		),
+		Entry(
+			"Within nested in compound constraint",
+			"{ns::a=1, b=2}",
+			constraint.And(
+				constraint.Within("ns", constraint.Equal("a", "1")),
+				constraint.Equal("b", "2"),
+			),
+		),
+		Entry("Equal", "{a=1}", constraint.Equal("a", "1")),
+		Entry("Equal with quoted strings", `{"a b"="1\"2"}`, constraint.Equal("a b", `1"2`)),
+		Entry("Equal with quoted key in Within", `ns::{"a b"=1}`, constraint.Within("ns", constraint.Equal("a b", "1"))),
+		Entry("NotEqual", "{a!=1}", constraint.NotEqual("a", "1")),
+		Entry("Empty", "{!a}", constraint.Empty("a")),
+		Entry("NotEmpty", "{a}", constraint.NotEmpty("a")),
+		Entry("Not", "{! a=1}", constraint.Not(constraint.Equal("a", "1"))),
+		Entry(
+			"Not with compound expression",
+			"{! {a=1, b=2}}",
+			constraint.Not(constraint.And(constraint.Equal("a", "1"), constraint.Equal("b", "2"))),
+		),
+		Entry("Not with empty constraint", "{! !a}", constraint.Not(constraint.Empty("a"))),
+		Entry(
+			"And",
+			"{a=1, b=2}",
+			constraint.And(constraint.Equal("a", "1"), constraint.Equal("b", "2")),
+		),
+		Entry("And without terms", "{}", constraint.And()),
+		Entry(
+			"Or",
+			"{a=1|b=2}",
+			constraint.Or(constraint.Equal("a", "1"), constraint.Equal("b", "2")),
+		),
+		Entry(
+			"nested compound expression",
+			"{a=1, {b=2|c=3}}",
+			constraint.And(
+				constraint.Equal("a", "1"),
+				constraint.Or(constraint.Equal("b", "2"), constraint.Equal("c", "3")),
+			),
+		),
+		Entry("In", "{a in [1, 2]}", constraint.In("a", "1", "2")),
+		Entry("In with key named 'in'", "{in in [1]}", constraint.In("in", "1")),
+		Entry("Prefix", "{a^=eu-}", constraint.Prefix("a", "eu-")),
+		Entry("Matches", `{a~="^eu-"}`, constraint.Matches("a", "^eu-")),
+		Entry("Exists", "{?a}", constraint.Exists("a")),
+		Entry("Less", "{a<3}", constraint.Less("a", "3")),
+		Entry("Greater", "{a>-1.5}", constraint.Greater("a", "-1.5")),
+		Entry("VersionLess", "{a~<1.10}", constraint.VersionLess("a", "1.10")),
+		Entry("VersionGreater", "{a~>1.2.3}", constraint.VersionGreater("a", "1.2.3")),
+	)
+
+	It("allows optional whitespace", func() {
+		c, err := constraint.Parse(" ns:: { a=1 ,b in[ x,y ] } ")
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c).To(Equal(constraint.Within(
+			"ns",
+			constraint.Equal("a", "1"),
+			constraint.In("b", "x", "y"),
+		)))
+	})
+
+	DescribeTable(
+		"returns an error describing the position of invalid syntax",
+		func(s string, offset int) {
+			_, err := constraint.Parse(s)
+
+			Expect(err).To(BeAssignableToTypeOf(constraint.ParseError{}))
+			Expect(err.(constraint.ParseError).Offset).To(Equal(offset))
+		},
+
+		Entry("empty string", "", 0),
+		Entry("unclosed brace", "{a=1, b=2", 0),
+		Entry("missing value", "{a=}", 3),
+		Entry("mixed separators", "{a, b|c}", 5),
+		Entry("unterminated string", `{a="1}`, 3),
+		Entry("trailing characters", "{a=1} b", 6),
+		Entry("missing list", "{a in 1}", 6),
+	)
+})
+
+var _ = Describe("MustParse", func() {
+	It("returns the constraint represented by the string", func() {
+		Expect(constraint.MustParse("{a=1}")).To(Equal(constraint.Equal("a", "1")))
+	})
+
+	It("panics if the string is invalid", func() {
+		Expect(func() {
+			constraint.MustParse("{")
+		}).To(Panic())
+	})
+})
+
+var _ = Describe("ParseError", func() {
+	It("includes the offset in the error message", func() {
+		err := constraint.ParseError{Offset: 3, Message: "expected value"}
+		Expect(err.Error()).To(Equal("can not parse constraint, expected value at offset 3"))
+	})
+})
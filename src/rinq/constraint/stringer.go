@@ -2,6 +2,7 @@ package constraint
 
 import (
 	"bytes"
+	"strings"
 
 	"github.com/rinq/rinq-go/src/internal/x/repr"
 )
@@ -20,8 +21,27 @@ func (s *stringer) None(...interface{}) (interface{}, error) {
 }
 
 func (s *stringer) Within(ns string, cons []Constraint, _ ...interface{}) (interface{}, error) {
+	// The namespace ends at the first "::", so namespaces that contain "::",
+	// or that end with a colon, are quoted.
+	if strings.Index(ns+"::", "::") != len(ns) {
+		ns = repr.Escape(ns)
+	}
+
 	s.buf.WriteString(ns)
 	s.buf.WriteString("::")
+
+	// A nested WITHIN constraint is enclosed in braces, so that it is clear
+	// which namespace each constraint applies to.
+	if len(cons) == 1 && cons[0].Op == withinOp {
+		s.buf.WriteRune('{')
+		s.push(true)
+		_, _ = cons[0].Accept(s)
+		s.pop()
+		s.buf.WriteRune('}')
+
+		return nil, nil
+	}
+
 	s.join(", ", cons)
 
 	return nil, nil
@@ -81,6 +101,81 @@ func (s *stringer) Or(cons []Constraint, _ ...interface{}) (interface{}, error)
 	return nil, nil
 }
 
+func (s *stringer) In(k string, vs []string, _ ...interface{}) (interface{}, error) {
+	s.open()
+
+	s.buf.WriteString(repr.Escape(k))
+	s.buf.WriteString(" in [")
+
+	for i, v := range vs {
+		if i != 0 {
+			s.buf.WriteString(", ")
+		}
+		s.buf.WriteString(repr.Escape(v))
+	}
+
+	s.buf.WriteRune(']')
+
+	s.close()
+
+	return nil, nil
+}
+
+func (s *stringer) Prefix(k, p string, _ ...interface{}) (interface{}, error) {
+	s.binary(k, "^=", p)
+
+	return nil, nil
+}
+
+func (s *stringer) Matches(k, pattern string, _ ...interface{}) (interface{}, error) {
+	s.binary(k, "~=", pattern)
+
+	return nil, nil
+}
+
+func (s *stringer) Exists(k string, _ ...interface{}) (interface{}, error) {
+	s.open()
+	s.buf.WriteRune('?')
+	s.buf.WriteString(repr.Escape(k))
+	s.close()
+
+	return nil, nil
+}
+
+func (s *stringer) Less(k, v string, _ ...interface{}) (interface{}, error) {
+	s.binary(k, "<", v)
+
+	return nil, nil
+}
+
+func (s *stringer) Greater(k, v string, _ ...interface{}) (interface{}, error) {
+	s.binary(k, ">", v)
+
+	return nil, nil
+}
+
+func (s *stringer) VersionLess(k, v string, _ ...interface{}) (interface{}, error) {
+	s.binary(k, "~<", v)
+
+	return nil, nil
+}
+
+func (s *stringer) VersionGreater(k, v string, _ ...interface{}) (interface{}, error) {
+	s.binary(k, "~>", v)
+
+	return nil, nil
+}
+
+// binary writes a constraint that compares the attribute k to v using the
+// given operator.
+func (s *stringer) binary(k, op, v string) {
+	s.open()
+	s.buf.WriteString(repr.Escape(k))
+	s.buf.WriteString(op)
+	s.buf.WriteString(repr.Escape(v))
+	s.close()
+}
+
 func (s *stringer) join(sep string, cons []Constraint) {
 	if len(cons) == 1 {
 		_, _ = cons[0].Accept(s)
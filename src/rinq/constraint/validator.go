@@ -2,8 +2,10 @@ package constraint
 
 import (
 	"errors"
+	"regexp"
 
 	"github.com/rinq/rinq-go/src/internal/namespaces"
+	"github.com/rinq/rinq-go/src/internal/x/compare"
 )
 
 type validator struct{}
@@ -69,3 +71,63 @@ func (v *validator) Or(cons []Constraint, _ ...interface{}) (interface{}, error)
 
 	return nil, nil
 }
+
+func (v *validator) In(_ string, vs []string, _ ...interface{}) (interface{}, error) {
+	if len(vs) == 0 {
+		return nil, errors.New("IN constraint has no values")
+	}
+
+	return nil, nil
+}
+
+func (v *validator) Prefix(string, string, ...interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+func (v *validator) Matches(_ string, pattern string, _ ...interface{}) (interface{}, error) {
+	if len(pattern) > MaxPatternLength {
+		return nil, errors.New("MATCHES constraint has a pattern that is too long")
+	}
+
+	if _, err := regexp.Compile(pattern); err != nil {
+		return nil, errors.New("MATCHES constraint has invalid pattern: " + err.Error())
+	}
+
+	return nil, nil
+}
+
+func (v *validator) Exists(string, ...interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+func (v *validator) Less(_ string, val string, _ ...interface{}) (interface{}, error) {
+	if !compare.IsNumber(val) {
+		return nil, errors.New("LESS constraint value is not a number")
+	}
+
+	return nil, nil
+}
+
+func (v *validator) Greater(_ string, val string, _ ...interface{}) (interface{}, error) {
+	if !compare.IsNumber(val) {
+		return nil, errors.New("GREATER constraint value is not a number")
+	}
+
+	return nil, nil
+}
+
+func (v *validator) VersionLess(_ string, val string, _ ...interface{}) (interface{}, error) {
+	if !compare.IsVersion(val) {
+		return nil, errors.New("VERSION LESS constraint value is not a version")
+	}
+
+	return nil, nil
+}
+
+func (v *validator) VersionGreater(_ string, val string, _ ...interface{}) (interface{}, error) {
+	if !compare.IsVersion(val) {
+		return nil, errors.New("VERSION GREATER constraint value is not a version")
+	}
+
+	return nil, nil
+}
@@ -10,6 +10,15 @@ const (
 	notOp      op = "!"
 	andOp      op = "&"
 	orOp       op = "|"
+	inOp       op = "in"
+	prefixOp   op = "^="
+	matchesOp  op = "~="
+	existsOp   op = "?"
+	lessOp     op = "<"
+	greaterOp  op = ">"
+
+	versionLessOp    op = "~<"
+	versionGreaterOp op = "~>"
 )
 
 // Visitor is used to walk a constraint hierarchy.
@@ -21,4 +30,12 @@ type Visitor interface {
 	Not(con Constraint, args ...interface{}) (interface{}, error)
 	And(cons []Constraint, args ...interface{}) (interface{}, error)
 	Or(cons []Constraint, args ...interface{}) (interface{}, error)
+	In(k string, vs []string, args ...interface{}) (interface{}, error)
+	Prefix(k, p string, args ...interface{}) (interface{}, error)
+	Matches(k, pattern string, args ...interface{}) (interface{}, error)
+	Exists(k string, args ...interface{}) (interface{}, error)
+	Less(k, v string, args ...interface{}) (interface{}, error)
+	Greater(k, v string, args ...interface{}) (interface{}, error)
+	VersionLess(k, v string, args ...interface{}) (interface{}, error)
+	VersionGreater(k, v string, args ...interface{}) (interface{}, error)
 }
@@ -0,0 +1,75 @@
+package rinq
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// mutateAttempts is the maximum number of times Mutate() calls fn.
+	mutateAttempts = 10
+
+	// mutateMinBackoff and mutateMaxBackoff bound the delay between attempts.
+	mutateMinBackoff = 5 * time.Millisecond
+	mutateMaxBackoff = 500 * time.Millisecond
+)
+
+// Mutate atomically updates the attributes within the ns namespace of a
+// session using optimistic concurrency.
+//
+// fn is called with the latest known revision of the session. It computes the
+// attributes to update, typically by reading attributes from r. The returned
+// attributes are then applied with r.Update().
+//
+// If fn or the update fails because r is out of date, that is, ShouldRetry(err)
+// returns true, the latest revision is obtained with Refresh() and fn is called
+// again, after a short delay that increases with each attempt. If the update
+// can not be made within a bounded number of attempts, the last error is
+// returned.
+//
+// Any other error, such as FrozenAttributesError or NotFoundError, is returned
+// immediately, as is the error from ctx if it is canceled while waiting to
+// retry.
+//
+// On success, rev is the revision produced by the update. As a convenience, if
+// the mutation fails for any reason, rev is the last revision passed to fn.
+func Mutate(
+	ctx context.Context,
+	rev Revision,
+	ns string,
+	fn func(r Revision) ([]Attr, error),
+) (Revision, error) {
+	backoff := mutateMinBackoff
+
+	for attempt := 1; ; attempt++ {
+		attrs, err := fn(rev)
+		if err == nil {
+			var next Revision
+			next, err = rev.Update(ctx, ns, attrs...)
+			if err == nil {
+				return next, nil
+			}
+		}
+
+		if !ShouldRetry(err) || attempt == mutateAttempts {
+			return rev, err
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return rev, ctx.Err()
+		}
+
+		if backoff *= 2; backoff > mutateMaxBackoff {
+			backoff = mutateMaxBackoff
+		}
+
+		next, err := rev.Refresh(ctx)
+		if err != nil {
+			return rev, err
+		}
+
+		rev = next
+	}
+}
@@ -0,0 +1,128 @@
+package rinq_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinq/rinq-go/src/rinq"
+)
+
+// fakeRevision is a rinq.Revision that fails updates until a number of
+// refreshes have been performed.
+type fakeRevision struct {
+	rinq.Revision
+
+	rev       int
+	staleTill int
+	updateErr error
+	updated   []rinq.Attr
+}
+
+func (r *fakeRevision) Refresh(ctx context.Context) (rinq.Revision, error) {
+	next := *r
+	next.rev++
+	return &next, nil
+}
+
+func (r *fakeRevision) Update(ctx context.Context, ns string, attrs ...rinq.Attr) (rinq.Revision, error) {
+	if r.updateErr != nil {
+		return r, r.updateErr
+	}
+
+	if r.rev < r.staleTill {
+		return r, rinq.StaleUpdateError{}
+	}
+
+	next := *r
+	next.rev++
+	next.updated = attrs
+	return &next, nil
+}
+
+var _ = Describe("Mutate", func() {
+	var (
+		ctx   context.Context
+		calls int
+		fn    = func(r rinq.Revision) ([]rinq.Attr, error) {
+			calls++
+			return []rinq.Attr{rinq.Set("a", "1")}, nil
+		}
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		calls = 0
+	})
+
+	It("returns the revision produced by the update", func() {
+		rev, err := rinq.Mutate(ctx, &fakeRevision{}, "ns", fn)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rev.(*fakeRevision).updated).To(Equal([]rinq.Attr{rinq.Set("a", "1")}))
+		Expect(calls).To(Equal(1))
+	})
+
+	It("retries on the latest revision if the update is stale", func() {
+		rev, err := rinq.Mutate(ctx, &fakeRevision{staleTill: 3}, "ns", fn)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rev.(*fakeRevision).rev).To(Equal(4))
+		Expect(calls).To(Equal(4))
+	})
+
+	It("retries if fn returns a stale fetch error", func() {
+		failed := false
+
+		_, err := rinq.Mutate(ctx, &fakeRevision{}, "ns", func(r rinq.Revision) ([]rinq.Attr, error) {
+			if !failed {
+				failed = true
+				return nil, rinq.StaleFetchError{}
+			}
+
+			return fn(r)
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(calls).To(Equal(1))
+	})
+
+	It("gives up after a bounded number of attempts", func() {
+		_, err := rinq.Mutate(ctx, &fakeRevision{staleTill: 1000}, "ns", fn)
+
+		Expect(rinq.ShouldRetry(err)).To(BeTrue())
+		Expect(calls).To(Equal(10))
+	})
+
+	It("returns other update errors immediately", func() {
+		r := &fakeRevision{updateErr: rinq.FrozenAttributesError{}}
+
+		rev, err := rinq.Mutate(ctx, r, "ns", fn)
+
+		Expect(err).To(Equal(rinq.FrozenAttributesError{}))
+		Expect(rev).To(BeIdenticalTo(r))
+		Expect(calls).To(Equal(1))
+	})
+
+	It("returns errors from fn immediately", func() {
+		expected := errors.New("<error>")
+
+		_, err := rinq.Mutate(ctx, &fakeRevision{}, "ns", func(rinq.Revision) ([]rinq.Attr, error) {
+			calls++
+			return nil, expected
+		})
+
+		Expect(err).To(Equal(expected))
+		Expect(calls).To(Equal(1))
+	})
+
+	It("returns the context error if ctx is canceled while waiting to retry", func() {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, err := rinq.Mutate(ctx, &fakeRevision{staleTill: 1}, "ns", fn)
+
+		Expect(err).To(Equal(context.Canceled))
+	})
+})
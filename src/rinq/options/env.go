@@ -11,12 +11,15 @@ import (
 //
 // The environment variables are listed below.
 //
-// - RINQ_DEFAULT_TIMEOUT (duration in milliseconds, non-zero)
-// - RINQ_LOG_DEBUG       (boolean 'true' or 'false')
-// - RINQ_COMMAND_WORKERS (positive integer, non-zero)
-// - RINQ_SESSION_WORKERS (positive integer, non-zero)
-// - RINQ_PRUNE_INTERVAL  (duration in milliseconds, non-zero)
-// - RINQ_PRODUCT         (string)
+// - RINQ_DEFAULT_TIMEOUT     (duration in milliseconds, non-zero)
+// - RINQ_LOG_DEBUG           (boolean 'true' or 'false')
+// - RINQ_COMMAND_WORKERS     (positive integer, non-zero)
+// - RINQ_SESSION_WORKERS     (positive integer, non-zero)
+// - RINQ_PRUNE_INTERVAL      (duration in milliseconds, non-zero)
+// - RINQ_PRODUCT             (string)
+// - RINQ_FAIL_FAST           (boolean 'true' or 'false')
+// - RINQ_MULTICAST_FILTERING (boolean 'true' or 'false')
+// - RINQ_RETENTION           (boolean 'true' or 'false')
 func FromEnv() ([]Option, error) {
 	var o []Option
 
@@ -60,5 +63,26 @@ func FromEnv() ([]Option, error) {
 		o = append(o, Product(p))
 	}
 
+	failFast, ok, err := env.Bool("RINQ_FAIL_FAST")
+	if err != nil {
+		return nil, err
+	} else if ok {
+		o = append(o, FailFast(failFast))
+	}
+
+	filtering, ok, err := env.Bool("RINQ_MULTICAST_FILTERING")
+	if err != nil {
+		return nil, err
+	} else if ok {
+		o = append(o, MulticastFiltering(filtering))
+	}
+
+	retention, ok, err := env.Bool("RINQ_RETENTION")
+	if err != nil {
+		return nil, err
+	} else if ok {
+		o = append(o, Retention(retention))
+	}
+
 	return o, nil
 }
@@ -18,6 +18,9 @@ var _ = Describe("FromEnv", func() {
 		os.Setenv("RINQ_SESSION_WORKERS", "")
 		os.Setenv("RINQ_PRUNE_INTERVAL", "")
 		os.Setenv("RINQ_PRODUCT", "")
+		os.Setenv("RINQ_FAIL_FAST", "")
+		os.Setenv("RINQ_MULTICAST_FILTERING", "")
+		os.Setenv("RINQ_RETENTION", "")
 	})
 
 	It("returns an empty slice when no environment variables are set", func() {
@@ -161,4 +164,67 @@ var _ = Describe("FromEnv", func() {
 			Expect(opts.Product).To(Equal("my-app"))
 		})
 	})
+
+	Context("RINQ_FAIL_FAST", func() {
+		It("returns a FailFast option", func() {
+			os.Setenv("RINQ_FAIL_FAST", "true")
+			o, err := options.FromEnv()
+
+			Expect(err).NotTo(HaveOccurred())
+
+			opts, err := options.NewOptions(o...)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(opts.FailFast).To(BeTrue())
+		})
+
+		It("returns an error if the value is not a boolean", func() {
+			os.Setenv("RINQ_FAIL_FAST", "invalid")
+			_, err := options.FromEnv()
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("RINQ_MULTICAST_FILTERING", func() {
+		It("returns a MulticastFiltering option", func() {
+			os.Setenv("RINQ_MULTICAST_FILTERING", "true")
+			o, err := options.FromEnv()
+
+			Expect(err).NotTo(HaveOccurred())
+
+			opts, err := options.NewOptions(o...)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(opts.MulticastFiltering).To(BeTrue())
+		})
+
+		It("returns an error if the value is not a boolean", func() {
+			os.Setenv("RINQ_MULTICAST_FILTERING", "invalid")
+			_, err := options.FromEnv()
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("RINQ_RETENTION", func() {
+		It("returns a Retention option", func() {
+			os.Setenv("RINQ_RETENTION", "true")
+			o, err := options.FromEnv()
+
+			Expect(err).NotTo(HaveOccurred())
+
+			opts, err := options.NewOptions(o...)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(opts.Retention).To(BeTrue())
+		})
+
+		It("returns an error if the value is not a boolean", func() {
+			os.Setenv("RINQ_RETENTION", "invalid")
+			_, err := options.FromEnv()
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
 })
@@ -0,0 +1,80 @@
+package options
+
+import "time"
+
+// ListenOption is a function that applies a configuration change to a single
+// command namespace. See Peer.Listen().
+type ListenOption func(v listenVisitor) error
+
+// Workers returns a ListenOption that specifies the number of load-balanced
+// command REQUESTS in the namespace that are accepted at any given time.
+//
+// A namespace with a worker limit receives requests independently of all other
+// namespaces, such that a slow namespace can not starve the others. If n is
+// zero the namespace shares the peer-wide limit described by CommandWorkers().
+//
+// The limit can be changed while the peer is running by calling Peer.Listen()
+// again with a new value.
+func Workers(n uint) ListenOption {
+	return func(v listenVisitor) error {
+		return v.applyWorkers(n)
+	}
+}
+
+// RateLimitKey describes how command requests are grouped when applying a
+// rate limit. Each group has its own independent limit.
+type RateLimitKey int
+
+const (
+	// PerNamespace applies a single rate limit to all requests in the
+	// namespace.
+	PerNamespace RateLimitKey = iota
+
+	// PerCommand applies a separate rate limit to each command.
+	PerCommand
+
+	// PerSession applies a separate rate limit to each source session.
+	PerSession
+
+	// PerPeer applies a separate rate limit to each peer that owns a source
+	// session.
+	PerPeer
+)
+
+// RateLimit returns a ListenOption that limits the rate at which command
+// requests are accepted to n requests per interval d. Requests are grouped
+// according to k, each group having its own limit.
+//
+// Requests that exceed the limit are not passed to the command handler,
+// instead they fail with a failure of type rinq.RateLimitedFailure. See
+// rinq.RetryAfter().
+//
+// The option may be specified multiple times, in which case a request must be
+// within every limit to be accepted.
+func RateLimit(n uint, d time.Duration, k RateLimitKey) ListenOption {
+	return func(v listenVisitor) error {
+		return v.applyRateLimit(RateLimitPolicy{
+			Requests: n,
+			Interval: d,
+			Key:      k,
+		})
+	}
+}
+
+// SessionRateLimit returns a ListenOption that limits the rate at which each
+// source session may send command requests to n requests per interval d.
+//
+// The limit for an individual session can be overridden by the session's
+// attributes. If the attribute identified by ns and key is a non-empty
+// integer, it is used as the number of requests per interval instead of n.
+func SessionRateLimit(n uint, d time.Duration, ns, key string) ListenOption {
+	return func(v listenVisitor) error {
+		return v.applyRateLimit(RateLimitPolicy{
+			Requests:      n,
+			Interval:      d,
+			Key:           PerSession,
+			AttrNamespace: ns,
+			AttrKey:       key,
+		})
+	}
+}
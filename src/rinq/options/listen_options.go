@@ -0,0 +1,60 @@
+package options
+
+import (
+	"time"
+
+	"github.com/rinq/rinq-go/src/internal/namespaces"
+)
+
+// ListenOptions is a structure representing a resolved set of options for a
+// single command namespace.
+type ListenOptions struct {
+	Workers    uint
+	RateLimits []RateLimitPolicy
+}
+
+// RateLimitPolicy describes a limit on the rate at which command requests are
+// accepted.
+type RateLimitPolicy struct {
+	Requests uint
+	Interval time.Duration
+	Key      RateLimitKey
+
+	// AttrNamespace and AttrKey identify an attribute of the source session
+	// that overrides Requests, if set.
+	AttrNamespace string
+	AttrKey       string
+}
+
+// NewListenOptions returns a new ListenOptions object from the given options,
+// with default values for any options that are not specified.
+func NewListenOptions(opts ...ListenOption) (o ListenOptions, err error) {
+	err = ApplyListen(&o, opts...)
+	return
+}
+
+// applyWorkers sets the Workers value.
+func (o *ListenOptions) applyWorkers(v uint) error {
+	o.Workers = v
+	return nil
+}
+
+// applyRateLimit adds a RateLimits value.
+func (o *ListenOptions) applyRateLimit(v RateLimitPolicy) error {
+	if v.Interval <= 0 {
+		panic("rate limit interval must be positive")
+	}
+
+	switch v.Key {
+	case PerNamespace, PerCommand, PerSession, PerPeer:
+	default:
+		panic("rate limit key is not recognized")
+	}
+
+	if v.AttrNamespace != "" {
+		namespaces.MustValidate(v.AttrNamespace)
+	}
+
+	o.RateLimits = append(o.RateLimits, v)
+	return nil
+}
@@ -35,6 +35,25 @@ func CommandWorkers(n uint) Option {
 	}
 }
 
+// Backpressure returns an Option that enables adaptive adjustment of the
+// number of incoming command requests that are accepted at any given time.
+//
+// The limit starts at the value given by CommandWorkers(), which is also the
+// maximum. It is halved whenever a command handler takes longer than latency
+// to complete, or the heap exceeds heap bytes, and is increased by one for
+// each full "window" of handlers that complete within latency. Requests that
+// this peer is not prepared to accept are left on the broker for other peers.
+//
+// If heap is zero, memory usage is not considered. If latency is zero,
+// backpressure is disabled, which is the default.
+//
+// Namespaces with their own worker limit, see Workers(), are not affected.
+func Backpressure(latency time.Duration, heap uint64) Option {
+	return func(v visitor) error {
+		return v.applyBackpressure(latency, heap)
+	}
+}
+
 // SessionWorkers returns an Option that specifies the number of command RESPONSES
 // or notifications that are buffered in memory at any given time.
 func SessionWorkers(n uint) Option {
@@ -45,6 +64,9 @@ func SessionWorkers(n uint) Option {
 
 // PruneInterval returns an Option that specifies how often the cache of remote
 // session information is purged of unused data.
+//
+// It is also the interval at which the peer checks that the owners of
+// remotely watched sessions are still pushing changes. See Revision.Watch().
 func PruneInterval(t time.Duration) Option {
 	return func(v visitor) error {
 		return v.applyPruneInterval(t)
@@ -62,6 +84,92 @@ func Product(p string) Option {
 	}
 }
 
+// FailFast returns an Option that specifies whether a panic within a command
+// or notification handler should crash the application.
+//
+// By default, such panics are recovered. The panic is logged, the command
+// request is answered with a rinq.CommandError, and the message is rejected
+// without being re-queued.
+func FailFast(enabled bool) Option {
+	return func(v visitor) error {
+		return v.applyFailFast(enabled)
+	}
+}
+
+// Watchdog returns an Option that enables tracking of running command and
+// notification handlers.
+//
+// A warning is logged, including the stack of the handler's goroutine, when a
+// handler has been running for longer than threshold. Such handlers are also
+// returned by Peer.LongRunningHandlers().
+//
+// If threshold is zero, the watchdog is disabled, which is the default.
+func Watchdog(threshold time.Duration) Option {
+	return func(v visitor) error {
+		return v.applyWatchdog(threshold)
+	}
+}
+
+// SessionQuota returns an Option that limits the number and size of the
+// attributes of each session created by the peer.
+//
+// Updates that would exceed any of the limits in p fail with a
+// rinq.QuotaExceededError. By default, no limits are enforced.
+func SessionQuota(p QuotaPolicy) Option {
+	return func(v visitor) error {
+		return v.applyQuota(p)
+	}
+}
+
+// SessionMailbox returns an Option that limits the number of incoming
+// notifications that are queued for each session created by the peer.
+//
+// Notifications for each session are handled one at a time, in the order they
+// are received. When a notification arrives for a session that already has
+// p.Depth notifications queued, p.Overflow determines whether the notification
+// waits for space, or a notification is discarded. By default, the number of
+// queued notifications is limited only by SessionWorkers().
+func SessionMailbox(p MailboxPolicy) Option {
+	return func(v visitor) error {
+		return v.applyMailbox(p)
+	}
+}
+
+// MulticastFiltering returns an Option that specifies whether multicast
+// notifications are filtered by the broker.
+//
+// When enabled, the peer advertises coarse routing hints derived from the
+// attributes of its sessions, and notifications sent with constraints that
+// require specific attribute values, such as constraint.Equal(), are only
+// delivered to peers that advertise a matching hint. A hint is advertised
+// before the attribute update that requires it completes.
+//
+// Peers with filtering disabled receive every multicast notification, so it
+// can be enabled on some peers in the network and not others. It is disabled
+// by default.
+func MulticastFiltering(enabled bool) Option {
+	return func(v visitor) error {
+		return v.applyMulticastFiltering(enabled)
+	}
+}
+
+// Retention returns an Option that specifies whether notifications sent by
+// Session.NotifyManyRetained() are retained and replayed.
+//
+// When enabled, the peer retains the notifications sent by its sessions, and
+// asks every peer to replay its retained notifications each time one of its
+// sessions starts listening. When disabled, such notifications are delivered
+// as per Session.NotifyMany(), and are never replayed.
+//
+// Notifications are only replayed between peers that both have it enabled, so
+// it must be enabled on every peer in the network that uses retained
+// notifications. It is disabled by default.
+func Retention(enabled bool) Option {
+	return func(v visitor) error {
+		return v.applyRetention(enabled)
+	}
+}
+
 // Tracer returns an Option that specifies an OpenTracing tracer to use for
 // tracking Rinq operations.
 //
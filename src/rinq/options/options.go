@@ -1,6 +1,7 @@
 package options
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/jmalloc/twelf/src/twelf"
@@ -9,13 +10,95 @@ import (
 
 // Options is a structure representing a resolved set of options.
 type Options struct {
-	DefaultTimeout time.Duration
-	Logger         twelf.Logger
-	CommandWorkers uint
-	SessionWorkers uint
-	PruneInterval  time.Duration
-	Product        string
-	Tracer         opentracing.Tracer
+	DefaultTimeout      time.Duration
+	Logger              twelf.Logger
+	CommandWorkers      uint
+	SessionWorkers      uint
+	PruneInterval       time.Duration
+	Product             string
+	Tracer              opentracing.Tracer
+	BackpressureLatency time.Duration
+	BackpressureHeap    uint64
+	FailFast            bool
+	WatchdogThreshold   time.Duration
+	Quota               QuotaPolicy
+	MulticastFiltering  bool
+	Mailbox             MailboxPolicy
+	Retention           bool
+}
+
+// QuotaPolicy describes limits on the attributes of each local session. A
+// limit of zero is not enforced.
+//
+// Only attributes with a non-empty value count towards the limits.
+type QuotaPolicy struct {
+	// KeysPerNamespace is the maximum number of attributes in each namespace.
+	KeysPerNamespace uint
+
+	// Namespaces is the maximum number of namespaces that contain attributes.
+	Namespaces uint
+
+	// ValueSize is the maximum size of each attribute value, in bytes.
+	ValueSize uint
+
+	// TotalSize is the maximum combined size of all attribute keys and
+	// values, in bytes.
+	TotalSize uint
+}
+
+// IsZero returns true if none of the limits are enforced.
+func (p QuotaPolicy) IsZero() bool {
+	return p == QuotaPolicy{}
+}
+
+// MailboxPolicy describes how incoming notifications are queued for each local
+// session.
+//
+// Each session handles its notifications one at a time, in the order they are
+// received, while the notifications of different sessions are handled
+// concurrently.
+type MailboxPolicy struct {
+	// Depth is the maximum number of notifications that are queued for each
+	// session, not including the notification currently being handled. A
+	// depth of zero is not enforced, in which case the number of queued
+	// notifications is limited only by SessionWorkers().
+	Depth uint
+
+	// Overflow determines what happens to a notification that arrives for a
+	// session with a full mailbox.
+	Overflow OverflowPolicy
+}
+
+// OverflowPolicy determines what happens to a notification that arrives for a
+// session with a full mailbox.
+type OverflowPolicy int
+
+const (
+	// BlockOnOverflow holds the notification until there is space in the
+	// mailbox. Held notifications are not acknowledged until they are
+	// handled, so the number held is limited by SessionWorkers(), and the
+	// notifications of other sessions are not delayed.
+	BlockOnOverflow OverflowPolicy = iota
+
+	// DropNewest discards the notification that has just arrived.
+	DropNewest
+
+	// DropOldest discards the oldest notification in the mailbox to make
+	// space for the notification that has just arrived.
+	DropOldest
+)
+
+func (p OverflowPolicy) String() string {
+	switch p {
+	case BlockOnOverflow:
+		return "block"
+	case DropNewest:
+		return "drop-newest"
+	case DropOldest:
+		return "drop-oldest"
+	}
+
+	return fmt.Sprintf("<unknown %d>", int(p))
 }
 
 // NewOptions returns a new Options object from the given options, with default
@@ -74,3 +157,60 @@ func (o *Options) applyTracer(v opentracing.Tracer) error {
 	o.Tracer = v
 	return nil
 }
+
+// applyFailFast sets the FailFast value.
+func (o *Options) applyFailFast(v bool) error {
+	o.FailFast = v
+	return nil
+}
+
+// applyWatchdog sets the WatchdogThreshold value.
+func (o *Options) applyWatchdog(v time.Duration) error {
+	if v < 0 {
+		panic("watchdog threshold must not be negative")
+	}
+
+	o.WatchdogThreshold = v
+	return nil
+}
+
+// applyMulticastFiltering sets the MulticastFiltering value.
+func (o *Options) applyMulticastFiltering(v bool) error {
+	o.MulticastFiltering = v
+	return nil
+}
+
+// applyRetention sets the Retention value.
+func (o *Options) applyRetention(v bool) error {
+	o.Retention = v
+	return nil
+}
+
+// applyMailbox sets the Mailbox value.
+func (o *Options) applyMailbox(v MailboxPolicy) error {
+	switch v.Overflow {
+	case BlockOnOverflow, DropNewest, DropOldest:
+	default:
+		panic("mailbox overflow policy is not recognized")
+	}
+
+	o.Mailbox = v
+	return nil
+}
+
+// applyQuota sets the Quota value.
+func (o *Options) applyQuota(v QuotaPolicy) error {
+	o.Quota = v
+	return nil
+}
+
+// applyBackpressure sets the BackpressureLatency and BackpressureHeap values.
+func (o *Options) applyBackpressure(l time.Duration, h uint64) error {
+	if l < 0 {
+		panic("backpressure latency must not be negative")
+	}
+
+	o.BackpressureLatency = l
+	o.BackpressureHeap = h
+	return nil
+}
@@ -26,4 +26,118 @@ var _ = Describe("NewOptions", func() {
 			Tracer:         opentracing.NoopTracer{},
 		}))
 	})
+
+	It("applies the Backpressure option", func() {
+		opts, err := options.NewOptions(
+			options.Backpressure(250*time.Millisecond, 1<<30),
+		)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(opts.BackpressureLatency).To(Equal(250 * time.Millisecond))
+		Expect(opts.BackpressureHeap).To(Equal(uint64(1 << 30)))
+	})
+
+	It("applies the Watchdog option", func() {
+		opts, err := options.NewOptions(
+			options.Watchdog(10 * time.Second),
+		)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(opts.WatchdogThreshold).To(Equal(10 * time.Second))
+	})
+
+	It("applies the SessionQuota option", func() {
+		p := options.QuotaPolicy{
+			KeysPerNamespace: 10,
+			Namespaces:       2,
+			ValueSize:        100,
+			TotalSize:        1000,
+		}
+
+		opts, err := options.NewOptions(options.SessionQuota(p))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(opts.Quota).To(Equal(p))
+	})
+
+	It("applies the MulticastFiltering option", func() {
+		opts, err := options.NewOptions(options.MulticastFiltering(true))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(opts.MulticastFiltering).To(BeTrue())
+	})
+
+	It("applies the Retention option", func() {
+		opts, err := options.NewOptions(options.Retention(true))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(opts.Retention).To(BeTrue())
+	})
+
+	It("applies the SessionMailbox option", func() {
+		p := options.MailboxPolicy{
+			Depth:    10,
+			Overflow: options.DropOldest,
+		}
+
+		opts, err := options.NewOptions(options.SessionMailbox(p))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(opts.Mailbox).To(Equal(p))
+	})
+
+	It("panics if the mailbox overflow policy is not recognized", func() {
+		Expect(func() {
+			options.NewOptions(options.SessionMailbox(options.MailboxPolicy{Overflow: -1}))
+		}).To(Panic())
+	})
+
+	It("panics if the watchdog threshold is negative", func() {
+		Expect(func() {
+			options.NewOptions(options.Watchdog(-time.Second))
+		}).To(Panic())
+	})
+
+	It("panics if the backpressure latency is negative", func() {
+		Expect(func() {
+			options.NewOptions(options.Backpressure(-time.Second, 0))
+		}).To(Panic())
+	})
+})
+
+var _ = Describe("NewListenOptions", func() {
+	It("uses the correct defaults", func() {
+		opts, err := options.NewListenOptions()
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(opts).To(Equal(options.ListenOptions{
+			Workers: 0,
+		}))
+	})
+
+	It("applies the RateLimit option", func() {
+		opts, err := options.NewListenOptions(
+			options.RateLimit(10, time.Second, options.PerCommand),
+			options.SessionRateLimit(5, time.Minute, "ns", "limit"),
+		)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(opts.RateLimits).To(Equal([]options.RateLimitPolicy{
+			{Requests: 10, Interval: time.Second, Key: options.PerCommand},
+			{Requests: 5, Interval: time.Minute, Key: options.PerSession, AttrNamespace: "ns", AttrKey: "limit"},
+		}))
+	})
+
+	It("panics if the rate limit interval is not positive", func() {
+		Expect(func() {
+			options.NewListenOptions(options.RateLimit(10, 0, options.PerNamespace))
+		}).To(Panic())
+	})
+
+	It("applies the Workers option", func() {
+		opts, err := options.NewListenOptions(options.Workers(3))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(opts.Workers).To(Equal(uint(3)))
+	})
 })
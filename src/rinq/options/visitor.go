@@ -17,6 +17,13 @@ type visitor interface {
 	applyPruneInterval(time.Duration) error
 	applyProduct(string) error
 	applyTracer(opentracing.Tracer) error
+	applyBackpressure(time.Duration, uint64) error
+	applyFailFast(bool) error
+	applyWatchdog(time.Duration) error
+	applyQuota(QuotaPolicy) error
+	applyMulticastFiltering(bool) error
+	applyMailbox(MailboxPolicy) error
+	applyRetention(bool) error
 }
 
 // Apply applies the default options, then a sequence of additional options to v.
@@ -55,6 +62,28 @@ func Apply(v visitor, opts ...Option) error {
 	return nil
 }
 
+// listenVisitor handles the application of listen options.
+type listenVisitor interface {
+	applyWorkers(uint) error
+	applyRateLimit(RateLimitPolicy) error
+}
+
+// ApplyListen applies the default listen options, then a sequence of
+// additional options to v.
+func ApplyListen(v listenVisitor, opts ...ListenOption) error {
+	if err := v.applyWorkers(0); err != nil {
+		return err
+	}
+
+	for _, o := range opts {
+		if err := o(v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 var defaultLogger twelf.Logger
 
 func init() {
@@ -1,6 +1,11 @@
 package rinq
 
-import "github.com/rinq/rinq-go/src/rinq/ident"
+import (
+	"context"
+
+	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/options"
+)
 
 // Peer represents a connection to a Rinq network.
 //
@@ -33,16 +38,67 @@ type Peer interface {
 	// handler h is invoked.
 	//
 	// Repeated calls to Listen() with the same namespace simply changes the
-	// handler associated with that namespace.
+	// handler and options associated with that namespace.
 	//
 	// h is invoked on its own goroutine for each command request.
-	Listen(ns string, h CommandHandler) error
+	//
+	// opts are used to configure how requests in this namespace are accepted,
+	// such as options.Workers() which limits the number of requests in ns that
+	// are handled concurrently.
+	Listen(ns string, h CommandHandler, opts ...options.ListenOption) error
 
 	// Unlisten stops listening for command requests in the given namepsace.
 	//
 	// If the peer is not currently listening to ns, nil is returned immediately.
 	Unlisten(ns string) error
 
+	// Publish sends a message to all peers that are subscribed to topic,
+	// including this peer.
+	//
+	// Unlike notifications, publications are not sent by or delivered to
+	// sessions. They are intended for service-level events that are of
+	// interest to peers, rather than to the clients they represent.
+	//
+	// Delivery is "at-most-once", a subscriber that is not connected to the
+	// network when the message is published never receives it. Use
+	// PublishConfirmed() to find out how many peers handled the message.
+	//
+	// topic must be a valid namespace name.
+	Publish(ctx context.Context, topic string, out *Payload) error
+
+	// PublishConfirmed sends a message to all peers that are subscribed to
+	// topic, and returns the number of peers that handled it before a
+	// deadline.
+	//
+	// As the number of subscribers is not known in advance, it always waits
+	// until the deadline. If ctx does not have a deadline, a timeout described
+	// by options.DefaultTimeout() is used. Reaching the deadline is not
+	// considered an error.
+	PublishConfirmed(ctx context.Context, topic string, out *Payload) (n int, err error)
+
+	// Subscribe starts receiving messages published to topic.
+	//
+	// When a message is published to topic, the handler h is invoked.
+	//
+	// Repeated calls to Subscribe() with the same topic simply changes the
+	// handler associated with that topic.
+	//
+	// h is invoked on its own goroutine for each message.
+	Subscribe(topic string, h PublicationHandler) error
+
+	// Unsubscribe stops receiving messages published to topic.
+	//
+	// If the peer is not currently subscribed to topic, nil is returned
+	// immediately.
+	Unsubscribe(topic string) error
+
+	// LongRunningHandlers returns the command, notification and publication
+	// handlers that have been running for longer than the threshold given by
+	// options.Watchdog().
+	//
+	// If the watchdog is not enabled, nil is returned.
+	LongRunningHandlers() []LongRunningHandler
+
 	// Done returns a channel that is closed when the peer is stopped.
 	//
 	// Err() may be called to obtain the error that caused the peer to stop, if
@@ -0,0 +1,40 @@
+package rinq
+
+import (
+	"context"
+
+	"github.com/rinq/rinq-go/src/rinq/ident"
+)
+
+// Publication holds information about a message published to a topic.
+type Publication struct {
+	// ID uniquely identifies the publication.
+	ID ident.MessageID
+
+	// Source is the ID of the peer that published the message.
+	Source ident.PeerID
+
+	// Topic is the name of the topic that the message was published to.
+	// Topics are used to route publications to only those peers that are
+	// subscribed to them.
+	Topic string
+
+	// Payload contains optional application-defined information. The handler
+	// that accepts the publication is responsible for closing the payload,
+	// however there is no requirement that the payload be closed during the
+	// execution of the handler.
+	Payload *Payload
+}
+
+// PublicationHandler is a callback-function invoked when a message is
+// published to a topic.
+//
+// Publications can only be received for topics that a peer is subscribed to.
+// See Peer.Subscribe() to start receiving publications.
+//
+// The handler is responsible for closing pub.Payload, however there is no
+// requirement that the payload be closed during the execution of the handler.
+type PublicationHandler func(
+	ctx context.Context,
+	pub Publication,
+)
@@ -68,6 +68,42 @@ type Revision interface {
 	// If err is nil, t contains all of the attributes specified in k.
 	GetMany(ctx context.Context, ns string, k ...string) (t AttrTable, err error)
 
+	// Namespaces returns the names of the namespaces that contain at least one
+	// attribute at this revision, in order. Namespaces that only contain
+	// attributes created after this revision are excluded.
+	//
+	// If IsNotFound(err) returns true, the session has been destroyed and the
+	// revision can not be queried.
+	Namespaces(ctx context.Context) (ns []string, err error)
+
+	// GetAll returns all of the attributes within the ns namespace, as they
+	// were at this revision.
+	//
+	// Attributes that have been created since this revision are excluded. If
+	// any of the attributes can not be retrieved because they have already
+	// been modified, ShouldRetry(err) returns true. To fetch the attribute
+	// values at the later revision, first call Refresh() then retry the
+	// GetAll() on the newer revision.
+	//
+	// If IsNotFound(err) returns true, the session has been destroyed and the
+	// revision can not be queried.
+	GetAll(ctx context.Context, ns string) (t AttrTable, err error)
+
+	// DiffSince returns the attributes within the ns namespace that were
+	// modified after the revision from, as they were at this revision. from
+	// must be a revision of the same session, otherwise DiffSince() panics.
+	//
+	// If from is not older than this revision, t is empty.
+	//
+	// If any of the attributes can not be retrieved because they have been
+	// modified since this revision, ShouldRetry(err) returns true. To fetch
+	// the attribute values at the later revision, first call Refresh() then
+	// retry the DiffSince() on the newer revision.
+	//
+	// If IsNotFound(err) returns true, the session has been destroyed and the
+	// revision can not be queried.
+	DiffSince(ctx context.Context, from Revision, ns string) (t AttrTable, err error)
+
 	// Update atomically modifies a set of attributes within the ns namespace of
 	// the attribute table.
 	//
@@ -94,6 +130,46 @@ type Revision interface {
 	// existing variable without first checking for errors.
 	Update(ctx context.Context, ns string, attrs ...Attr) (rev Revision, err error)
 
+	// UpdateMany atomically modifies sets of attributes within multiple
+	// namespaces of the attribute table. attrs is a map of namespace to the
+	// attributes to update within that namespace.
+	//
+	// The sematics are the same as for Update(), except that all of the
+	// changes are made in a single revision. Either all of the attributes in
+	// every namespace are updated, or the attribute table remains unchanged.
+	//
+	// As a convenience, if the update fails for any reason, rev is this
+	// revision. This allows the caller to assign the return value to an
+	// existing variable without first checking for errors.
+	UpdateMany(ctx context.Context, attrs map[string][]Attr) (rev Revision, err error)
+
+	// UpdateIf atomically modifies a set of attributes within the ns namespace
+	// of the attribute table, provided that every condition in cond holds for
+	// the current state of the attributes in ns.
+	//
+	// Unlike Update(), the session revision represented by this instance need
+	// not be the latest revision. Changes made to the session since this
+	// revision do not cause the update to fail unless they affect the
+	// attributes referenced by cond. This allows writers that modify unrelated
+	// attributes to proceed without retrying.
+	//
+	// The following conditions must be met for an update to succeed:
+	//
+	// 1. Every condition in cond must hold. If any condition does not hold the
+	//    update fails; ShouldRetry(err) returns true.
+	//
+	// 2. All attribute changes must reference non-frozen attributes. If any of
+	//    attributes being updated are already frozen the update fails and
+	//    ShouldRetry(err) returns false.
+	//
+	// On success, rev is the newly created revision, which is the latest
+	// revision of the session.
+	//
+	// As a convenience, if the update fails for any reason, rev is this
+	// revision. This allows the caller to assign the return value to an
+	// existing variable without first checking for errors.
+	UpdateIf(ctx context.Context, ns string, cond []AttrCondition, attrs ...Attr) (rev Revision, err error)
+
 	// Clear is an update operation that atomically sets the value of each
 	// attribute within the ns namespace to the empty string.
 	//
@@ -105,6 +181,28 @@ type Revision interface {
 	// existing variable without first checking for errors.
 	Clear(ctx context.Context, ns string) (rev Revision, err error)
 
+	// Watch returns a channel that receives changes to the attributes within
+	// the ns namespace, made after this revision.
+	//
+	// If k is non-empty, only changes to attributes with keys in k are
+	// reported. Otherwise, changes to any attribute within ns are reported.
+	//
+	// If this is not the latest revision, the first change received describes
+	// all watched attributes that have been modified since this revision.
+	//
+	// When the session is destroyed, a final change with IsDestroyed set to
+	// true is received. The channel is closed after the final change has been
+	// received, or when ctx is canceled.
+	//
+	// The channel is also closed, without a final change, if changes can no
+	// longer be delivered. This occurs if the receiver falls too far behind,
+	// or if the peer that owns the session stops or can not be reached. The
+	// watch may be re-established by calling Refresh() then Watch().
+	//
+	// If IsNotFound(err) returns true, the session has already been destroyed
+	// and can not be watched.
+	Watch(ctx context.Context, ns string, k ...string) (changes <-chan AttrChange, err error)
+
 	// Destroy terminates the session.
 	//
 	// The session revision represented by this instance must be the latest
@@ -113,8 +211,27 @@ type Revision interface {
 	Destroy(ctx context.Context) (err error)
 }
 
-// ShouldRetry returns true if a call to Revision.Get(), GetMany(), Update() or
-// Destroy() failed because the revision is out of date.
+// AttrChange is a change to the attributes of a session, as received from the
+// channel returned by Revision.Watch().
+type AttrChange struct {
+	// Revision is the session revision produced by the change.
+	Revision Revision
+
+	// Namespace is the namespace of the changed attributes.
+	Namespace string
+
+	// Attrs contains the new values of the changed attributes. It is empty if
+	// IsDestroyed is true.
+	Attrs AttrTable
+
+	// IsDestroyed is true if the session has been destroyed. It is always the
+	// last change received.
+	IsDestroyed bool
+}
+
+// ShouldRetry returns true if a call to Revision.Get(), GetMany(), GetAll(),
+// DiffSince(), Update(), UpdateIf() or Destroy() failed because the revision
+// is out of date.
 //
 // The operation should be retried on the latest revision of the session,
 // which can be retrieved with Revision.Refresh().
@@ -165,3 +282,22 @@ func (err FrozenAttributesError) Error() string {
 		err.Ref,
 	)
 }
+
+// QuotaExceededError indicates a failure to update a session because the
+// change would exceed one of the limits on the session's attributes.
+//
+// See options.SessionQuota().
+type QuotaExceededError struct {
+	Ref ident.Ref
+
+	// Limit describes the limit that would be exceeded, such as "value size".
+	Limit string
+}
+
+func (err QuotaExceededError) Error() string {
+	return fmt.Sprintf(
+		"can not update %s, the change exceeds the %s quota",
+		err.Ref,
+		err.Limit,
+	)
+}
@@ -89,3 +89,39 @@ func ExampleRevision_update() {
 
 	// Output: updated to new revision
 }
+
+// This example illustrates how to use Mutate() to modify an attribute based on
+// its current value, without writing the retry logic by hand.
+func ExampleMutate() {
+	peer, err := rinqamqp.DialEnv()
+	if err != nil {
+		panic(err)
+	}
+	defer peer.Stop()
+
+	sess := peer.Session()
+	defer sess.Destroy()
+
+	ctx := context.Background()
+
+	_, err = Mutate(
+		ctx,
+		sess.CurrentRevision(),
+		"my-api",
+		func(rev Revision) ([]Attr, error) {
+			attr, err := rev.Get(ctx, "my-api", "visits")
+			if err != nil {
+				return nil, err
+			}
+
+			return []Attr{Set("visits", attr.Value+"+")}, nil
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("updated to new revision")
+
+	// Output: updated to new revision
+}
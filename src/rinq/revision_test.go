@@ -68,4 +68,15 @@ var _ = Describe("Revision", func() {
 			})
 		})
 	})
+
+	Describe("QuotaExceededError", func() {
+		Describe("Error", func() {
+			It("returns the message", func() {
+				err := rinq.QuotaExceededError{Ref: sessionRef, Limit: "value size"}
+				Expect(err.Error()).To(Equal(
+					"can not update 1-0002.3@4, the change exceeds the value size quota",
+				))
+			})
+		})
+	})
 })
@@ -3,6 +3,7 @@ package rinq
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/rinq/rinq-go/src/rinq/constraint"
 	"github.com/rinq/rinq-go/src/rinq/ident"
@@ -137,15 +138,112 @@ type Session interface {
 	// notification can not be sent.
 	NotifyMany(ctx context.Context, ns, t string, c constraint.Constraint, out *Payload) error
 
+	// NotifyManyRetained sends a message to multiple sessions that are
+	// listening to the ns namespace, and retains it for sessions that start
+	// listening later.
+	//
+	// The semantics are the same as NotifyMany(), except that this session's
+	// owning peer keeps the notification until ttl elapses. It is replayed to
+	// each session that matches c and starts listening to ns (or a pattern
+	// that matches ns) in the meantime, including sessions that call Listen()
+	// or ListenTypes() again to accept additional types, in which case only
+	// notifications of the additional types are replayed.
+	//
+	// Notifications are only retained and replayed if options.Retention() is
+	// enabled on both the sending peer and the listening peer, otherwise the
+	// semantics are exactly the same as NotifyMany().
+	//
+	// Only the latest notification is retained for each combination of
+	// namespace, type and constraint, making retained notifications suited to
+	// notifications that describe the current state of something, rather than
+	// an event. Each peer retains a limited number of notifications, beyond
+	// which those closest to expiry are discarded early.
+	//
+	// It panics if ttl is not positive.
+	//
+	// If IsNotFound(err) returns true, this session has been destroyed and the
+	// notification can not be sent.
+	NotifyManyRetained(ctx context.Context, ns, t string, c constraint.Constraint, ttl time.Duration, out *Payload) error
+
+	// NotifyEach sends a message directly to each of the sessions in targets
+	// that are listening to the ns namespace.
+	//
+	// The semantics are the same as calling Notify() for each target, except
+	// that a single message is sent to each peer that owns one or more of the
+	// targets, rather than one message per session.
+	//
+	// If IsNotFound(err) returns true, this session has been destroyed and the
+	// notification can not be sent.
+	NotifyEach(ctx context.Context, ns, t string, targets []ident.SessionID, out *Payload) error
+
+	// NotifyConfirmed sends a message directly to another session listening to
+	// the ns namespace, and waits for confirmation that it has been handled.
+	//
+	// The semantics are the same as Notify(), except that handled is true once
+	// the notification handler of the target session has returned. If the
+	// target session exists but is not listening for notifications of this
+	// namespace and type, handled is false and err is nil.
+	//
+	// The wait always uses a deadline; if ctx does not have a deadline, a
+	// timeout described by options.DefaultTimeout() is used. If no confirmation
+	// is received before the deadline, such as when the target session's peer
+	// has stopped, err is the context error.
+	//
+	// If IsNotFound(err) returns true, either this session or the target
+	// session has been destroyed.
+	NotifyConfirmed(ctx context.Context, ns, t string, s ident.SessionID, out *Payload) (handled bool, err error)
+
+	// NotifyManyConfirmed sends a message to multiple sessions that are
+	// listening to the ns namespace, and returns the number of sessions that
+	// handled the notification before a deadline.
+	//
+	// The semantics are the same as NotifyMany(), except that the peers that
+	// receive the notification report the number of sessions with handlers
+	// that returned. As the number of recipients is not known in advance, it
+	// always waits until the deadline. If ctx does not have a deadline, a
+	// timeout described by options.DefaultTimeout() is used. Reaching the
+	// deadline is not considered an error.
+	//
+	// If IsNotFound(err) returns true, this session has been destroyed and the
+	// notification can not be sent.
+	NotifyManyConfirmed(ctx context.Context, ns, t string, c constraint.Constraint, out *Payload) (n int, err error)
+
 	// Listen begins listening for notifications sent to this session in the ns
 	// namespace.
 	//
 	// When a notification is received with a namespace equal to ns, h is invoked.
 	//
-	// h is invoked on its own goroutine for each notification.
+	// ns may also be a pattern in which some of the period-separated segments
+	// are wildcards. The "*" wildcard matches exactly one segment, and the "#"
+	// wildcard matches zero or more segments. For example, h is invoked for
+	// notifications in the "chat.lobby" namespace if ns is "chat.*". If more
+	// than one pattern matches a notification, only the handler for the most
+	// specific pattern is invoked, see ListenTypes().
+	//
+	// h is invoked on its own goroutine for each notification. The session's
+	// notifications are handled one at a time, in the order they are received.
+	//
+	// Calling Listen() or ListenTypes() again with the same ns replaces the
+	// existing handler.
 	Listen(ns string, h NotificationHandler) error
 
-	// Unlisten stops listening for notifications from the ns namespace.
+	// ListenTypes begins listening for notifications sent to this session in
+	// the ns namespace, with a type that is one of the given types.
+	//
+	// Notifications of other types are not delivered to the peer, rather than
+	// being discarded after they are received. ns may be a pattern, as per
+	// Listen().
+	//
+	// If more than one call to Listen() or ListenTypes() matches a
+	// notification, only one handler is invoked. A handler for a namespace is
+	// preferred over a handler for a pattern, and a handler for a longer
+	// pattern is preferred over a shorter one.
+	//
+	// It panics if types is empty.
+	ListenTypes(ns string, types []string, h NotificationHandler) error
+
+	// Unlisten stops listening for notifications from the ns namespace, or
+	// namespace pattern.
 	//
 	// If the session is not currently listening for notifications, nil is
 	// returned immediately.
@@ -0,0 +1,43 @@
+package rinq
+
+import (
+	"time"
+
+	"github.com/rinq/rinq-go/src/rinq/ident"
+)
+
+// LongRunningHandler describes a command, notification or publication handler
+// that has been running for longer than the threshold given by
+// options.Watchdog().
+type LongRunningHandler struct {
+	// ID is the ID of the command request, notification or publication being
+	// handled.
+	ID ident.MessageID
+
+	// Namespace is the namespace of the command request or notification. It is
+	// empty if the handler is a publication handler.
+	Namespace string
+
+	// Command is the command name. It is empty if the handler is a
+	// notification or publication handler.
+	Command string
+
+	// Type is the notification type. It is empty if the handler is a command
+	// or publication handler.
+	Type string
+
+	// Session is the ID of the session that is receiving the notification. It
+	// is the zero-value if the handler is a command or publication handler.
+	Session ident.SessionID
+
+	// Topic is the topic of the publication. It is empty if the handler is a
+	// command or notification handler.
+	Topic string
+
+	// TraceID is the trace ID of the command request, notification or
+	// publication.
+	TraceID string
+
+	// StartedAt is the time at which the handler was invoked.
+	StartedAt time.Time
+}
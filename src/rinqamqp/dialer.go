@@ -13,6 +13,7 @@ import (
 	"github.com/rinq/rinq-go/src/internal/localsession"
 	"github.com/rinq/rinq-go/src/internal/remotesession"
 	"github.com/rinq/rinq-go/src/internal/revisions"
+	"github.com/rinq/rinq-go/src/internal/watchdog"
 	"github.com/rinq/rinq-go/src/internal/x/env"
 	"github.com/rinq/rinq-go/src/rinq"
 	"github.com/rinq/rinq-go/src/rinq/ident"
@@ -179,6 +180,19 @@ func (d *Dialer) Dial(
 		peerID,
 	)
 
+	var wd *watchdog.Watchdog
+	if opts.WatchdogThreshold != 0 {
+		wd = watchdog.New(peerID, opts.WatchdogThreshold, opts.Logger)
+
+		defer func() {
+			// if an error has occurred when the function exits, stop the
+			// watchdog, otherwise it is given to the peer
+			if err != nil {
+				wd.Stop()
+			}
+		}()
+	}
+
 	localStore := localsession.NewStore()
 	revStore := revisions.NewAggregateStore(
 		peerID,
@@ -186,12 +200,12 @@ func (d *Dialer) Dial(
 		nil, // Remote revision store depends on invoker, created below
 	)
 
-	invoker, server, err := commandamqp.New(peerID, opts, localStore, revStore, channels)
+	invoker, server, err := commandamqp.New(peerID, opts, localStore, revStore, channels, wd)
 	if err != nil {
 		return nil, err
 	}
 
-	notifier, listener, err := notifyamqp.New(peerID, opts, localStore, revStore, channels)
+	notifier, listener, err := notifyamqp.New(peerID, opts, localStore, revStore, channels, wd)
 	if err != nil {
 		return nil, err
 	}
@@ -199,7 +213,7 @@ func (d *Dialer) Dial(
 	remoteStore := remotesession.NewStore(peerID, invoker, opts.PruneInterval, opts.Logger, opts.Tracer)
 	revStore.Remote = remoteStore
 
-	if err := remotesession.Listen(server, peerID, localStore, opts.Logger); err != nil {
+	if err := remotesession.Listen(server, peerID, localStore, remoteStore, opts.Logger); err != nil {
 		return nil, err
 	}
 
@@ -212,8 +226,10 @@ func (d *Dialer) Dial(
 		server,
 		notifier,
 		listener,
+		wd,
 		opts.Logger,
 		opts.Tracer,
+		opts.Quota,
 	), nil
 }
 
@@ -18,6 +18,10 @@ type ChannelPool interface {
 	// the channel.
 	GetQOS(preFetch uint) (*amqp.Channel, error)
 
+	// SetQOS changes the pre-fetch count of a channel that was obtained from
+	// the pool. The pre-fetch is applied across all consumers on the channel.
+	SetQOS(channel *amqp.Channel, preFetch uint) error
+
 	// Put returns a channel to the pool.
 	Put(*amqp.Channel)
 }
@@ -54,21 +58,26 @@ func (p *channelPool) GetQOS(preFetch uint) (*amqp.Channel, error) {
 		return nil, err
 	}
 
+	if err := p.SetQOS(channel, preFetch); err != nil {
+		return nil, err
+	}
+
+	return channel, nil
+}
+
+// SetQOS changes the pre-fetch count of a channel that was obtained from
+// the pool. The pre-fetch is applied across all consumers on the channel.
+func (p *channelPool) SetQOS(channel *amqp.Channel, preFetch uint) error {
 	// Always use a "channel-wide" QoS setting.
 	// http://www.rabbitmq.com/consumer-prefetch.html
 	caps, _ := p.broker.Properties["capabilities"].(amqp.Table)
 	global, _ := caps["per_consumer_qos"].(bool)
 
 	if preFetch > maxPreFetch {
-		return nil, errors.New("pre-fetch is too large")
-	}
-
-	err = channel.Qos(int(preFetch), 0, global)
-	if err != nil {
-		return nil, err
+		return errors.New("pre-fetch is too large")
 	}
 
-	return channel, nil
+	return channel.Qos(int(preFetch), 0, global)
 }
 
 func (p *channelPool) Put(channel *amqp.Channel) {
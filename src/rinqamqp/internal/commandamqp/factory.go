@@ -4,6 +4,7 @@ import (
 	"github.com/rinq/rinq-go/src/internal/command"
 	"github.com/rinq/rinq-go/src/internal/localsession"
 	"github.com/rinq/rinq-go/src/internal/revisions"
+	"github.com/rinq/rinq-go/src/internal/watchdog"
 	"github.com/rinq/rinq-go/src/rinq/ident"
 	"github.com/rinq/rinq-go/src/rinq/options"
 	"github.com/rinq/rinq-go/src/rinqamqp/internal/amqputil"
@@ -16,6 +17,7 @@ func New(
 	sessions *localsession.Store,
 	revs revisions.Store,
 	channels amqputil.ChannelPool,
+	wd *watchdog.Watchdog,
 ) (command.Invoker, command.Server, error) {
 	channel, err := channels.Get()
 	if err != nil {
@@ -46,6 +48,10 @@ func New(
 	server, err := newServer(
 		peerID,
 		opts.CommandWorkers,
+		opts.BackpressureLatency,
+		opts.BackpressureHeap,
+		opts.FailFast,
+		wd,
 		revs,
 		queues,
 		channels,
@@ -2,16 +2,22 @@ package commandamqp
 
 import (
 	"context"
+	"runtime/debug"
 	"sync"
+	"time"
 
 	"github.com/jmalloc/twelf/src/twelf"
 	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
 	"github.com/rinq/rinq-go/src/internal/command"
+	"github.com/rinq/rinq-go/src/internal/opentr"
 	"github.com/rinq/rinq-go/src/internal/revisions"
 	"github.com/rinq/rinq-go/src/internal/service"
+	"github.com/rinq/rinq-go/src/internal/watchdog"
 	"github.com/rinq/rinq-go/src/rinq"
 	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/options"
+	"github.com/rinq/rinq-go/src/rinq/trace"
 	"github.com/rinq/rinq-go/src/rinqamqp/internal/amqputil"
 	"github.com/streadway/amqp"
 )
@@ -28,6 +34,10 @@ type server struct {
 	logger    twelf.Logger
 	tracer    opentracing.Tracer
 
+	backpressure *command.Backpressure // adaptive pre-fetch limit, nil if disabled
+	failFast     bool                  // do not recover panics in command handlers
+	watchdog     *watchdog.Watchdog
+
 	parentCtx context.Context // parent of all contexts passed to handlers
 	cancelCtx func()          // cancels parentCtx when the server stops
 
@@ -37,14 +47,21 @@ type server struct {
 	amqpClosed chan *amqp.Error
 	pending    uint // number of requests currently being handled
 
-	mutex    sync.RWMutex                   // guards handlers so handler can be read in dispatch() goroutine
-	handlers map[string]rinq.CommandHandler // map of namespace to handler
+	mutex    sync.RWMutex                    // guards handlers so handler can be read in dispatch() goroutine
+	handlers map[string]rinq.CommandHandler  // map of namespace to handler
+	workers  map[string]uint                 // map of namespace to worker limit, zero uses the shared channel
+	isolated map[string]*amqp.Channel        // map of namespace to dedicated channel, see consumerChannel()
+	limiters map[string]*command.RateLimiter // map of namespace to rate limiter, see rateLimit()
 }
 
 // newServer creates, starts and returns a new server.
 func newServer(
 	peerID ident.PeerID,
 	preFetch uint,
+	latency time.Duration,
+	heapLimit uint64,
+	failFast bool,
+	wd *watchdog.Watchdog,
 	revs revisions.Store,
 	queues *queueSet,
 	channels amqputil.ChannelPool,
@@ -60,10 +77,20 @@ func newServer(
 		logger:    logger,
 		tracer:    tracer,
 
+		failFast: failFast,
+		watchdog: wd,
+
 		deliveries: make(chan amqp.Delivery, preFetch),
 		amqpClosed: make(chan *amqp.Error, 1),
 
 		handlers: map[string]rinq.CommandHandler{},
+		workers:  map[string]uint{},
+		isolated: map[string]*amqp.Channel{},
+		limiters: map[string]*command.RateLimiter{},
+	}
+
+	if latency != 0 {
+		s.backpressure = command.NewBackpressure(preFetch, latency, heapLimit, command.HeapUsage)
 	}
 
 	s.sm = service.NewStateMachine(s.run, s.finalize)
@@ -78,17 +105,24 @@ func newServer(
 	return s, nil
 }
 
-func (s *server) Listen(ns string, h rinq.CommandHandler) (added bool, err error) {
+func (s *server) Listen(
+	ns string,
+	h rinq.CommandHandler,
+	opts options.ListenOptions,
+) (added bool, err error) {
 	err = s.sm.Do(func() error {
 		s.mutex.Lock()
 		defer s.mutex.Unlock()
 
+		h = s.rateLimit(ns, h, opts.RateLimits)
+
 		if _, ok := s.handlers[ns]; ok {
 			s.handlers[ns] = h
-			return nil
+			return s.setWorkers(ns, opts.Workers)
 		}
 
 		s.handlers[ns] = h
+		s.workers[ns] = opts.Workers
 		added = true
 
 		return s.bind(ns)
@@ -109,12 +143,42 @@ func (s *server) Unlisten(ns string) (removed bool, err error) {
 		removed = true
 		delete(s.handlers, ns)
 
-		return s.unbind(ns)
+		err := s.unbind(ns)
+		delete(s.workers, ns)
+		delete(s.limiters, ns)
+
+		return err
 	})
 
 	return
 }
 
+// rateLimit returns a handler that applies the rate limits described by
+// policies to h.
+//
+// The limiter for each namespace is retained across calls to Listen(), so
+// that changing the handler does not reset the namespace's limits.
+func (s *server) rateLimit(
+	ns string,
+	h rinq.CommandHandler,
+	policies []options.RateLimitPolicy,
+) rinq.CommandHandler {
+	if len(policies) == 0 {
+		delete(s.limiters, ns)
+		return h
+	}
+
+	l, ok := s.limiters[ns]
+	if !ok {
+		l = command.NewRateLimiter(s.peerID, s.logger)
+		s.limiters[ns] = l
+	}
+
+	l.SetPolicies(policies)
+
+	return command.RateLimit(h, l)
+}
+
 func (s *server) bind(ns string) error {
 	if err := s.channel.QueueBind(
 		requestQueue(s.peerID),
@@ -126,12 +190,39 @@ func (s *server) bind(ns string) error {
 		return err
 	}
 
+	return s.consume(ns)
+}
+
+func (s *server) unbind(ns string) error {
+	if err := s.channel.QueueUnbind(
+		requestQueue(s.peerID),
+		ns,
+		multicastExchange,
+		nil, //  args
+	); err != nil {
+		return err
+	}
+
+	return s.cancel(ns)
+}
+
+// consume starts consuming balanced command requests in the ns namespace.
+//
+// If the namespace has a worker limit, the requests are consumed on a channel
+// dedicated to that namespace, otherwise they are consumed on the shared
+// channel along with unicast and multicast requests.
+func (s *server) consume(ns string) error {
 	queue, err := s.queues.Get(s.channel, ns)
 	if err != nil {
 		return err
 	}
 
-	messages, err := s.channel.Consume(
+	channel, err := s.consumerChannel(ns)
+	if err != nil {
+		return err
+	}
+
+	messages, err := channel.Consume(
 		queue,
 		queue, // use queue name as consumer tag
 		false, // autoAck
@@ -144,27 +235,110 @@ func (s *server) bind(ns string) error {
 		return err
 	}
 
-	go s.pipe(messages)
+	if s.workers[ns] == 0 {
+		go s.pipe(messages)
+	} else {
+		go s.pipeIsolated(messages)
+	}
+
+	logConsuming(s.logger, s.peerID, ns, s.workers[ns])
 
 	return nil
 }
 
-func (s *server) unbind(ns string) error {
-	if err := s.channel.QueueUnbind(
-		requestQueue(s.peerID),
-		ns,
-		multicastExchange,
-		nil, //  args
-	); err != nil {
-		return err
+// cancel stops consuming balanced command requests in the ns namespace.
+func (s *server) cancel(ns string) error {
+	channel := s.channel
+	if s.workers[ns] != 0 {
+		channel = s.isolated[ns]
 	}
 
-	return s.channel.Cancel(
+	return channel.Cancel(
 		balancedRequestQueue(ns), // use queue name as consumer tag
-		false, // noWait
+		false,                    // noWait
 	)
 }
 
+// setWorkers changes the worker limit of the ns namespace, which must already
+// be bound.
+func (s *server) setWorkers(ns string, n uint) error {
+	prev := s.workers[ns]
+
+	if n == prev {
+		return nil
+	}
+
+	// The limit is changing, but the namespace was already using a dedicated
+	// channel, so the change can be made by adjusting the pre-fetch.
+	if n != 0 && prev != 0 {
+		s.workers[ns] = n
+
+		if err := s.channels.SetQOS(s.isolated[ns], n); err != nil {
+			return err
+		}
+
+		logConsuming(s.logger, s.peerID, ns, n)
+
+		return nil
+	}
+
+	// Otherwise, the namespace is moving to or from the shared channel, stop
+	// consuming and start again on the appropriate channel. Any requests that
+	// are already being handled are acknowledged on the channel that delivered
+	// them.
+	if err := s.cancel(ns); err != nil {
+		return err
+	}
+
+	s.workers[ns] = n
+
+	return s.consume(ns)
+}
+
+// consumerChannel returns the channel on which balanced command requests in the ns
+// namespace are consumed.
+//
+// Namespaces with a worker limit are given their own channel, with a pre-fetch
+// equal to that limit. The broker therefore enforces the limit for each
+// namespace independently, and the requests are dispatched by pipeIsolated()
+// without passing through s.deliveries, so a saturated namespace can not
+// delay requests in any other namespace.
+//
+// Dedicated channels are retained until the server stops, even if the
+// namespace is no longer listened to, as there may be requests that have
+// been delivered on the channel but not yet acknowledged.
+func (s *server) consumerChannel(ns string) (*amqp.Channel, error) {
+	n := s.workers[ns]
+	if n == 0 {
+		return s.channel, nil
+	}
+
+	if channel, ok := s.isolated[ns]; ok {
+		return channel, s.channels.SetQOS(channel, n)
+	}
+
+	channel, err := s.channels.GetQOS(n) // do not return to pool, used for consume
+	if err != nil {
+		return nil, err
+	}
+
+	s.isolated[ns] = channel
+
+	// Forward any closure of the dedicated channel to the state-machine
+	// without blocking, as amqpClosed may already be full.
+	closed := channel.NotifyClose(make(chan *amqp.Error, 1))
+	go func() {
+		if err, ok := <-closed; ok {
+			select {
+			case s.amqpClosed <- err:
+			default:
+			}
+		}
+	}()
+
+	return channel, nil
+}
+
 // initialize prepares the AMQP channel
 func (s *server) initialize() error {
 	if channel, err := s.channels.GetQOS(s.preFetch); err == nil { // do not return to pool, used for consume
@@ -222,6 +396,13 @@ func (s *server) run() (service.State, error) {
 
 	s.parentCtx, s.cancelCtx = context.WithCancel(context.Background())
 
+	var adjust <-chan time.Time
+	if s.backpressure != nil {
+		ticker := time.NewTicker(adjustInterval)
+		defer ticker.Stop()
+		adjust = ticker.C
+	}
+
 	for {
 		select {
 		case msg := <-s.deliveries:
@@ -231,6 +412,11 @@ func (s *server) run() (service.State, error) {
 		case req := <-s.sm.Commands:
 			s.sm.Execute(req)
 
+		case <-adjust:
+			if err := s.adjustPreFetch(); err != nil {
+				return nil, err
+			}
+
 		case <-s.sm.Graceful:
 			return s.gracefulStopConsuming, nil
 
@@ -243,6 +429,30 @@ func (s *server) run() (service.State, error) {
 	}
 }
 
+// adjustInterval is how often the pre-fetch of the shared channel is adjusted
+// when backpressure is enabled.
+const adjustInterval = time.Second
+
+// adjustPreFetch applies the current backpressure limit to the shared channel.
+//
+// Lowering the pre-fetch does not affect requests that have already been
+// delivered, they are handled as usual.
+func (s *server) adjustPreFetch() error {
+	prev, n, err := s.backpressure.Adjust(
+		time.Now(),
+		func(n uint) error {
+			return s.channels.SetQOS(s.channel, n)
+		},
+	)
+
+	if err == nil && n != prev {
+		logPreFetchAdjusted(s.logger, s.peerID, prev, n)
+		s.preFetch = n
+	}
+
+	return err
+}
+
 // gracefulStopConsuming is the first state entered when a graceful stop is
 // requested.
 func (s *server) gracefulStopConsuming() (service.State, error) {
@@ -309,6 +519,10 @@ func (s *server) finalize(err error) error {
 
 	closeErr := s.channel.Close()
 
+	for _, channel := range s.isolated {
+		_ = channel.Close()
+	}
+
 	// only report the closeErr if there's no causal error.
 	if err == nil {
 		return closeErr
@@ -351,6 +565,7 @@ func (s *server) dispatch(msg *amqp.Delivery) {
 	// find the handler for this namespace
 	s.mutex.RLock()
 	h, ok := s.handlers[ns]
+	isolated := s.workers[ns] != 0 && msg.Exchange == balancedExchange
 	s.mutex.RUnlock()
 	if !ok {
 		_ = msg.Reject(msg.Exchange == balancedExchange) // requeue if "balanced"
@@ -366,7 +581,13 @@ func (s *server) dispatch(msg *amqp.Delivery) {
 		return
 	}
 
-	s.handle(msgID, msg, ns, cmd, source, h, spanOpts)
+	// only requests delivered on the shared channel contribute to backpressure
+	limit := s.backpressure
+	if isolated {
+		limit = nil
+	}
+
+	s.handle(msgID, msg, ns, cmd, source, h, spanOpts, limit)
 }
 
 // handle invokes the command handler for request.
@@ -378,6 +599,7 @@ func (s *server) handle(
 	source rinq.Revision,
 	handler rinq.CommandHandler,
 	spanOpts []opentracing.StartSpanOption,
+	limit *command.Backpressure,
 ) {
 	ctx := amqputil.UnpackTrace(s.parentCtx, msg)
 	ctx, cancel := amqputil.UnpackDeadline(ctx, msg)
@@ -408,9 +630,26 @@ func (s *server) handle(
 		logRequestBegin(ctx, s.logger, s.peerID, msgID, req)
 	}
 
-	handler(ctx, req, res)
+	done := s.watchdog.Track(rinq.LongRunningHandler{
+		ID:        msgID,
+		Namespace: ns,
+		Command:   cmd,
+		TraceID:   trace.Get(ctx),
+	})
+
+	start := time.Now()
+	panicked := s.invoke(ctx, span, msgID, handler, req, res)
+	done()
 
-	if finalize() {
+	if limit != nil {
+		end := time.Now()
+		limit.Observe(end.Sub(start), end)
+	}
+
+	if panicked {
+		finalize()
+		_ = msg.Reject(false) // false = don't requeue
+	} else if finalize() {
 		_ = msg.Ack(false) // false = single message
 
 		if dr, ok := res.(*debugResponse); ok {
@@ -432,7 +671,44 @@ func (s *server) handle(
 	}
 }
 
-// pipe aggregates AMQP messages from multiple consumers to a single channel.
+// invoke calls handler, recovering from any panic unless the server is
+// configured to fail fast.
+//
+// If the handler panics before responding, the request is answered with a
+// rinq.CommandError so the caller is not left waiting for a timeout.
+func (s *server) invoke(
+	ctx context.Context,
+	span opentracing.Span,
+	msgID ident.MessageID,
+	handler rinq.CommandHandler,
+	req rinq.Request,
+	res rinq.Response,
+) (panicked bool) {
+	if !s.failFast {
+		defer func() {
+			if v := recover(); v != nil {
+				panicked = true
+				stack := debug.Stack()
+
+				opentr.LogPanic(span, v, stack)
+				logHandlerPanic(ctx, s.logger, s.peerID, msgID, req, v, stack)
+
+				req.Payload.Close()
+
+				if !res.IsClosed() {
+					res.Error(rinq.CommandError("command handler panicked"))
+				}
+			}
+		}()
+	}
+
+	handler(ctx, req, res)
+
+	return
+}
+
+// pipe aggregates AMQP messages from the consumers on the shared channel to a
+// single channel, which is limited by the shared channel's pre-fetch.
 func (s *server) pipe(messages <-chan amqp.Delivery) {
 	for msg := range messages {
 		select {
@@ -441,3 +717,26 @@ func (s *server) pipe(messages <-chan amqp.Delivery) {
 		}
 	}
 }
+
+// pipeIsolated dispatches AMQP messages from a consumer on a namespace's
+// dedicated channel.
+//
+// The number of unacknowledged requests is already limited by the dedicated
+// channel's pre-fetch, so each request is dispatched as soon as it arrives,
+// rather than competing with other namespaces for space in s.deliveries.
+// Requests that arrive once the server is stopping are requeued.
+func (s *server) pipeIsolated(messages <-chan amqp.Delivery) {
+	for msg := range messages {
+		msg := msg
+
+		if err := s.sm.Do(func() error {
+			s.pending++
+			return nil
+		}); err != nil {
+			_ = msg.Reject(true) // true = requeue
+			continue
+		}
+
+		go s.dispatch(&msg)
+	}
+}
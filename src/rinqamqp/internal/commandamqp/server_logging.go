@@ -107,6 +107,27 @@ func logRequestEnd(
 	}
 }
 
+func logHandlerPanic(
+	ctx context.Context,
+	logger twelf.Logger,
+	peerID ident.PeerID,
+	msgID ident.MessageID,
+	req rinq.Request,
+	v interface{},
+	stack []byte,
+) {
+	logger.Log(
+		"%s server recovered from a panic in the '%s::%s' command handler for request %s, request has been rejected [%s]: %v\n%s",
+		peerID.ShortString(),
+		req.Namespace,
+		req.Command,
+		msgID.ShortString(),
+		trace.Get(ctx),
+		v,
+		stack,
+	)
+}
+
 func logNoLongerListening(
 	logger twelf.Logger,
 	peerID ident.PeerID,
@@ -169,6 +190,42 @@ func logServerStart(
 	)
 }
 
+func logPreFetchAdjusted(
+	logger twelf.Logger,
+	peerID ident.PeerID,
+	prev uint,
+	n uint,
+) {
+	logger.Debug(
+		"%s server adjusted pre-fetch from %d to %d",
+		peerID.ShortString(),
+		prev,
+		n,
+	)
+}
+
+func logConsuming(
+	logger twelf.Logger,
+	peerID ident.PeerID,
+	ns string,
+	workers uint,
+) {
+	if workers == 0 {
+		logger.Debug(
+			"%s server is consuming '%s' namespace on the shared channel",
+			peerID.ShortString(),
+			ns,
+		)
+	} else {
+		logger.Debug(
+			"%s server is consuming '%s' namespace on a dedicated channel (workers: %d)",
+			peerID.ShortString(),
+			ns,
+			workers,
+		)
+	}
+}
+
 func logServerStopping(
 	logger twelf.Logger,
 	peerID ident.PeerID,
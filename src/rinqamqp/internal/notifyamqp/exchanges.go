@@ -1,21 +1,49 @@
 package notifyamqp
 
-import "github.com/streadway/amqp"
+import (
+	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/streadway/amqp"
+)
 
 const (
 	// unicastExchange is the exchange used to publish notifications directly to
 	// a specific session.
-	unicastExchange = "ntf.uc"
+	unicastExchange = "ntf.uct"
 
 	// multicastExchange is the exchange used to publish notifications that are
 	// sent to multiple sessions based on a rinq.Constraint.
-	multicastExchange = "ntf.mc"
+	multicastExchange = "ntf.mct"
+
+	// multicastHintExchange is the exchange used to publish multicast
+	// notifications that are filtered by the broker, based on hint headers
+	// derived from the notification's constraint. Notifications are then
+	// routed by each peer's hinted exchange, in the same way as those published
+	// to multicastExchange.
+	multicastHintExchange = "ntf.mch"
+
+	// hintedAnchorKey is the binding key used to bind each peer's notification
+	// queue to its hinted exchange, such that the exchange is not deleted while
+	// no subscriptions are bound. It begins with a reserved segment, so it
+	// can not be matched by the routing keys used for notifications.
+	hintedAnchorKey = "_anchor"
+
+	// receiptExchange is the exchange used to publish receipts that confirm
+	// a notification has been handled, routed by the notification's message
+	// ID.
+	receiptExchange = "ntf.rcpt"
 )
 
+// hintedExchange returns the name of the exchange that routes notifications
+// from multicastHintExchange to the notification queue of the peer id, using
+// the same binding keys as multicastExchange.
+func hintedExchange(id ident.PeerID) string {
+	return id.ShortString() + ".ntf.mch"
+}
+
 func declareExchanges(channel *amqp.Channel) error {
 	if err := channel.ExchangeDeclare(
 		unicastExchange,
-		"direct",
+		"topic",
 		false, // durable
 		false, // autoDelete
 		false, // internal
@@ -27,7 +55,31 @@ func declareExchanges(channel *amqp.Channel) error {
 
 	if err := channel.ExchangeDeclare(
 		multicastExchange,
-		"direct",
+		"topic",
+		false, // durable
+		false, // autoDelete
+		false, // internal
+		false, // noWait
+		nil,   // args
+	); err != nil {
+		return err
+	}
+
+	if err := channel.ExchangeDeclare(
+		receiptExchange,
+		"topic",
+		false, // durable
+		false, // autoDelete
+		false, // internal
+		false, // noWait
+		nil,   // args
+	); err != nil {
+		return err
+	}
+
+	if err := channel.ExchangeDeclare(
+		multicastHintExchange,
+		"headers",
 		false, // durable
 		false, // autoDelete
 		false, // internal
@@ -0,0 +1,83 @@
+package notifyamqp
+
+import (
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/options"
+	"github.com/streadway/amqp"
+)
+
+// This file exposes unexported parts of the package to the tests in the
+// notifyamqp_test package.
+
+var (
+	NewRetainer       = newRetainer
+	NewReceiptTracker = newReceiptTracker
+	MulticastHints    = multicastHints
+	HintHeader        = hintHeader
+)
+
+// RetainedCount returns the number of notifications held by r, including any
+// that have expired but have not yet been discarded.
+func RetainedCount(r *retainer) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return len(r.notifications)
+}
+
+// UntrackReceipt calls t.Untrack() and returns the content of the receipt.
+func UntrackReceipt(t *receiptTracker, msgID ident.MessageID) (count int, notFound bool) {
+	r := t.Untrack(msgID)
+	return r.count, r.notFound
+}
+
+// NewReceipt returns a delivery containing a receipt for the notification with
+// the given ID.
+func NewReceipt(msgID ident.MessageID, count int, notFound bool) *amqp.Delivery {
+	var msg amqp.Publishing
+	packReceipt(&msg, receipt{count, notFound})
+
+	return &amqp.Delivery{
+		MessageId: msgID.String(),
+		Type:      msg.Type,
+		Headers:   msg.Headers,
+	}
+}
+
+// Mailbox is a mailbox with a specific policy, in which each notification is
+// identified by its type.
+type Mailbox struct {
+	box    mailbox
+	policy options.MailboxPolicy
+}
+
+// NewMailbox returns an empty mailbox that uses the policy p.
+func NewMailbox(p options.MailboxPolicy) *Mailbox {
+	return &Mailbox{policy: p}
+}
+
+// Push adds a notification of type t to the mailbox, as per mailbox.push(). It
+// returns the type of the notification that was discarded, if any.
+func (m *Mailbox) Push(t string) (dropped string, ok bool) {
+	d := &delivery{
+		proto: &rinq.Notification{Type: t},
+	}
+
+	x, ok := m.box.push(mail{d: d}, m.policy)
+	if x.d != nil {
+		dropped = x.d.proto.Type
+	}
+
+	return
+}
+
+// Queue returns the types of the notifications in the mailbox, oldest first.
+func (m *Mailbox) Queue() []string {
+	var types []string
+	for _, x := range m.box.queue {
+		types = append(types, x.d.proto.Type)
+	}
+
+	return types
+}
@@ -4,9 +4,11 @@ import (
 	"github.com/rinq/rinq-go/src/internal/localsession"
 	"github.com/rinq/rinq-go/src/internal/notify"
 	"github.com/rinq/rinq-go/src/internal/revisions"
+	"github.com/rinq/rinq-go/src/internal/watchdog"
 	"github.com/rinq/rinq-go/src/rinq/ident"
 	"github.com/rinq/rinq-go/src/rinq/options"
 	"github.com/rinq/rinq-go/src/rinqamqp/internal/amqputil"
+	"github.com/streadway/amqp"
 )
 
 // New returns a pair of notifier and listener.
@@ -16,6 +18,7 @@ func New(
 	sessions *localsession.Store,
 	revs revisions.Store,
 	channels amqputil.ChannelPool,
+	wd *watchdog.Watchdog,
 ) (notify.Notifier, notify.Listener, error) {
 	channel, err := channels.GetQOS(opts.SessionWorkers) // do not return to pool, use for listener
 	if err != nil {
@@ -26,18 +29,50 @@ func New(
 		return nil, nil, err
 	}
 
+	var hints *amqp.Channel
+	if opts.MulticastFiltering {
+		hints, err = channels.Get() // do not return to pool, use for hint bindings by listener
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	receipts := newReceiptTracker()
+
+	var retained *retainer
+	if opts.Retention {
+		retained = newRetainer(maxRetained)
+	}
+
 	listener, err := newListener(
 		peerID,
 		opts.SessionWorkers,
 		sessions,
 		revs,
+		channels,
+		receipts,
+		retained,
 		channel,
+		hints,
 		opts.Logger,
 		opts.Tracer,
+		opts.FailFast,
+		opts.Mailbox,
+		wd,
 	)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	return newNotifier(peerID, channels, opts.Logger), listener, nil
+	notifier := newNotifier(
+		peerID,
+		channels,
+		receipts,
+		retained,
+		opts.DefaultTimeout,
+		opts.Logger,
+		opts.MulticastFiltering,
+	)
+
+	return notifier, listener, nil
 }
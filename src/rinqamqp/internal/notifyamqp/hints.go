@@ -0,0 +1,186 @@
+package notifyamqp
+
+import (
+	"hash/fnv"
+	"strconv"
+
+	"github.com/rinq/rinq-go/src/rinq/constraint"
+	"github.com/streadway/amqp"
+)
+
+const (
+	// hintBuckets is the number of distinct hint headers. Attribute values are
+	// hashed into buckets to bound the number of bindings a listener needs,
+	// at the cost of occasionally receiving notifications that do not match
+	// any of its sessions.
+	hintBuckets = 1024
+
+	// hintHeaderPrefix is the prefix of the hint headers in multicast
+	// notifications published to multicastHintExchange.
+	hintHeaderPrefix = "h."
+)
+
+// hintHeader returns the name of the hint header for the attribute k with the
+// value v in the ns namespace.
+func hintHeader(ns, k, v string) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(ns))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(k))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(v))
+
+	return hintHeaderPrefix + strconv.FormatUint(uint64(h.Sum32()%hintBuckets), 10)
+}
+
+// hintBindingArgs returns the arguments used to bind a peer's hinted exchange
+// to multicastHintExchange for the hint header h.
+func hintBindingArgs(h string) amqp.Table {
+	return amqp.Table{
+		"x-match": "any",
+		h:         true,
+	}
+}
+
+// catchAllBindingArgs are the arguments used to bind a peer's hinted exchange
+// to multicastHintExchange such that it receives every notification,
+// regardless of its hints.
+var catchAllBindingArgs = amqp.Table{
+	"x-match": "all",
+}
+
+// multicastHints returns the hint headers for a multicast notification with
+// the constraint con, using ns as the default namespace.
+//
+// Any session that matches con has an attribute value represented by at least
+// one of the returned headers. It returns nil if con does not require any
+// specific attribute values, in which case the notification can not be
+// filtered.
+func multicastHints(ns string, con constraint.Constraint) []string {
+	hints, _ := con.Accept(hintPlanner{}, ns)
+	if hints == nil {
+		return nil
+	}
+
+	return hints.([]string)
+}
+
+// hintPlanner is a constraint.Visitor that produces the hint headers for a
+// constraint. Each method returns a []string, or nil if the constraint can not
+// be represented by hints.
+type hintPlanner struct{}
+
+func (p hintPlanner) None(...interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+func (p hintPlanner) Within(ns string, cons []constraint.Constraint, _ ...interface{}) (interface{}, error) {
+	return p.And(cons, ns)
+}
+
+func (p hintPlanner) Equal(k, v string, args ...interface{}) (interface{}, error) {
+	if v == "" {
+		// sessions without the attribute also match
+		return nil, nil
+	}
+
+	return []string{hintHeader(args[0].(string), k, v)}, nil
+}
+
+func (p hintPlanner) NotEqual(string, string, ...interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+func (p hintPlanner) Not(constraint.Constraint, ...interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+// And returns the hints of the term with the fewest hints, as a session must
+// match every term.
+func (p hintPlanner) And(cons []constraint.Constraint, args ...interface{}) (interface{}, error) {
+	var result []string
+
+	for _, con := range cons {
+		hints, _ := con.Accept(p, args...)
+		if hints == nil {
+			continue
+		}
+
+		if result == nil || len(hints.([]string)) < len(result) {
+			result = hints.([]string)
+		}
+	}
+
+	if result == nil {
+		return nil, nil
+	}
+
+	return result, nil
+}
+
+// Or returns the hints of every term, as a session need only match one term.
+func (p hintPlanner) Or(cons []constraint.Constraint, args ...interface{}) (interface{}, error) {
+	var result []string
+
+	for _, con := range cons {
+		hints, _ := con.Accept(p, args...)
+		if hints == nil {
+			return nil, nil
+		}
+
+		result = append(result, hints.([]string)...)
+	}
+
+	if result == nil {
+		return nil, nil
+	}
+
+	return result, nil
+}
+
+func (p hintPlanner) In(k string, vs []string, args ...interface{}) (interface{}, error) {
+	if len(vs) == 0 {
+		return nil, nil
+	}
+
+	ns := args[0].(string)
+	result := make([]string, 0, len(vs))
+
+	for _, v := range vs {
+		if v == "" {
+			return nil, nil
+		}
+
+		result = append(result, hintHeader(ns, k, v))
+	}
+
+	return result, nil
+}
+
+func (p hintPlanner) Prefix(string, string, ...interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+func (p hintPlanner) Matches(string, string, ...interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+func (p hintPlanner) Exists(string, ...interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+func (p hintPlanner) Less(string, string, ...interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+func (p hintPlanner) Greater(string, string, ...interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+func (p hintPlanner) VersionLess(string, string, ...interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+func (p hintPlanner) VersionGreater(string, string, ...interface{}) (interface{}, error) {
+	return nil, nil
+}
@@ -0,0 +1,69 @@
+package notifyamqp_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/rinq/rinq-go/src/rinq/constraint"
+	. "github.com/rinq/rinq-go/src/rinqamqp/internal/notifyamqp"
+)
+
+var _ = Describe("multicast hints", func() {
+	DescribeTable(
+		"returns the hint headers for constraints that require specific values",
+		func(con constraint.Constraint, hints []string) {
+			Expect(MulticastHints("ns", con)).To(ConsistOf(hints))
+		},
+		Entry(
+			"Equal",
+			constraint.Equal("a", "1"),
+			[]string{HintHeader("ns", "a", "1")},
+		),
+		Entry(
+			"In",
+			constraint.In("a", "1", "2"),
+			[]string{HintHeader("ns", "a", "1"), HintHeader("ns", "a", "2")},
+		),
+		Entry(
+			"Within",
+			constraint.Within("other", constraint.Equal("a", "1")),
+			[]string{HintHeader("other", "a", "1")},
+		),
+		Entry(
+			"And uses the term with the fewest hints",
+			constraint.And(constraint.In("a", "1", "2"), constraint.Equal("b", "1")),
+			[]string{HintHeader("ns", "b", "1")},
+		),
+		Entry(
+			"And ignores terms that can not be filtered",
+			constraint.And(constraint.Prefix("a", "x"), constraint.Equal("b", "1")),
+			[]string{HintHeader("ns", "b", "1")},
+		),
+		Entry(
+			"Or uses the hints of every term",
+			constraint.Or(constraint.Equal("a", "1"), constraint.Equal("b", "2")),
+			[]string{HintHeader("ns", "a", "1"), HintHeader("ns", "b", "2")},
+		),
+	)
+
+	DescribeTable(
+		"returns nil for constraints that can not be filtered",
+		func(con constraint.Constraint) {
+			Expect(MulticastHints("ns", con)).To(BeNil())
+		},
+		Entry("None", constraint.None),
+		Entry("Equal with an empty value", constraint.Equal("a", "")),
+		Entry("NotEqual", constraint.NotEqual("a", "1")),
+		Entry("Not", constraint.Not(constraint.Equal("a", "1"))),
+		Entry("In with an empty value", constraint.In("a", "1", "")),
+		Entry("Prefix", constraint.Prefix("a", "1")),
+		Entry("Matches", constraint.Matches("a", "^1")),
+		Entry("Exists", constraint.Exists("a")),
+		Entry("Less", constraint.Less("a", "1")),
+		Entry("Greater", constraint.Greater("a", "1")),
+		Entry("VersionLess", constraint.VersionLess("a", "1")),
+		Entry("VersionGreater", constraint.VersionGreater("a", "1")),
+		Entry("And without filterable terms", constraint.And(constraint.Exists("a"), constraint.Exists("b"))),
+		Entry("Or with a term that can not be filtered", constraint.Or(constraint.Equal("a", "1"), constraint.Exists("b"))),
+	)
+})
@@ -2,17 +2,23 @@ package notifyamqp
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"runtime/debug"
 	"sync"
 
 	"github.com/jmalloc/twelf/src/twelf"
 	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/rinq/rinq-go/src/internal/localsession"
 	"github.com/rinq/rinq-go/src/internal/notify"
+	"github.com/rinq/rinq-go/src/internal/opentr"
 	"github.com/rinq/rinq-go/src/internal/revisions"
 	"github.com/rinq/rinq-go/src/internal/service"
+	"github.com/rinq/rinq-go/src/internal/watchdog"
 	"github.com/rinq/rinq-go/src/rinq"
 	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/options"
+	"github.com/rinq/rinq-go/src/rinq/trace"
 	"github.com/rinq/rinq-go/src/rinqamqp/internal/amqputil"
 	"github.com/streadway/amqp"
 )
@@ -25,21 +31,36 @@ type listener struct {
 	preFetch  uint
 	sessions  *localsession.Store
 	revisions revisions.Store
+	channels  amqputil.ChannelPool // used to publish receipts
+	receipts  *receiptTracker
+	retained  *retainer // nil if retention is disabled
 	logger    twelf.Logger
 	tracer    opentracing.Tracer
+	failFast  bool // do not recover panics in notification handlers
+	mailbox   options.MailboxPolicy
+	watchdog  *watchdog.Watchdog
 
 	parentCtx context.Context // parent of all contexts passed to handlers
 	cancelCtx func()          // cancels parentCtx when the server stops
 
 	// state-machine data
 	channel    *amqp.Channel        // channel used for consuming
-	namespaces map[string]uint      // map of namespace to listener count
+	bindings   map[string]uint      // map of multicast binding key to subscription count
 	deliveries <-chan amqp.Delivery // incoming notifications
 	amqpClosed chan *amqp.Error
-	pending    uint // number of notifications currently being handled
+	pending    uint          // number of notifications currently being handled
+	posted     chan struct{} // closed when the most recent delivery has been posted to mailboxes
 
-	mutex    sync.RWMutex // guards handlers so handler can be read in dispatch() goroutine
-	handlers map[ident.SessionID]map[string]rinq.NotificationHandler
+	hintMutex   sync.Mutex      // guards hintChannel and hints
+	hintChannel *amqp.Channel   // channel used to bind hints, nil if filtering is disabled
+	hints       map[string]uint // map of hint header to indexed value count
+
+	mutex    sync.RWMutex // guards handlers and topics so they can be read in dispatch() goroutine
+	handlers map[ident.SessionID]map[string]subscription
+	topics   map[string]rinq.PublicationHandler
+
+	boxMutex  sync.Mutex // guards mailboxes
+	mailboxes map[ident.SessionID]*mailbox
 }
 
 // newListener creates, starts and returns a new listener.
@@ -48,25 +69,46 @@ func newListener(
 	preFetch uint,
 	sessions *localsession.Store,
 	revs revisions.Store,
+	channels amqputil.ChannelPool,
+	receipts *receiptTracker,
+	retained *retainer,
 	channel *amqp.Channel,
+	hintChannel *amqp.Channel,
 	logger twelf.Logger,
 	tracer opentracing.Tracer,
+	failFast bool,
+	mailboxPolicy options.MailboxPolicy,
+	wd *watchdog.Watchdog,
 ) (notify.Listener, error) {
 	l := &listener{
 		peerID:    peerID,
 		preFetch:  preFetch,
 		sessions:  sessions,
 		revisions: revs,
+		channels:  channels,
+		receipts:  receipts,
+		retained:  retained,
 		logger:    logger,
 		tracer:    tracer,
+		failFast:  failFast,
+		mailbox:   mailboxPolicy,
+		watchdog:  wd,
 
 		channel:    channel,
-		namespaces: map[string]uint{},
+		bindings:   map[string]uint{},
 		amqpClosed: make(chan *amqp.Error, 1),
+		posted:     make(chan struct{}),
+
+		hintChannel: hintChannel,
+		hints:       map[string]uint{},
 
-		handlers: map[ident.SessionID]map[string]rinq.NotificationHandler{},
+		handlers:  map[ident.SessionID]map[string]subscription{},
+		topics:    map[string]rinq.PublicationHandler{},
+		mailboxes: map[ident.SessionID]*mailbox{},
 	}
 
+	close(l.posted) // there are no prior deliveries to wait for
+
 	l.sm = service.NewStateMachine(l.run, l.finalize)
 	l.Service = l.sm
 
@@ -76,32 +118,63 @@ func newListener(
 
 	go l.sm.Run()
 
+	if hintChannel != nil {
+		sessions.SetIndexObserver(l)
+	}
+
 	return l, nil
 }
 
-func (l *listener) Listen(id ident.SessionID, ns string, h rinq.NotificationHandler) (added bool, err error) {
+func (l *listener) Listen(
+	id ident.SessionID,
+	ns string,
+	types []string,
+	h rinq.NotificationHandler,
+) (changed bool, err error) {
+	var (
+		replay                    bool
+		replayTypes, replayExcept []string
+	)
+
 	err = l.sm.Do(func() error {
 		l.mutex.Lock()
 		defer l.mutex.Unlock()
 
-		handlers, ok := l.handlers[id]
+		subs, ok := l.handlers[id]
 		if !ok {
-			handlers = map[string]rinq.NotificationHandler{}
-			l.handlers[id] = handlers
+			subs = map[string]subscription{}
+			l.handlers[id] = subs
 		}
 
-		_, ok = handlers[ns]
-		handlers[ns] = h
+		sub := newSubscription(types, h)
+		prev, ok := subs[ns]
+		subs[ns] = sub
 
 		if ok {
-			return nil
+			if prev.hasSameTypes(sub) {
+				return nil
+			}
+
+			if err := l.unbind(ns, prev); err != nil {
+				return err
+			}
+
+			// only the types that were not already accepted are replayed, as
+			// notifications of the other types have already been replayed
+			replayTypes, replayExcept, replay = sub.addedTypes(prev)
+		} else {
+			replayTypes, replay = types, true
 		}
 
-		added = true
+		changed = true
 
-		return l.bind(ns)
+		return l.bind(ns, sub)
 	})
 
+	if replay && err == nil && l.retained != nil {
+		l.requestReplay(id, ns, replayTypes, replayExcept)
+	}
+
 	return
 }
 
@@ -110,20 +183,20 @@ func (l *listener) Unlisten(id ident.SessionID, ns string) (removed bool, err er
 		l.mutex.Lock()
 		defer l.mutex.Unlock()
 
-		handlers, ok := l.handlers[id]
+		subs, ok := l.handlers[id]
 		if !ok {
 			return nil
 		}
 
-		_, ok = handlers[ns]
+		sub, ok := subs[ns]
 		if !ok {
 			return nil
 		}
 
-		delete(handlers, ns)
+		delete(subs, ns)
 		removed = true
 
-		return l.unbind(ns)
+		return l.unbind(ns, sub)
 	})
 
 	return
@@ -134,11 +207,11 @@ func (l *listener) UnlistenAll(id ident.SessionID) error {
 		l.mutex.Lock()
 		defer l.mutex.Unlock()
 
-		handlers := l.handlers[id]
+		subs := l.handlers[id]
 		delete(l.handlers, id)
 
-		for ns := range handlers {
-			if err := l.unbind(ns); err != nil {
+		for ns, sub := range subs {
+			if err := l.unbind(ns, sub); err != nil {
 				return err
 			}
 		}
@@ -147,60 +220,200 @@ func (l *listener) UnlistenAll(id ident.SessionID) error {
 	})
 }
 
-func (l *listener) bind(ns string) error {
-	count := l.namespaces[ns]
-	l.namespaces[ns] = count + 1
+func (l *listener) Subscribe(topic string, h rinq.PublicationHandler) (added bool, err error) {
+	err = l.sm.Do(func() error {
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
+
+		if _, ok := l.topics[topic]; ok {
+			l.topics[topic] = h
+			return nil
+		}
+
+		if err := l.channel.QueueBind(
+			notifyQueue(l.peerID),
+			topicRoutingKey(topic),
+			multicastExchange,
+			false, // noWait
+			nil,   // args
+		); err != nil {
+			return err
+		}
+
+		l.topics[topic] = h
+		added = true
 
-	if count != 0 {
 		return nil
+	})
+
+	return
+}
+
+func (l *listener) Unsubscribe(topic string) (removed bool, err error) {
+	err = l.sm.Do(func() error {
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
+
+		if _, ok := l.topics[topic]; !ok {
+			return nil
+		}
+
+		delete(l.topics, topic)
+		removed = true
+
+		return l.channel.QueueUnbind(
+			notifyQueue(l.peerID),
+			topicRoutingKey(topic),
+			multicastExchange,
+			nil, // args
+		)
+	})
+
+	return
+}
+
+// bind binds the notification queue to the multicast and hinted exchanges for
+// the notifications accepted by sub, in namespaces that match ns.
+func (l *listener) bind(ns string, sub subscription) error {
+	queue := notifyQueue(l.peerID)
+	hinted := hintedExchange(l.peerID)
+
+	for _, key := range sub.bindingKeys(ns) {
+		count := l.bindings[key]
+		l.bindings[key] = count + 1
+
+		if count != 0 {
+			continue
+		}
+
+		if err := l.channel.QueueBind(
+			queue,
+			key,
+			multicastExchange,
+			false, // noWait
+			nil,   // args
+		); err != nil {
+			return err
+		}
+
+		if err := l.channel.QueueBind(
+			queue,
+			key,
+			hinted,
+			false, // noWait
+			nil,   // args
+		); err != nil {
+			return err
+		}
 	}
 
+	return nil
+}
+
+// unbind reverses the bindings made by bind(ns, sub), unless they are still
+// required by other subscriptions.
+func (l *listener) unbind(ns string, sub subscription) error {
 	queue := notifyQueue(l.peerID)
+	hinted := hintedExchange(l.peerID)
 
-	if err := l.channel.QueueBind(
-		queue,
-		unicastRoutingKey(ns, l.peerID),
-		unicastExchange,
-		false, // noWait
-		nil,   // args
-	); err != nil {
-		return err
+	for _, key := range sub.bindingKeys(ns) {
+		count := l.bindings[key] - 1
+
+		if count != 0 {
+			l.bindings[key] = count
+			continue
+		}
+
+		delete(l.bindings, key)
+
+		if err := l.channel.QueueUnbind(
+			queue,
+			key,
+			multicastExchange,
+			nil, // args
+		); err != nil {
+			return err
+		}
+
+		if err := l.channel.QueueUnbind(
+			queue,
+			key,
+			hinted,
+			nil, // args
+		); err != nil {
+			return err
+		}
 	}
 
-	return l.channel.QueueBind(
-		queue,
-		ns,
-		multicastExchange,
+	return nil
+}
+
+// Indexed binds the hinted exchange to multicastHintExchange for the hint
+// header that represents the value v of the attribute k in the ns namespace,
+// unless it is already bound for another value that shares the same header.
+//
+// It implements localsession.IndexObserver. The binding is made before it
+// returns, so that notifications sent once an attribute update has been
+// acknowledged are delivered to the updated session.
+func (l *listener) Indexed(ns, k, v string) {
+	h := hintHeader(ns, k, v)
+
+	l.hintMutex.Lock()
+	defer l.hintMutex.Unlock()
+
+	count := l.hints[h]
+	l.hints[h] = count + 1
+
+	if count != 0 || l.hintChannel == nil {
+		return
+	}
+
+	if err := l.hintChannel.ExchangeBind(
+		hintedExchange(l.peerID),
+		"", // routing key is ignored by headers exchanges
+		multicastHintExchange,
 		false, // noWait
-		nil,   // args
-	)
+		hintBindingArgs(h),
+	); err != nil {
+		logHintBindingError(l.logger, l.peerID, h, err)
+	}
 }
 
-func (l *listener) unbind(ns string) error {
-	count := l.namespaces[ns] - 1
-	l.namespaces[ns] = count
+// Unindexed removes the binding made by Indexed(), once no other indexed
+// values share the same header.
+//
+// It implements localsession.IndexObserver.
+func (l *listener) Unindexed(ns, k, v string) {
+	h := hintHeader(ns, k, v)
 
-	if count != 0 {
-		return nil
+	l.hintMutex.Lock()
+	defer l.hintMutex.Unlock()
+
+	count := l.hints[h]
+	if count == 0 {
+		return
 	}
 
-	queue := notifyQueue(l.peerID)
+	if count > 1 {
+		l.hints[h] = count - 1
+		return
+	}
 
-	if err := l.channel.QueueUnbind(
-		queue,
-		unicastRoutingKey(ns, l.peerID),
-		unicastExchange,
-		nil, // args
-	); err != nil {
-		return err
+	delete(l.hints, h)
+
+	if l.hintChannel == nil {
+		return
 	}
 
-	return l.channel.QueueUnbind(
-		queue,
-		ns,
-		multicastExchange,
-		nil, // args
-	)
+	if err := l.hintChannel.ExchangeUnbind(
+		hintedExchange(l.peerID),
+		"", // routing key is ignored by headers exchanges
+		multicastHintExchange,
+		false, // noWait
+		hintBindingArgs(h),
+	); err != nil {
+		logHintBindingError(l.logger, l.peerID, h, err)
+	}
 }
 
 // initialize prepares the AMQP channel
@@ -220,6 +433,84 @@ func (l *listener) initialize() error {
 		return err
 	}
 
+	// replay requests are sent to all peers, as any peer may have retained
+	// notifications for the listening session
+	if l.retained != nil {
+		if err := l.channel.QueueBind(
+			queue,
+			replayRoutingKey,
+			multicastExchange,
+			false, // noWait
+			nil,   // args
+		); err != nil {
+			return err
+		}
+	}
+
+	// notifications published to multicastHintExchange are routed to the queue
+	// via the hinted exchange, so that they are matched against the same
+	// binding keys as those published to multicastExchange
+	hinted := hintedExchange(l.peerID)
+
+	if err := l.channel.ExchangeDeclare(
+		hinted,
+		"topic",
+		false, // durable
+		true,  // autoDelete
+		false, // internal
+		false, // noWait
+		nil,   // args
+	); err != nil {
+		return err
+	}
+
+	if err := l.channel.QueueBind(
+		queue,
+		hintedAnchorKey,
+		hinted,
+		false, // noWait
+		nil,   // args
+	); err != nil {
+		return err
+	}
+
+	// without filtering, the hinted exchange receives every notification
+	// published to multicastHintExchange, regardless of its hints
+	if l.hintChannel == nil {
+		if err := l.channel.ExchangeBind(
+			hinted,
+			"", // routing key is ignored by headers exchanges
+			multicastHintExchange,
+			false, // noWait
+			catchAllBindingArgs,
+		); err != nil {
+			return err
+		}
+	}
+
+	// unicast notifications for this peer's sessions are always received, even
+	// if the target session is not listening, so that a receipt can be sent
+	if err := l.channel.QueueBind(
+		queue,
+		unicastBindingKey("#", l.peerID),
+		unicastExchange,
+		false, // noWait
+		nil,   // args
+	); err != nil {
+		return err
+	}
+
+	// receipts for notifications sent by this peer are routed by message ID
+	if err := l.channel.QueueBind(
+		queue,
+		l.peerID.String()+".*",
+		receiptExchange,
+		false, // noWait
+		nil,   // args
+	); err != nil {
+		return err
+	}
+
 	var err error
 	l.deliveries, err = l.channel.Consume(
 		queue,
@@ -247,8 +538,33 @@ func (l *listener) run() (service.State, error) {
 				// sometimes the consumer channel is closed before the AMQP channel
 				return nil, <-l.amqpClosed
 			}
+
+			if msg.Exchange == receiptExchange {
+				l.receive(&msg)
+				continue
+			}
+
 			l.pending++
-			go l.dispatch(&msg)
+
+			// publications are not delivered to sessions, so they do not need
+			// to be ordered with respect to notifications
+			if topic, ok := unpackTopic(&msg); ok {
+				go l.publication(&msg, topic)
+				continue
+			}
+
+			if msg.Exchange == multicastExchange && msg.RoutingKey == replayRoutingKey {
+				go l.replay(&msg)
+				continue
+			}
+
+			// each delivery waits for the previous one to be posted to the
+			// session mailboxes, preserving the order of notifications for
+			// each session. Posting never blocks, so a session with a full
+			// mailbox does not delay the notifications of other sessions.
+			prev := l.posted
+			l.posted = make(chan struct{})
+			go l.dispatch(&msg, prev, l.posted)
 
 		case req := <-l.sm.Commands:
 			l.sm.Execute(req)
@@ -307,6 +623,13 @@ func (l *listener) finalize(err error) error {
 	l.cancelCtx()
 	logListenerStop(l.logger, l.peerID, err)
 
+	l.hintMutex.Lock()
+	if l.hintChannel != nil {
+		_ = l.hintChannel.Close()
+		l.hintChannel = nil // do not bind hints for sessions destroyed after stopping
+	}
+	l.hintMutex.Unlock()
+
 	closeErr := l.channel.Close()
 
 	// only report the closeErr if there's no causal error.
@@ -317,94 +640,298 @@ func (l *listener) finalize(err error) error {
 	return err
 }
 
-// dispatch validates an incoming notification and dispatches it the
-// appropriate handler.
-func (l *listener) dispatch(msg *amqp.Delivery) {
-	defer l.sm.DoGraceful(func() error {
-		l.pending--
-		return nil
-	})
-
-	// create a prototype notification that is cloned for each handler
-	proto := &rinq.Notification{}
+// dispatch validates an incoming notification and posts it to the mailboxes
+// of the appropriate sessions.
+//
+// It waits for prev to be closed before posting, and closes next once it has
+// finished posting.
+func (l *listener) dispatch(msg *amqp.Delivery, prev <-chan struct{}, next chan<- struct{}) {
+	d := &delivery{
+		msg: msg,
+
+		// create a prototype notification that is cloned for each handler
+		proto: &rinq.Notification{},
+	}
 
 	var err error
-	proto.ID, err = ident.ParseMessageID(msg.MessageId)
+	d.proto.ID, err = ident.ParseMessageID(msg.MessageId)
 	if err != nil {
+		<-prev
+		close(next)
+
 		_ = msg.Reject(false) // false = don't requeue
 		logInvalidMessageID(l.logger, l.peerID, msg.MessageId)
+
+		_ = l.sm.DoGraceful(func() error {
+			l.pending--
+			return nil
+		})
+
+		return
 	}
 
-	defer func() {
-		if err == nil {
-			_ = msg.Ack(false) // false = single message
-		} else {
-			_ = msg.Reject(false) // false = don't requeue
-			logIgnoredMessage(l.logger, l.peerID, proto.ID, err)
+	sessions, err := l.route(d)
+
+	<-prev
+
+	if err == nil && len(sessions) != 0 {
+		d.remaining = len(sessions)
+
+		for _, sess := range sessions {
+			l.post(sess, d)
 		}
-	}()
+	}
+
+	close(next)
+
+	if err != nil || len(sessions) == 0 {
+		l.complete(d, err)
+	}
+}
+
+// route unpacks the notification in d and returns the sessions that are
+// listening for it.
+func (l *listener) route(d *delivery) ([]rinq.Session, error) {
+	msg := d.msg
+	proto := d.proto
+
+	var err error
 
 	// find the source session revision
 	proto.Source, err = l.revisions.GetRevision(proto.ID.Ref)
 	if err != nil {
-		return
+		return nil, err
 	}
 
 	proto.Namespace, proto.Type, proto.Payload, err = unpackCommonAttributes(msg)
 	if err != nil {
-		return
+		return nil, err
 	}
-	defer proto.Payload.Close()
 
 	var sessions []rinq.Session
 
 	switch msg.Exchange {
 	case unicastExchange:
-		sessions, err = l.findUnicastTarget(proto, msg)
-	case multicastExchange:
+		sessions, err = l.findUnicastTargets(proto, msg)
+		d.notFound = len(sessions) == 0
+	case multicastExchange, multicastHintExchange:
 		proto.IsMulticast = true
 		sessions, err = l.findMulticastTargets(proto, msg)
 	default:
 		err = fmt.Errorf("delivery via '%s' exchange is not expected", msg.Exchange)
 	}
 	if err != nil {
+		return nil, err
+	}
+
+	d.ctx = amqputil.UnpackTrace(l.parentCtx, msg)
+
+	d.spanOpts, err = unpackSpanOptions(msg, l.tracer)
+	if err != nil {
+		return nil, err
+	}
+
+	// only post to the sessions that have a handler, so that notifications
+	// for other namespaces do not occupy space in the mailbox
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	listening := sessions[:0]
+	for _, sess := range sessions {
+		if l.handler(sess.ID(), proto.Namespace, proto.Type) != nil {
+			listening = append(listening, sess)
+		}
+	}
+
+	return listening, nil
+}
+
+// receive passes an incoming receipt to the receipt tracker.
+func (l *listener) receive(msg *amqp.Delivery) {
+	if _, err := l.receipts.Deliver(msg); err != nil {
+		_ = msg.Reject(false) // false = don't requeue
+		logInvalidReceipt(l.logger, l.peerID, msg.MessageId, err)
 		return
 	}
 
-	ctx := amqputil.UnpackTrace(l.parentCtx, msg)
+	_ = msg.Ack(false) // false = single message
+}
 
-	spanOpts, err := unpackSpanOptions(msg, l.tracer)
+// publication invokes the handler for the topic of the publication in msg, if
+// the peer is subscribed to it.
+func (l *listener) publication(msg *amqp.Delivery, topic string) {
+	defer func() {
+		_ = l.sm.DoGraceful(func() error {
+			l.pending--
+			return nil
+		})
+	}()
+
+	pub := rinq.Publication{
+		Topic:   topic,
+		Payload: rinq.NewPayloadFromBytes(msg.Body),
+	}
+	defer pub.Payload.Close()
+
+	var err error
+	pub.ID, err = ident.ParseMessageID(msg.MessageId)
 	if err != nil {
+		_ = msg.Reject(false) // false = don't requeue
+		logInvalidMessageID(l.logger, l.peerID, msg.MessageId)
 		return
 	}
 
-	for _, sess := range sessions {
-		l.handle(
-			ctx,
-			sess,
-			proto,
-			spanOpts,
+	pub.Source = pub.ID.Ref.ID.Peer
+
+	spanOpts, err := unpackSpanOptions(msg, l.tracer)
+
+	if err == nil {
+		l.mutex.RLock()
+		h := l.topics[topic]
+		l.mutex.RUnlock()
+
+		if h != nil {
+			ctx := amqputil.UnpackTrace(l.parentCtx, msg)
+
+			if l.handlePublication(ctx, h, pub, spanOpts) {
+				err = errHandlerPanicked
+			}
+
+			if unpackReceiptRequest(msg) {
+				l.publishReceipt(pub.ID, receipt{count: 1})
+			}
+		}
+	}
+
+	if err == nil {
+		_ = msg.Ack(false) // false = single message
+	} else {
+		_ = msg.Reject(false) // false = don't requeue
+		logIgnoredMessage(l.logger, l.peerID, pub.ID, err)
+	}
+}
+
+// requestReplay asks all peers to replay their retained notifications in
+// namespaces that match ns, with one of the given types, and none of the
+// types in except, to the session id.
+func (l *listener) requestReplay(id ident.SessionID, ns string, types, except []string) {
+	msg := amqp.Publishing{}
+	packReplayRequest(&msg, id, ns, types, except)
+
+	channel, err := l.channels.Get()
+	if err == nil {
+		defer l.channels.Put(channel)
+
+		err = channel.Publish(
+			multicastExchange,
+			replayRoutingKey,
+			false, // mandatory
+			false, // immediate
+			msg,
 		)
 	}
+
+	if err != nil {
+		logReplayRequestError(l.logger, l.peerID, id, ns, err)
+	}
 }
 
-// findUnicastTarget returns the session that should receive the unicast
-// notification n.
-func (l *listener) findUnicastTarget(
+// replay sends the retained notifications that match the replay request in
+// msg to the session that requested them.
+func (l *listener) replay(msg *amqp.Delivery) {
+	defer func() {
+		_ = l.sm.DoGraceful(func() error {
+			l.pending--
+			return nil
+		})
+	}()
+
+	target, ns, types, except, err := unpackReplayRequest(msg)
+	if err != nil {
+		_ = msg.Reject(false) // false = don't requeue
+		logInvalidReplayRequest(l.logger, l.peerID, err)
+		return
+	}
+
+	_ = msg.Ack(false) // false = single message
+
+	retained := l.retained.Matching(ns, types, except)
+	if len(retained) == 0 {
+		return
+	}
+
+	channel, err := l.channels.Get()
+	if err != nil {
+		logReplayError(l.logger, l.peerID, target, err)
+		return
+	}
+	defer l.channels.Put(channel)
+
+	seqs := map[uint32]struct{}{target.Seq: {}}
+
+	for _, out := range retained {
+		packTargets(&out, seqs)
+
+		outNS, _ := out.Headers[namespaceHeader].(string)
+
+		if err := channel.Publish(
+			unicastExchange,
+			unicastRoutingKey(outNS, out.Type, target.Peer),
+			false, // mandatory
+			false, // immediate
+			out,
+		); err != nil {
+			logReplayError(l.logger, l.peerID, target, err)
+			return
+		}
+	}
+}
+
+// errHandlerPanicked indicates that at least one notification handler panicked
+// while handling a message. The message is rejected, but any other sessions
+// that are targeted by the notification still receive it.
+var errHandlerPanicked = errors.New("notification handler panicked")
+
+// findUnicastTargets returns the sessions that should receive the unicast
+// notification n. The notification may target more than one session on this
+// peer, see Notifier.NotifyEach().
+func (l *listener) findUnicastTargets(
 	n *rinq.Notification,
 	msg *amqp.Delivery,
 ) ([]rinq.Session, error) {
-	var sessID ident.SessionID
-	sessID, err := unpackTarget(msg)
+	ids, err := unpackTargets(msg, l.peerID)
 	if err != nil {
 		return nil, err
 	}
 
-	if sess, ok := l.sessions.Get(sessID); ok {
-		return []rinq.Session{sess}, nil
+	// retained multicast notifications are replayed to specific sessions, but
+	// are only delivered to those that match the constraint
+	if _, ok := msg.Headers[constraintHeader]; ok {
+		n.IsMulticast = true
+		n.Constraint, err = unpackConstraint(msg)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	return nil, nil
+	var sessions []rinq.Session
+
+	for _, id := range ids {
+		sess, ok := l.sessions.Get(id)
+		if !ok {
+			continue
+		}
+
+		if n.IsMulticast {
+			if _, attrs := sess.Attrs(); !attrs.MatchConstraint(n.Namespace, n.Constraint) {
+				continue
+			}
+		}
+
+		sessions = append(sessions, sess)
+	}
+
+	return sessions, nil
 }
 
 // findMulticastTargets returns the sessions that should receive the multicast
@@ -421,12 +948,11 @@ func (l *listener) findMulticastTargets(
 		return
 	}
 
-	l.sessions.Each(
+	l.sessions.EachMatching(
+		n.Namespace,
+		n.Constraint,
 		func(session *localsession.Session) {
-			_, attrs := session.Attrs()
-			if attrs.MatchConstraint(n.Namespace, n.Constraint) {
-				sessions = append(sessions, session)
-			}
+			sessions = append(sessions, session)
 		},
 	)
 
@@ -434,28 +960,96 @@ func (l *listener) findMulticastTargets(
 }
 
 // handle invokes the notification handler for a specific session, if one is
-// present.
+// present. handled is true if the handler was invoked, and panicked is true if
+// it panicked.
 func (l *listener) handle(
 	ctx context.Context,
 	sess rinq.Session,
 	proto *rinq.Notification,
 	spanOpts []opentracing.StartSpanOption,
-) {
+) (handled, panicked bool) {
 	l.mutex.RLock()
-	h := l.handlers[sess.ID()][proto.Namespace]
+	h := l.handler(sess.ID(), proto.Namespace, proto.Type)
 	l.mutex.RUnlock()
 
 	if h != nil {
+		handled = true
+
 		n := *proto
 		n.Payload = n.Payload.Clone()
 
 		span := l.tracer.StartSpan("", spanOpts...)
 		defer span.Finish()
 
+		defer l.watchdog.Track(rinq.LongRunningHandler{
+			ID:        n.ID,
+			Namespace: n.Namespace,
+			Type:      n.Type,
+			Session:   sess.ID(),
+			TraceID:   trace.Get(ctx),
+		})()
+
+		if !l.failFast {
+			defer func() {
+				if v := recover(); v != nil {
+					panicked = true
+					stack := debug.Stack()
+
+					opentr.LogPanic(span, v, stack)
+					logHandlerPanic(ctx, l.logger, l.peerID, sess.ID(), n, v, stack)
+
+					n.Payload.Close()
+				}
+			}()
+		}
+
 		h(
 			opentracing.ContextWithSpan(ctx, span),
 			sess,
 			n,
 		)
 	}
+
+	return
+}
+
+// handlePublication invokes the publication handler h. panicked is true if h
+// panicked.
+func (l *listener) handlePublication(
+	ctx context.Context,
+	h rinq.PublicationHandler,
+	pub rinq.Publication,
+	spanOpts []opentracing.StartSpanOption,
+) (panicked bool) {
+	pub.Payload = pub.Payload.Clone()
+
+	span := l.tracer.StartSpan("", spanOpts...)
+	defer span.Finish()
+
+	defer l.watchdog.Track(rinq.LongRunningHandler{
+		ID:      pub.ID,
+		Topic:   pub.Topic,
+		TraceID: trace.Get(ctx),
+	})()
+
+	if !l.failFast {
+		defer func() {
+			if v := recover(); v != nil {
+				panicked = true
+				stack := debug.Stack()
+
+				opentr.LogPanic(span, v, stack)
+				logPublicationHandlerPanic(ctx, l.logger, l.peerID, pub, v, stack)
+
+				pub.Payload.Close()
+			}
+		}()
+	}
+
+	h(
+		opentracing.ContextWithSpan(ctx, span),
+		pub,
+	)
+
+	return
 }
@@ -1,8 +1,13 @@
 package notifyamqp
 
 import (
+	"context"
+
 	"github.com/jmalloc/twelf/src/twelf"
+	"github.com/rinq/rinq-go/src/rinq"
 	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/options"
+	"github.com/rinq/rinq-go/src/rinq/trace"
 )
 
 func logInvalidMessageID(
@@ -31,6 +36,150 @@ func logIgnoredMessage(
 	)
 }
 
+func logHintBindingError(
+	logger twelf.Logger,
+	peerID ident.PeerID,
+	hint string,
+	err error,
+) {
+	logger.Log(
+		"%s listener could not update the binding for the '%s' multicast hint, some notifications may not be received: %s",
+		peerID.ShortString(),
+		hint,
+		err,
+	)
+}
+
+func logNotificationDropped(
+	logger twelf.Logger,
+	peerID ident.PeerID,
+	sessID ident.SessionID,
+	n *rinq.Notification,
+	p options.MailboxPolicy,
+) {
+	logger.Log(
+		"%s listener discarded '%s::%s' notification %s for session %s, the mailbox is full (depth: %d, overflow: %s)",
+		peerID.ShortString(),
+		n.Namespace,
+		n.Type,
+		n.ID.ShortString(),
+		sessID.ShortString(),
+		p.Depth,
+		p.Overflow,
+	)
+}
+
+func logInvalidReceipt(
+	logger twelf.Logger,
+	peerID ident.PeerID,
+	msgID string,
+	err error,
+) {
+	logger.Debug(
+		"%s listener ignored receipt for %s, %s",
+		peerID.ShortString(),
+		msgID,
+		err,
+	)
+}
+
+func logReceiptError(
+	logger twelf.Logger,
+	peerID ident.PeerID,
+	msgID ident.MessageID,
+	err error,
+) {
+	logger.Log(
+		"%s listener could not send receipt for message %s: %s",
+		peerID.ShortString(),
+		msgID.ShortString(),
+		err,
+	)
+}
+
+func logReplayRequestError(
+	logger twelf.Logger,
+	peerID ident.PeerID,
+	sessID ident.SessionID,
+	ns string,
+	err error,
+) {
+	logger.Log(
+		"%s listener could not request retained notifications in '%s' namespace for session %s: %s",
+		peerID.ShortString(),
+		ns,
+		sessID.ShortString(),
+		err,
+	)
+}
+
+func logInvalidReplayRequest(
+	logger twelf.Logger,
+	peerID ident.PeerID,
+	err error,
+) {
+	logger.Debug(
+		"%s listener ignored replay request, %s",
+		peerID.ShortString(),
+		err,
+	)
+}
+
+func logReplayError(
+	logger twelf.Logger,
+	peerID ident.PeerID,
+	sessID ident.SessionID,
+	err error,
+) {
+	logger.Log(
+		"%s listener could not replay retained notifications to session %s: %s",
+		peerID.ShortString(),
+		sessID.ShortString(),
+		err,
+	)
+}
+
+func logHandlerPanic(
+	ctx context.Context,
+	logger twelf.Logger,
+	peerID ident.PeerID,
+	sessID ident.SessionID,
+	n rinq.Notification,
+	v interface{},
+	stack []byte,
+) {
+	logger.Log(
+		"%s listener recovered from a panic in the '%s::%s' notification handler of session %s for notification %s [%s]: %v\n%s",
+		peerID.ShortString(),
+		n.Namespace,
+		n.Type,
+		sessID.ShortString(),
+		n.ID.ShortString(),
+		trace.Get(ctx),
+		v,
+		stack,
+	)
+}
+
+func logPublicationHandlerPanic(
+	ctx context.Context,
+	logger twelf.Logger,
+	peerID ident.PeerID,
+	pub rinq.Publication,
+	v interface{},
+	stack []byte,
+) {
+	logger.Log(
+		"%s listener recovered from a panic in the '%s' publication handler for publication %s [%s]: %v\n%s",
+		peerID.ShortString(),
+		pub.Topic,
+		pub.ID.ShortString(),
+		trace.Get(ctx),
+		v,
+		stack,
+	)
+}
+
 func logListenerStart(
 	logger twelf.Logger,
 	peerID ident.PeerID,
@@ -0,0 +1,237 @@
+package notifyamqp
+
+import (
+	"context"
+	"sync"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/options"
+	"github.com/streadway/amqp"
+)
+
+// delivery is a notification that has been posted to the mailboxes of one or
+// more sessions. The AMQP message is acknowledged once every session has
+// handled the notification, or it has been discarded.
+type delivery struct {
+	msg      *amqp.Delivery
+	ctx      context.Context
+	proto    *rinq.Notification // prototype notification, cloned for each handler
+	spanOpts []opentracing.StartSpanOption
+
+	mutex     sync.Mutex
+	remaining int  // number of sessions that have not yet handled the notification
+	handled   int  // number of sessions that have handled the notification
+	panicked  bool // true if any of the handlers panicked
+	notFound  bool // true if the target of a unicast notification does not exist
+}
+
+// mailbox is a queue of notifications for a single session. The notifications
+// are handled one at a time, in the order they were posted.
+type mailbox struct {
+	queue   []mail
+	waiting []mail // notifications held until there is space in the queue, in order
+	running bool   // true if a goroutine is draining the mailbox
+}
+
+// mail is a notification in a mailbox.
+type mail struct {
+	sess rinq.Session
+	d    *delivery
+}
+
+// post adds d to the mailbox of sess, starting a goroutine to drain the mailbox
+// if necessary. It never blocks, so a full mailbox does not delay the
+// notifications of other sessions.
+//
+// If the mailbox is full, l.mailbox.Overflow determines whether d is held until
+// there is space, or a notification is discarded. A held notification is not
+// acknowledged until it is handled, so the number of held notifications is
+// limited by the pre-fetch count.
+func (l *listener) post(sess rinq.Session, d *delivery) {
+	id := sess.ID()
+	m := mail{sess, d}
+
+	l.boxMutex.Lock()
+
+	box, ok := l.mailboxes[id]
+	if !ok {
+		box = &mailbox{}
+		l.mailboxes[id] = box
+	}
+
+	// notifications that arrive while others are held must be held behind
+	// them, to preserve the order of notifications for the session
+	if len(box.waiting) != 0 {
+		box.waiting = append(box.waiting, m)
+		l.boxMutex.Unlock()
+		return
+	}
+
+	dropped, ok := box.push(m, l.mailbox)
+	if !ok {
+		box.waiting = append(box.waiting, m)
+	} else if !box.running {
+		box.running = true
+		go l.drain(id, box)
+	}
+
+	l.boxMutex.Unlock()
+
+	if dropped.d != nil {
+		logNotificationDropped(l.logger, l.peerID, id, dropped.d.proto, l.mailbox)
+		l.release(dropped.d, false, false)
+	}
+}
+
+// push adds m to the end of the mailbox, unless it already contains p.Depth
+// notifications. If the mailbox is full, p.Overflow determines whether m or the
+// oldest notification in the mailbox is discarded and returned as dropped, or
+// ok is false and the mailbox is unchanged.
+func (box *mailbox) push(m mail, p options.MailboxPolicy) (dropped mail, ok bool) {
+	if p.Depth == 0 || uint(len(box.queue)) < p.Depth {
+		box.queue = append(box.queue, m)
+		return mail{}, true
+	}
+
+	switch p.Overflow {
+	case options.DropNewest:
+		return m, true
+
+	case options.DropOldest:
+		dropped = box.queue[0]
+		box.queue = append(box.queue[1:], m)
+		return dropped, true
+	}
+
+	return mail{}, false
+}
+
+// drain handles each of the notifications in box, until it is empty.
+func (l *listener) drain(id ident.SessionID, box *mailbox) {
+	for {
+		l.boxMutex.Lock()
+
+		if len(box.queue) == 0 {
+			box.running = false
+			delete(l.mailboxes, id)
+			l.boxMutex.Unlock()
+			return
+		}
+
+		m := box.queue[0]
+		box.queue[0] = mail{}
+		box.queue = box.queue[1:]
+
+		// move the oldest held notification into the space that was made
+		if len(box.waiting) != 0 {
+			box.queue = append(box.queue, box.waiting[0])
+			box.waiting[0] = mail{}
+			box.waiting = box.waiting[1:]
+		}
+
+		l.boxMutex.Unlock()
+
+		handled, panicked := l.handle(
+			m.d.ctx,
+			m.sess,
+			m.d.proto,
+			m.d.spanOpts,
+		)
+
+		l.release(m.d, handled, panicked)
+	}
+}
+
+// release records that a session is finished with d, either because its
+// handler was invoked, or the notification was discarded. It completes the
+// delivery once no sessions remain.
+func (l *listener) release(d *delivery, handled, panicked bool) {
+	d.mutex.Lock()
+	d.remaining--
+	d.panicked = d.panicked || panicked
+	if handled {
+		d.handled++
+	}
+	done := d.remaining == 0
+	d.mutex.Unlock()
+
+	if done {
+		l.complete(d, nil)
+	}
+}
+
+// complete acknowledges the AMQP message of d, or rejects it if err is non-nil
+// or a handler panicked. A receipt is sent if one was requested and the
+// notification was valid.
+func (l *listener) complete(d *delivery, err error) {
+	if err == nil && unpackReceiptRequest(d.msg) {
+		l.sendReceipt(d)
+	}
+
+	if err == nil && d.panicked {
+		err = errHandlerPanicked
+	}
+
+	if err == nil {
+		_ = d.msg.Ack(false) // false = single message
+	} else {
+		_ = d.msg.Reject(false) // false = don't requeue
+		logIgnoredMessage(l.logger, l.peerID, d.proto.ID, err)
+	}
+
+	if d.proto.Payload != nil {
+		d.proto.Payload.Close()
+	}
+
+	_ = l.sm.DoGraceful(func() error {
+		l.pending--
+		return nil
+	})
+}
+
+// sendReceipt publishes a receipt for d to the peer that sent the
+// notification.
+//
+// Receipts for multicast notifications are only sent if at least one session
+// handled the notification, as the sender only needs the total.
+func (l *listener) sendReceipt(d *delivery) {
+	if d.proto.IsMulticast && d.handled == 0 {
+		return
+	}
+
+	l.publishReceipt(d.proto.ID, receipt{
+		count:    d.handled,
+		notFound: d.notFound,
+	})
+}
+
+// publishReceipt publishes the receipt r for the message with the given ID to
+// the peer that sent it.
+func (l *listener) publishReceipt(msgID ident.MessageID, r receipt) {
+	key := msgID.String() // routed to the sender's peer
+
+	msg := amqp.Publishing{
+		MessageId: key,
+	}
+
+	packReceipt(&msg, r)
+
+	channel, err := l.channels.Get()
+	if err == nil {
+		defer l.channels.Put(channel)
+
+		err = channel.Publish(
+			receiptExchange,
+			key,
+			false, // mandatory
+			false, // immediate
+			msg,
+		)
+	}
+
+	if err != nil {
+		logReceiptError(l.logger, l.peerID, msgID, err)
+	}
+}
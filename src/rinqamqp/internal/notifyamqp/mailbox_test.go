@@ -0,0 +1,58 @@
+package notifyamqp_test
+
+import (
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/rinq/rinq-go/src/rinq/options"
+	. "github.com/rinq/rinq-go/src/rinqamqp/internal/notifyamqp"
+)
+
+var _ = DescribeTable(
+	"mailbox overflow",
+	func(p options.MailboxPolicy, queue, dropped, blocked []string) {
+		subject := NewMailbox(p)
+
+		var d, b []string
+
+		for _, t := range []string{"1", "2", "3", "4"} {
+			x, ok := subject.Push(t)
+			if !ok {
+				b = append(b, t)
+			} else if x != "" {
+				d = append(d, x)
+			}
+		}
+
+		Expect(subject.Queue()).To(Equal(queue))
+		Expect(d).To(Equal(dropped))
+		Expect(b).To(Equal(blocked))
+	},
+	Entry(
+		"unlimited depth",
+		options.MailboxPolicy{},
+		[]string{"1", "2", "3", "4"},
+		nil,
+		nil,
+	),
+	Entry(
+		"block on overflow",
+		options.MailboxPolicy{Depth: 2, Overflow: options.BlockOnOverflow},
+		[]string{"1", "2"},
+		nil,
+		[]string{"3", "4"},
+	),
+	Entry(
+		"drop newest",
+		options.MailboxPolicy{Depth: 2, Overflow: options.DropNewest},
+		[]string{"1", "2"},
+		[]string{"3", "4"},
+		nil,
+	),
+	Entry(
+		"drop oldest",
+		options.MailboxPolicy{Depth: 2, Overflow: options.DropOldest},
+		[]string{"3", "4"},
+		[]string{"1", "2"},
+		nil,
+	),
+)
@@ -2,6 +2,8 @@ package notifyamqp
 
 import (
 	"errors"
+	"hash/fnv"
+	"strconv"
 
 	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
@@ -24,10 +26,66 @@ const (
 
 	// constraintHeader specifies the constraint for multicast notifications.
 	constraintHeader = "c"
+
+	// targetsHeader specifies the sequence numbers of the target sessions for
+	// unicast notifications that are sent to more than one session on the
+	// same peer.
+	targetsHeader = "ts"
+
+	// topicHeader specifies the topic of a publication. It is only present in
+	// publications, distinguishing them from notifications.
+	topicHeader = "tp"
 )
 
-func unicastRoutingKey(ns string, p ident.PeerID) string {
-	return ns + "." + p.String()
+// multicastRoutingKey returns the routing key used to publish a notification
+// of type t in the ns namespace to the multicast exchange.
+//
+// The key consists of the segments of the namespace followed by a single
+// segment that represents the type, allowing listeners to bind using
+// namespace patterns, with or without a specific type.
+func multicastRoutingKey(ns, t string) string {
+	return ns + "." + typeSegment(t)
+}
+
+// unicastRoutingKey returns the routing key used to publish a notification of
+// type t in the ns namespace to the unicast exchange, for a session owned by
+// the peer p.
+func unicastRoutingKey(ns, t string, p ident.PeerID) string {
+	return unicastBindingKey(multicastRoutingKey(ns, t), p)
+}
+
+// unicastBindingKey returns the unicast equivalent of the multicast routing or
+// binding key k, for sessions owned by the peer p.
+func unicastBindingKey(k string, p ident.PeerID) string {
+	return p.String() + "." + k
+}
+
+// typeSegment returns the routing key segment that represents the
+// notification type t.
+//
+// Notification types may contain any character, including the periods and
+// wildcards that have special meaning in topic routing keys, so the type is
+// represented by its hash. Listeners discard notifications of types they did
+// not subscribe to, in the event of a collision.
+func typeSegment(t string) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(t))
+
+	return "=" + strconv.FormatUint(h.Sum64(), 16)
+}
+
+// anyTypeSegment is the binding key segment that matches notifications of any
+// type.
+const anyTypeSegment = "*"
+
+// topicRoutingKey returns the routing key used to publish a message to topic
+// via the multicast exchange.
+//
+// The key begins with a reserved segment, which can not be matched by the
+// binding keys used for notifications, as namespaces and namespace patterns
+// can not begin with an underscore or a wildcard.
+func topicRoutingKey(topic string) string {
+	return "_topic." + topic
 }
 
 func packCommonAttributes(
@@ -79,6 +137,67 @@ func unpackTarget(msg *amqp.Delivery) (id ident.SessionID, err error) {
 	return
 }
 
+func packTopic(msg *amqp.Publishing, topic string, p *rinq.Payload) {
+	msg.Body = p.Bytes()
+
+	if msg.Headers == nil {
+		msg.Headers = amqp.Table{}
+	}
+
+	msg.Headers[topicHeader] = topic
+}
+
+// unpackTopic returns the topic of a publication. ok is false if msg is not a
+// publication.
+func unpackTopic(msg *amqp.Delivery) (topic string, ok bool) {
+	topic, ok = msg.Headers[topicHeader].(string)
+	return
+}
+
+func packTargets(msg *amqp.Publishing, seqs map[uint32]struct{}) {
+	if msg.Headers == nil {
+		msg.Headers = amqp.Table{}
+	}
+
+	list := make([]uint32, 0, len(seqs))
+	for seq := range seqs {
+		list = append(list, seq)
+	}
+
+	// don't return buf to the pool as it's internal buffer is retained inside
+	// the msg header.
+	buf := bufferpool.Get()
+	cbor.MustEncode(buf, list)
+
+	msg.Headers[targetsHeader] = buf.Bytes()
+}
+
+// unpackTargets returns the target sessions of a unicast notification, which
+// are owned by the peer p.
+func unpackTargets(msg *amqp.Delivery, p ident.PeerID) ([]ident.SessionID, error) {
+	buf, ok := msg.Headers[targetsHeader].([]byte)
+	if !ok {
+		id, err := unpackTarget(msg)
+		if err != nil {
+			return nil, err
+		}
+
+		return []ident.SessionID{id}, nil
+	}
+
+	var seqs []uint32
+	if err := cbor.DecodeBytes(buf, &seqs); err != nil {
+		return nil, err
+	}
+
+	ids := make([]ident.SessionID, len(seqs))
+	for i, seq := range seqs {
+		ids[i] = p.Session(seq)
+	}
+
+	return ids, nil
+}
+
 func packConstraint(msg *amqp.Publishing, con constraint.Constraint) {
 	if msg.Headers == nil {
 		msg.Headers = amqp.Table{}
@@ -92,6 +211,16 @@ func packConstraint(msg *amqp.Publishing, con constraint.Constraint) {
 	msg.Headers[constraintHeader] = buf.Bytes()
 }
 
+func packHints(msg *amqp.Publishing, hints []string) {
+	if msg.Headers == nil {
+		msg.Headers = amqp.Table{}
+	}
+
+	for _, h := range hints {
+		msg.Headers[h] = true
+	}
+}
+
 func unpackConstraint(msg *amqp.Delivery) (con constraint.Constraint, err error) {
 	if buf, ok := msg.Headers[constraintHeader].([]byte); ok {
 		err = cbor.DecodeBytes(buf, &con)
@@ -2,6 +2,7 @@ package notifyamqp
 
 import (
 	"context"
+	"time"
 
 	"github.com/jmalloc/twelf/src/twelf"
 	"github.com/rinq/rinq-go/src/internal/notify"
@@ -17,21 +18,33 @@ type notifier struct {
 	service.Service
 	sm *service.StateMachine
 
-	peerID   ident.PeerID
-	channels amqputil.ChannelPool
-	logger   twelf.Logger
+	peerID         ident.PeerID
+	channels       amqputil.ChannelPool
+	receipts       *receiptTracker
+	retained       *retainer // nil if retention is disabled
+	defaultTimeout time.Duration
+	logger         twelf.Logger
+	filtering      bool // publish multicast notifications with hints, when possible
 }
 
 // newNotifier creates, initializes and returns a new notifier.
 func newNotifier(
 	peerID ident.PeerID,
 	channels amqputil.ChannelPool,
+	receipts *receiptTracker,
+	retained *retainer,
+	defaultTimeout time.Duration,
 	logger twelf.Logger,
+	filtering bool,
 ) notify.Notifier {
 	n := &notifier{
-		peerID:   peerID,
-		channels: channels,
-		logger:   logger,
+		peerID:         peerID,
+		channels:       channels,
+		receipts:       receipts,
+		retained:       retained,
+		defaultTimeout: defaultTimeout,
+		logger:         logger,
+		filtering:      filtering,
 	}
 
 	n.sm = service.NewStateMachine(n.run, n.finalize)
@@ -50,7 +63,216 @@ func (n *notifier) NotifyUnicast(
 	ns string,
 	notificationType string,
 	payload *rinq.Payload,
-) (err error) {
+) error {
+	return n.unicast(ctx, msgID, traceID, target, ns, notificationType, payload, false)
+}
+
+func (n *notifier) NotifyMulticast(
+	ctx context.Context,
+	msgID ident.MessageID,
+	traceID string,
+	con constraint.Constraint,
+	ns string,
+	notificationType string,
+	payload *rinq.Payload,
+) error {
+	return n.multicast(ctx, msgID, traceID, con, ns, notificationType, payload, false, 0)
+}
+
+func (n *notifier) NotifyMulticastRetained(
+	ctx context.Context,
+	msgID ident.MessageID,
+	traceID string,
+	con constraint.Constraint,
+	ns string,
+	notificationType string,
+	ttl time.Duration,
+	payload *rinq.Payload,
+) error {
+	return n.multicast(ctx, msgID, traceID, con, ns, notificationType, payload, false, ttl)
+}
+
+func (n *notifier) NotifyEach(
+	ctx context.Context,
+	msgID ident.MessageID,
+	traceID string,
+	targets []ident.SessionID,
+	ns string,
+	notificationType string,
+	payload *rinq.Payload,
+) error {
+	// group the targets by the peer that owns them, removing duplicates
+	peers := map[ident.PeerID]map[uint32]struct{}{}
+	for _, target := range targets {
+		seqs, ok := peers[target.Peer]
+		if !ok {
+			seqs = map[uint32]struct{}{}
+			peers[target.Peer] = seqs
+		}
+
+		seqs[target.Seq] = struct{}{}
+	}
+
+	// the encoded payload is shared by the message sent to each peer
+	proto := amqp.Publishing{
+		MessageId: msgID.String(),
+	}
+
+	packCommonAttributes(&proto, traceID, ns, notificationType, payload)
+
+	if err := amqputil.PackSpanContext(ctx, &proto); err != nil {
+		return err
+	}
+
+	for peerID, seqs := range peers {
+		msg := proto
+		msg.Headers = amqp.Table{}
+		for k, v := range proto.Headers {
+			msg.Headers[k] = v
+		}
+
+		packTargets(&msg, seqs)
+
+		if err := n.send(
+			unicastExchange,
+			unicastRoutingKey(ns, notificationType, peerID),
+			msg,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (n *notifier) NotifyUnicastConfirmed(
+	ctx context.Context,
+	msgID ident.MessageID,
+	traceID string,
+	target ident.SessionID,
+	ns string,
+	notificationType string,
+	payload *rinq.Payload,
+) (bool, error) {
+	ctx, cancel := n.withDeadline(ctx)
+	defer cancel()
+
+	arrived := n.receipts.Track(msgID)
+
+	if err := n.unicast(ctx, msgID, traceID, target, ns, notificationType, payload, true); err != nil {
+		n.receipts.Untrack(msgID)
+		return false, err
+	}
+
+	select {
+	case <-arrived:
+	case <-ctx.Done():
+		n.receipts.Untrack(msgID)
+		return false, ctx.Err()
+	case <-n.sm.Forceful:
+		n.receipts.Untrack(msgID)
+		return false, context.Canceled
+	}
+
+	r := n.receipts.Untrack(msgID)
+
+	if r.notFound {
+		return false, rinq.NotFoundError{ID: target}
+	}
+
+	return r.count != 0, nil
+}
+
+func (n *notifier) NotifyMulticastConfirmed(
+	ctx context.Context,
+	msgID ident.MessageID,
+	traceID string,
+	con constraint.Constraint,
+	ns string,
+	notificationType string,
+	payload *rinq.Payload,
+) (int, error) {
+	ctx, cancel := n.withDeadline(ctx)
+	defer cancel()
+
+	n.receipts.Track(msgID)
+
+	if err := n.multicast(ctx, msgID, traceID, con, ns, notificationType, payload, true, 0); err != nil {
+		n.receipts.Untrack(msgID)
+		return 0, err
+	}
+
+	// the number of peers that will send receipts is not known, so receipts
+	// are collected until the deadline
+	var err error
+
+	select {
+	case <-ctx.Done():
+		if ctx.Err() != context.DeadlineExceeded {
+			err = ctx.Err()
+		}
+	case <-n.sm.Forceful:
+		err = context.Canceled
+	}
+
+	return n.receipts.Untrack(msgID).count, err
+}
+
+func (n *notifier) Publish(
+	ctx context.Context,
+	msgID ident.MessageID,
+	traceID string,
+	topic string,
+	payload *rinq.Payload,
+) error {
+	return n.publish(ctx, msgID, traceID, topic, payload, false)
+}
+
+func (n *notifier) PublishConfirmed(
+	ctx context.Context,
+	msgID ident.MessageID,
+	traceID string,
+	topic string,
+	payload *rinq.Payload,
+) (int, error) {
+	ctx, cancel := n.withDeadline(ctx)
+	defer cancel()
+
+	n.receipts.Track(msgID)
+
+	if err := n.publish(ctx, msgID, traceID, topic, payload, true); err != nil {
+		n.receipts.Untrack(msgID)
+		return 0, err
+	}
+
+	// as per NotifyMulticastConfirmed(), the number of subscribers is not
+	// known, so receipts are collected until the deadline
+	var err error
+
+	select {
+	case <-ctx.Done():
+		if ctx.Err() != context.DeadlineExceeded {
+			err = ctx.Err()
+		}
+	case <-n.sm.Forceful:
+		err = context.Canceled
+	}
+
+	return n.receipts.Untrack(msgID).count, err
+}
+
+// unicast publishes a notification to a specific session, requesting a
+// receipt if confirm is true.
+func (n *notifier) unicast(
+	ctx context.Context,
+	msgID ident.MessageID,
+	traceID string,
+	target ident.SessionID,
+	ns string,
+	notificationType string,
+	payload *rinq.Payload,
+	confirm bool,
+) error {
 	msg := amqp.Publishing{
 		MessageId: msgID.String(),
 	}
@@ -58,16 +280,22 @@ func (n *notifier) NotifyUnicast(
 	packCommonAttributes(&msg, traceID, ns, notificationType, payload)
 	packTarget(&msg, target)
 
-	err = amqputil.PackSpanContext(ctx, &msg)
+	if confirm {
+		packReceiptRequest(&msg)
+	}
 
-	if err == nil {
-		err = n.send(unicastExchange, unicastRoutingKey(ns, target.Peer), msg)
+	if err := amqputil.PackSpanContext(ctx, &msg); err != nil {
+		return err
 	}
 
-	return
+	return n.send(unicastExchange, unicastRoutingKey(ns, notificationType, target.Peer), msg)
 }
 
-func (n *notifier) NotifyMulticast(
+// multicast publishes a notification to the sessions that match con,
+// requesting receipts if confirm is true. If retain is non-zero, the
+// notification is replayed to sessions that start listening before retain
+// elapses.
+func (n *notifier) multicast(
 	ctx context.Context,
 	msgID ident.MessageID,
 	traceID string,
@@ -75,7 +303,9 @@ func (n *notifier) NotifyMulticast(
 	ns string,
 	notificationType string,
 	payload *rinq.Payload,
-) (err error) {
+	confirm bool,
+	retain time.Duration,
+) error {
 	msg := amqp.Publishing{
 		MessageId: msgID.String(),
 	}
@@ -83,13 +313,64 @@ func (n *notifier) NotifyMulticast(
 	packCommonAttributes(&msg, traceID, ns, notificationType, payload)
 	packConstraint(&msg, con)
 
-	err = amqputil.PackSpanContext(ctx, &msg)
+	if confirm {
+		packReceiptRequest(&msg)
+	}
+
+	if err := amqputil.PackSpanContext(ctx, &msg); err != nil {
+		return err
+	}
+
+	if retain != 0 && n.retained != nil {
+		n.retained.Retain(ns, notificationType, con, msg, retain)
+	}
+
+	if n.filtering {
+		if hints := multicastHints(ns, con); hints != nil {
+			packHints(&msg, hints)
+			return n.send(multicastHintExchange, multicastRoutingKey(ns, notificationType), msg)
+		}
+	}
+
+	return n.send(multicastExchange, multicastRoutingKey(ns, notificationType), msg)
+}
+
+// publish sends a message to the peers that are subscribed to topic,
+// requesting receipts if confirm is true.
+func (n *notifier) publish(
+	ctx context.Context,
+	msgID ident.MessageID,
+	traceID string,
+	topic string,
+	payload *rinq.Payload,
+	confirm bool,
+) error {
+	msg := amqp.Publishing{
+		MessageId: msgID.String(),
+	}
+
+	packTopic(&msg, topic, payload)
+	amqputil.PackTrace(&msg, traceID)
+
+	if confirm {
+		packReceiptRequest(&msg)
+	}
+
+	if err := amqputil.PackSpanContext(ctx, &msg); err != nil {
+		return err
+	}
+
+	return n.send(multicastExchange, topicRoutingKey(topic), msg)
+}
 
-	if err == nil {
-		err = n.send(multicastExchange, ns, msg)
+// withDeadline returns a context with the default timeout if ctx does not
+// already have a deadline.
+func (n *notifier) withDeadline(ctx context.Context) (context.Context, func()) {
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
 	}
 
-	return
+	return context.WithTimeout(ctx, n.defaultTimeout)
 }
 
 func (n *notifier) send(exchange, key string, msg amqp.Publishing) error {
@@ -0,0 +1,155 @@
+package notifyamqp
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/streadway/amqp"
+)
+
+const (
+	// receiptRequestHeader is present in notifications for which the sender
+	// has requested a receipt.
+	receiptRequestHeader = "r"
+
+	// receiptCountHeader specifies the number of sessions that handled the
+	// notification in a receipt.
+	receiptCountHeader = "rn"
+)
+
+const (
+	// handledReceipt is the message type of a receipt that reports the number
+	// of sessions that handled a notification.
+	handledReceipt = "handled"
+
+	// notFoundReceipt is the message type of a receipt that reports that the
+	// target of a unicast notification does not exist.
+	notFoundReceipt = "not-found"
+)
+
+// receipt is the combined content of the receipts received for a single
+// notification.
+type receipt struct {
+	count    int  // number of sessions that handled the notification
+	notFound bool // true if the target session does not exist
+}
+
+// pendingReceipt is a notification that is awaiting receipts.
+type pendingReceipt struct {
+	receipt
+	arrived chan struct{} // closed when the first receipt arrives
+}
+
+// receiptTracker correlates incoming receipts with the notifications that
+// requested them. It is shared by the notifier, which sends the notifications,
+// and the listener, which consumes the receipts.
+type receiptTracker struct {
+	mutex   sync.Mutex
+	pending map[string]*pendingReceipt
+}
+
+func newReceiptTracker() *receiptTracker {
+	return &receiptTracker{
+		pending: map[string]*pendingReceipt{},
+	}
+}
+
+// Track begins accumulating receipts for the notification with the given ID.
+// The returned channel is closed when the first receipt arrives.
+func (t *receiptTracker) Track(msgID ident.MessageID) <-chan struct{} {
+	p := &pendingReceipt{
+		arrived: make(chan struct{}),
+	}
+
+	t.mutex.Lock()
+	t.pending[msgID.String()] = p
+	t.mutex.Unlock()
+
+	return p.arrived
+}
+
+// Untrack stops accumulating receipts for the notification with the given ID,
+// and returns the receipts received so far. Receipts that arrive later are
+// discarded.
+func (t *receiptTracker) Untrack(msgID ident.MessageID) receipt {
+	key := msgID.String()
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	p := t.pending[key]
+	delete(t.pending, key)
+
+	return p.receipt
+}
+
+// Deliver adds the receipt in msg to the receipts for its notification. It
+// returns false if the notification is not being tracked.
+func (t *receiptTracker) Deliver(msg *amqp.Delivery) (bool, error) {
+	r, err := unpackReceipt(msg)
+	if err != nil {
+		return false, err
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	p, ok := t.pending[msg.MessageId]
+	if !ok {
+		return false, nil
+	}
+
+	p.count += r.count
+	p.notFound = p.notFound || r.notFound
+
+	select {
+	case <-p.arrived:
+	default:
+		close(p.arrived)
+	}
+
+	return true, nil
+}
+
+func packReceiptRequest(msg *amqp.Publishing) {
+	if msg.Headers == nil {
+		msg.Headers = amqp.Table{}
+	}
+
+	msg.Headers[receiptRequestHeader] = true
+}
+
+func unpackReceiptRequest(msg *amqp.Delivery) bool {
+	r, _ := msg.Headers[receiptRequestHeader].(bool)
+	return r
+}
+
+func packReceipt(msg *amqp.Publishing, r receipt) {
+	if r.notFound {
+		msg.Type = notFoundReceipt
+		return
+	}
+
+	msg.Type = handledReceipt
+	msg.Headers = amqp.Table{
+		receiptCountHeader: int32(r.count),
+	}
+}
+
+func unpackReceipt(msg *amqp.Delivery) (r receipt, err error) {
+	switch msg.Type {
+	case notFoundReceipt:
+		r.notFound = true
+	case handledReceipt:
+		if n, ok := msg.Headers[receiptCountHeader].(int32); ok {
+			r.count = int(n)
+		} else {
+			err = errors.New("receipt count header is not an integer")
+		}
+	default:
+		err = errors.New("receipt type is not recognized")
+	}
+
+	return
+}
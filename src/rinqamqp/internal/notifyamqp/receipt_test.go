@@ -0,0 +1,76 @@
+package notifyamqp_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+	. "github.com/rinq/rinq-go/src/rinqamqp/internal/notifyamqp"
+	"github.com/streadway/amqp"
+)
+
+var _ = Describe("receiptTracker", func() {
+	var msgID ident.MessageID
+
+	BeforeEach(func() {
+		msgID = ident.MessageID{
+			Ref: ident.NewPeerID().Session(1).At(0),
+			Seq: 1,
+		}
+	})
+
+	It("combines the receipts delivered for a notification", func() {
+		subject := NewReceiptTracker()
+		arrived := subject.Track(msgID)
+
+		ok, err := subject.Deliver(NewReceipt(msgID, 2, false))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(arrived).To(BeClosed())
+
+		ok, err = subject.Deliver(NewReceipt(msgID, 3, false))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+
+		count, notFound := UntrackReceipt(subject, msgID)
+		Expect(count).To(Equal(5))
+		Expect(notFound).To(BeFalse())
+	})
+
+	It("reports not found receipts", func() {
+		subject := NewReceiptTracker()
+		arrived := subject.Track(msgID)
+
+		ok, err := subject.Deliver(NewReceipt(msgID, 0, true))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(arrived).To(BeClosed())
+
+		_, notFound := UntrackReceipt(subject, msgID)
+		Expect(notFound).To(BeTrue())
+	})
+
+	It("does not close the arrival channel before a receipt arrives", func() {
+		subject := NewReceiptTracker()
+		arrived := subject.Track(msgID)
+
+		Expect(arrived).NotTo(BeClosed())
+	})
+
+	It("discards receipts for notifications that are not tracked", func() {
+		subject := NewReceiptTracker()
+		subject.Track(msgID)
+		UntrackReceipt(subject, msgID)
+
+		ok, err := subject.Deliver(NewReceipt(msgID, 1, false))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeFalse())
+	})
+
+	It("returns an error if the receipt type is not recognized", func() {
+		subject := NewReceiptTracker()
+		subject.Track(msgID)
+
+		_, err := subject.Deliver(&amqp.Delivery{MessageId: msgID.String(), Type: "unknown"})
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,272 @@
+package notifyamqp
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/rinq/rinq-go/src/internal/namespaces"
+	"github.com/rinq/rinq-go/src/internal/x/bufferpool"
+	"github.com/rinq/rinq-go/src/internal/x/cbor"
+	"github.com/rinq/rinq-go/src/rinq/constraint"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/streadway/amqp"
+)
+
+const (
+	// replayRoutingKey is the routing key used to publish replay requests to
+	// the multicast exchange. Every peer binds to it, as any peer may have
+	// retained notifications for the listening session.
+	//
+	// As per topicRoutingKey(), the key can not be matched by the binding keys
+	// used for notifications.
+	replayRoutingKey = "_replay"
+
+	// typesHeader specifies the notification types that the session accepts
+	// in replay requests. It is omitted if the session accepts all types.
+	typesHeader = "ty"
+
+	// exceptTypesHeader specifies the notification types that are not
+	// replayed in replay requests, typically because they have already been
+	// replayed to the session. It is omitted if there are no such types.
+	exceptTypesHeader = "tx"
+
+	// maxRetained is the maximum number of notifications retained by a peer.
+	// Once it is reached, the notification that is closest to expiry is
+	// discarded to make room for each new combination of namespace, type and
+	// constraint.
+	maxRetained = 10000
+
+	// retainedPruneInterval is the interval at which expired notifications are
+	// discarded when new notifications are retained.
+	retainedPruneInterval = 1 * time.Minute
+)
+
+// retainedKey identifies the notifications that replace each other when they
+// are retained.
+type retainedKey struct {
+	ns  string
+	t   string
+	con string
+}
+
+// retainedNotification is a multicast notification that is replayed to
+// sessions that start listening after it was sent.
+type retainedNotification struct {
+	msg       amqp.Publishing
+	expiresAt time.Time
+}
+
+// retainer keeps the latest retained notification for each combination of
+// namespace, type and constraint. It is shared by the notifier, which retains
+// notifications as they are sent, and the listener, which replays them in
+// response to replay requests.
+type retainer struct {
+	limit int
+
+	mutex         sync.Mutex
+	notifications map[retainedKey]retainedNotification
+	pruneAt       time.Time // the time at which expired notifications are next discarded
+}
+
+// newRetainer returns a retainer that retains at most limit notifications.
+func newRetainer(limit int) *retainer {
+	return &retainer{
+		limit:         limit,
+		notifications: map[retainedKey]retainedNotification{},
+	}
+}
+
+// Retain stores msg as the latest notification of type t in the ns namespace
+// for the constraint con, replacing any notification previously retained for
+// the same combination. It expires after ttl.
+//
+// If the retainer is full, the notification that is closest to expiry is
+// discarded to make room for msg.
+func (r *retainer) Retain(
+	ns string,
+	t string,
+	con constraint.Constraint,
+	msg amqp.Publishing,
+	ttl time.Duration,
+) {
+	now := time.Now()
+	key := retainedKey{ns, t, con.String()}
+
+	n := retainedNotification{
+		msg:       cloneMessage(msg),
+		expiresAt: now.Add(ttl),
+	}
+
+	// the body is typically the internal buffer of a payload, which is
+	// returned to the pool when the payload is closed
+	n.msg.Body = append([]byte(nil), msg.Body...)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	_, exists := r.notifications[key]
+	isFull := !exists && len(r.notifications) >= r.limit
+
+	if isFull || now.After(r.pruneAt) {
+		r.prune(now)
+
+		if isFull && len(r.notifications) >= r.limit {
+			r.evict()
+		}
+	}
+
+	r.notifications[key] = n
+}
+
+// Matching returns the retained notifications in namespaces that match the
+// pattern p, with one of the given types, and none of the types in except. If
+// types is empty, notifications of any type not in except are returned.
+//
+// Expired notifications are discarded.
+func (r *retainer) Matching(p string, types, except []string) []amqp.Publishing {
+	now := time.Now()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var matches []amqp.Publishing
+
+	r.prune(now)
+
+	for key, n := range r.notifications {
+		if namespaces.Match(p, key.ns) && matchesType(types, except, key.t) {
+			matches = append(matches, cloneMessage(n.msg))
+		}
+	}
+
+	return matches
+}
+
+// prune discards the notifications that have expired as of now. It assumes
+// r.mutex is already locked.
+func (r *retainer) prune(now time.Time) {
+	for key, n := range r.notifications {
+		if now.After(n.expiresAt) {
+			delete(r.notifications, key)
+		}
+	}
+
+	r.pruneAt = now.Add(retainedPruneInterval)
+}
+
+// evict discards the notification that is closest to expiry. It assumes
+// r.mutex is already locked.
+func (r *retainer) evict() {
+	var (
+		evictKey retainedKey
+		evictAt  time.Time
+	)
+
+	for key, n := range r.notifications {
+		if evictAt.IsZero() || n.expiresAt.Before(evictAt) {
+			evictKey = key
+			evictAt = n.expiresAt
+		}
+	}
+
+	delete(r.notifications, evictKey)
+}
+
+// matchesType returns true if t is one of types, or types is empty, and t is
+// not one of except.
+func matchesType(types, except []string, t string) bool {
+	for _, x := range except {
+		if x == t {
+			return false
+		}
+	}
+
+	if len(types) == 0 {
+		return true
+	}
+
+	for _, x := range types {
+		if x == t {
+			return true
+		}
+	}
+
+	return false
+}
+
+// cloneMessage returns a copy of msg with its own header table, so that
+// headers can be added without affecting msg.
+func cloneMessage(msg amqp.Publishing) amqp.Publishing {
+	headers := amqp.Table{}
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+
+	msg.Headers = headers
+
+	return msg
+}
+
+func packReplayRequest(
+	msg *amqp.Publishing,
+	target ident.SessionID,
+	ns string,
+	types []string,
+	except []string,
+) {
+	if msg.Headers == nil {
+		msg.Headers = amqp.Table{}
+	}
+
+	msg.Headers[namespaceHeader] = ns
+	packTarget(msg, target)
+	packTypes(msg, typesHeader, types)
+	packTypes(msg, exceptTypesHeader, except)
+}
+
+// packTypes adds a header containing the given notification types to msg,
+// unless types is empty.
+func packTypes(msg *amqp.Publishing, header string, types []string) {
+	if len(types) == 0 {
+		return
+	}
+
+	// don't return buf to the pool as it's internal buffer is retained
+	// inside the msg header.
+	buf := bufferpool.Get()
+	cbor.MustEncode(buf, types)
+
+	msg.Headers[header] = buf.Bytes()
+}
+
+func unpackReplayRequest(msg *amqp.Delivery) (
+	target ident.SessionID,
+	ns string,
+	types []string,
+	except []string,
+	err error,
+) {
+	target, err = unpackTarget(msg)
+	if err != nil {
+		return
+	}
+
+	ns, ok := msg.Headers[namespaceHeader].(string)
+	if !ok {
+		err = errors.New("namespace header is not a string")
+		return
+	}
+
+	if buf, ok := msg.Headers[typesHeader].([]byte); ok {
+		if err = cbor.DecodeBytes(buf, &types); err != nil {
+			return
+		}
+	}
+
+	if buf, ok := msg.Headers[exceptTypesHeader].([]byte); ok {
+		err = cbor.DecodeBytes(buf, &except)
+	}
+
+	return
+}
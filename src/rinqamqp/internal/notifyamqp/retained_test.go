@@ -0,0 +1,133 @@
+package notifyamqp_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/rinq/rinq-go/src/rinq/constraint"
+	. "github.com/rinq/rinq-go/src/rinqamqp/internal/notifyamqp"
+	"github.com/streadway/amqp"
+)
+
+var _ = Describe("retainer", func() {
+	var subject = NewRetainer(100)
+
+	BeforeEach(func() {
+		subject = NewRetainer(100)
+	})
+
+	// retain retains a notification on subject that is identified by its
+	// namespace and type.
+	retain := func(ns, t, body string, ttl time.Duration) {
+		subject.Retain(
+			ns,
+			t,
+			constraint.None,
+			amqp.Publishing{MessageId: ns + "::" + t, Body: []byte(body)},
+			ttl,
+		)
+	}
+
+	// ids returns the message IDs of msgs.
+	ids := func(msgs []amqp.Publishing) []string {
+		var result []string
+		for _, msg := range msgs {
+			result = append(result, msg.MessageId)
+		}
+
+		return result
+	}
+
+	Describe("Retain", func() {
+		It("discards expired notifications to make room when full", func() {
+			subject = NewRetainer(2)
+
+			retain("ns", "a", "", time.Millisecond)
+			retain("ns", "b", "", time.Minute)
+
+			time.Sleep(5 * time.Millisecond)
+
+			retain("ns", "c", "", time.Minute)
+
+			Expect(RetainedCount(subject)).To(Equal(2))
+			Expect(ids(subject.Matching("ns", nil, nil))).To(ConsistOf("ns::b", "ns::c"))
+		})
+
+		It("discards the notification that is closest to expiry when full", func() {
+			subject = NewRetainer(2)
+
+			retain("ns", "a", "", time.Hour)
+			retain("ns", "b", "", time.Minute)
+			retain("ns", "c", "", time.Hour)
+
+			Expect(ids(subject.Matching("ns", nil, nil))).To(ConsistOf("ns::a", "ns::c"))
+		})
+
+		It("does not discard other notifications when replacing one while full", func() {
+			subject = NewRetainer(2)
+
+			retain("ns", "a", "1", time.Hour)
+			retain("ns", "b", "", time.Minute)
+			retain("ns", "a", "2", time.Hour)
+
+			Expect(ids(subject.Matching("ns", nil, nil))).To(ConsistOf("ns::a", "ns::b"))
+		})
+	})
+
+	Describe("Matching", func() {
+		DescribeTable(
+			"returns the notifications that match the namespace pattern and types",
+			func(p string, types, except, expected []string) {
+				retain("a.b", "x", "", time.Minute)
+				retain("a.b", "y", "", time.Minute)
+				retain("a.c", "x", "", time.Minute)
+				retain("other", "x", "", time.Minute)
+
+				Expect(ids(subject.Matching(p, types, except))).To(ConsistOf(expected))
+			},
+			Entry("namespace", "a.b", nil, nil, []string{"a.b::x", "a.b::y"}),
+			Entry("pattern", "a.*", nil, nil, []string{"a.b::x", "a.b::y", "a.c::x"}),
+			Entry("types", "a.*", []string{"x"}, nil, []string{"a.b::x", "a.c::x"}),
+			Entry("excluded types", "a.*", nil, []string{"x"}, []string{"a.b::y"}),
+			Entry("types and excluded types", "a.b", []string{"x", "y"}, []string{"y"}, []string{"a.b::x"}),
+			Entry("no matches", "none", nil, nil, []string{}),
+		)
+
+		It("returns only the latest notification for each namespace, type and constraint", func() {
+			retain("ns", "t", "1", time.Minute)
+			retain("ns", "t", "2", time.Minute)
+
+			msgs := subject.Matching("ns", nil, nil)
+
+			Expect(msgs).To(HaveLen(1))
+			Expect(msgs[0].Body).To(Equal([]byte("2")))
+		})
+
+		It("retains notifications with different constraints separately", func() {
+			msg := amqp.Publishing{MessageId: "ns::t"}
+			subject.Retain("ns", "t", constraint.Equal("a", "1"), msg, time.Minute)
+			subject.Retain("ns", "t", constraint.Equal("a", "2"), msg, time.Minute)
+
+			Expect(subject.Matching("ns", nil, nil)).To(HaveLen(2))
+		})
+
+		It("does not return expired notifications", func() {
+			retain("ns", "t", "", time.Millisecond)
+
+			time.Sleep(5 * time.Millisecond)
+
+			Expect(subject.Matching("ns", nil, nil)).To(BeEmpty())
+		})
+
+		It("retains a copy of the message body", func() {
+			body := []byte("body")
+			subject.Retain("ns", "t", constraint.None, amqp.Publishing{Body: body}, time.Minute)
+
+			body[0] = 'x'
+
+			Expect(subject.Matching("ns", nil, nil)[0].Body).To(Equal([]byte("body")))
+		})
+	})
+})
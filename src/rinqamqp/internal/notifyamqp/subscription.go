@@ -0,0 +1,128 @@
+package notifyamqp
+
+import (
+	"github.com/rinq/rinq-go/src/internal/namespaces"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+)
+
+// subscription is a session's handler for notifications in the namespaces that
+// match a specific pattern.
+type subscription struct {
+	types   map[string]struct{} // accepted types, nil if all types are accepted
+	handler rinq.NotificationHandler
+}
+
+func newSubscription(types []string, h rinq.NotificationHandler) subscription {
+	sub := subscription{handler: h}
+
+	if len(types) != 0 {
+		sub.types = map[string]struct{}{}
+		for _, t := range types {
+			sub.types[t] = struct{}{}
+		}
+	}
+
+	return sub
+}
+
+// accepts returns true if the subscription accepts notifications of type t.
+func (sub subscription) accepts(t string) bool {
+	if sub.types == nil {
+		return true
+	}
+
+	_, ok := sub.types[t]
+	return ok
+}
+
+// hasSameTypes returns true if sub and o accept the same notification types.
+func (sub subscription) hasSameTypes(o subscription) bool {
+	if (sub.types == nil) != (o.types == nil) || len(sub.types) != len(o.types) {
+		return false
+	}
+
+	for t := range sub.types {
+		if _, ok := o.types[t]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// addedTypes returns the notification types accepted by sub that are not
+// accepted by prev. If sub accepts all types, types is nil and except contains
+// the types accepted by prev. ok is false if there are no such types.
+func (sub subscription) addedTypes(prev subscription) (types, except []string, ok bool) {
+	if sub.types == nil {
+		if prev.types == nil {
+			return nil, nil, false
+		}
+
+		for t := range prev.types {
+			except = append(except, t)
+		}
+
+		return nil, except, true
+	}
+
+	for t := range sub.types {
+		if !prev.accepts(t) {
+			types = append(types, t)
+		}
+	}
+
+	return types, nil, len(types) != 0
+}
+
+// bindingKeys returns the multicast binding keys for the notifications
+// accepted by the subscription, in the namespaces that match the pattern p.
+func (sub subscription) bindingKeys(p string) []string {
+	if sub.types == nil {
+		return []string{p + "." + anyTypeSegment}
+	}
+
+	keys := make([]string, 0, len(sub.types))
+	for t := range sub.types {
+		keys = append(keys, multicastRoutingKey(p, t))
+	}
+
+	return keys
+}
+
+// handler returns the handler that the session with the given ID uses for
+// notifications of type t in the ns namespace, or nil if there is none. It
+// assumes l.mutex is already locked.
+//
+// A subscription to the namespace itself is preferred over a pattern,
+// otherwise the longest matching pattern is used.
+func (l *listener) handler(id ident.SessionID, ns, t string) rinq.NotificationHandler {
+	subs := l.handlers[id]
+
+	if sub, ok := subs[ns]; ok && sub.accepts(t) {
+		return sub.handler
+	}
+
+	var (
+		match string
+		h     rinq.NotificationHandler
+	)
+
+	for p, sub := range subs {
+		if !namespaces.IsPattern(p) ||
+			!sub.accepts(t) ||
+			!namespaces.Match(p, ns) {
+			continue
+		}
+
+		if h == nil ||
+			len(p) > len(match) ||
+			len(p) == len(match) && p < match {
+			match = p
+			h = sub.handler
+		}
+	}
+
+	return h
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/jmalloc/twelf/src/twelf"
 	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
 	"github.com/rinq/rinq-go/src/internal/command"
 	"github.com/rinq/rinq-go/src/internal/localsession"
 	"github.com/rinq/rinq-go/src/internal/namespaces"
@@ -13,8 +14,10 @@ import (
 	"github.com/rinq/rinq-go/src/internal/opentr"
 	"github.com/rinq/rinq-go/src/internal/remotesession"
 	"github.com/rinq/rinq-go/src/internal/service"
+	"github.com/rinq/rinq-go/src/internal/watchdog"
 	"github.com/rinq/rinq-go/src/rinq"
 	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/options"
 	"github.com/rinq/rinq-go/src/rinq/trace"
 	"github.com/streadway/amqp"
 )
@@ -32,10 +35,13 @@ type peer struct {
 	server      command.Server
 	notifier    notify.Notifier
 	listener    notify.Listener
+	watchdog    *watchdog.Watchdog
 	logger      twelf.Logger
 	tracer      opentracing.Tracer
+	quota       options.QuotaPolicy
 
-	seq        uint32
+	seq        uint32 // session sequence number
+	msgSeq     uint32 // publication sequence number
 	amqpClosed chan *amqp.Error
 }
 
@@ -48,8 +54,10 @@ func newPeer(
 	server command.Server,
 	notifier notify.Notifier,
 	listener notify.Listener,
+	wd *watchdog.Watchdog,
 	logger twelf.Logger,
 	tracer opentracing.Tracer,
+	quota options.QuotaPolicy,
 ) *peer {
 	p := &peer{
 		id:          id,
@@ -60,8 +68,10 @@ func newPeer(
 		server:      server,
 		notifier:    notifier,
 		listener:    listener,
+		watchdog:    wd,
 		logger:      logger,
 		tracer:      tracer,
+		quota:       quota,
 
 		amqpClosed: make(chan *amqp.Error, 1),
 	}
@@ -92,6 +102,7 @@ func (p *peer) Session() rinq.Session {
 		p.listener,
 		p.logger,
 		p.tracer,
+		p.quota,
 	)
 
 	p.localStore.Add(sess)
@@ -103,9 +114,14 @@ func (p *peer) Session() rinq.Session {
 	return sess
 }
 
-func (p *peer) Listen(ns string, handler rinq.CommandHandler) error {
+func (p *peer) Listen(ns string, handler rinq.CommandHandler, opts ...options.ListenOption) error {
 	namespaces.MustValidate(ns)
 
+	lo, err := options.NewListenOptions(opts...)
+	if err != nil {
+		return err
+	}
+
 	added, err := p.server.Listen(
 		ns,
 		func(
@@ -139,6 +155,7 @@ func (p *peer) Listen(ns string, handler rinq.CommandHandler) error {
 				),
 			)
 		},
+		lo,
 	)
 
 	if added {
@@ -160,6 +177,113 @@ func (p *peer) Unlisten(ns string) error {
 	return err
 }
 
+func (p *peer) Publish(ctx context.Context, topic string, out *rinq.Payload) error {
+	namespaces.MustValidate(topic)
+
+	msgID, traceID := p.nextMessageID(ctx)
+
+	span, ctx := opentr.ChildOf(ctx, p.tracer, ext.SpanKindProducer)
+	defer span.Finish()
+
+	opentr.SetupPublication(span, msgID, topic)
+	opentr.AddTraceID(span, traceID)
+	opentr.LogPublisherPublish(span, false, out)
+
+	err := p.notifier.Publish(ctx, msgID, traceID, topic, out)
+
+	if err != nil {
+		opentr.LogNotifierError(span, err)
+	}
+
+	logPublish(p.logger, msgID, topic, out, err, traceID)
+
+	return err
+}
+
+func (p *peer) PublishConfirmed(ctx context.Context, topic string, out *rinq.Payload) (int, error) {
+	namespaces.MustValidate(topic)
+
+	msgID, traceID := p.nextMessageID(ctx)
+
+	span, ctx := opentr.ChildOf(ctx, p.tracer, ext.SpanKindProducer)
+	defer span.Finish()
+
+	opentr.SetupPublication(span, msgID, topic)
+	opentr.AddTraceID(span, traceID)
+	opentr.LogPublisherPublish(span, true, out)
+
+	n, err := p.notifier.PublishConfirmed(ctx, msgID, traceID, topic, out)
+
+	if err != nil {
+		opentr.LogNotifierError(span, err)
+	}
+
+	logPublishConfirmed(p.logger, msgID, topic, out, n, err, traceID)
+
+	return n, err
+}
+
+func (p *peer) Subscribe(topic string, handler rinq.PublicationHandler) error {
+	namespaces.MustValidate(topic)
+	if handler == nil {
+		panic("handler must not be nil")
+	}
+
+	added, err := p.listener.Subscribe(
+		topic,
+		func(ctx context.Context, pub rinq.Publication) {
+			span := opentracing.SpanFromContext(ctx)
+
+			traceID := trace.Get(ctx)
+
+			opentr.SetupPublication(span, pub.ID, pub.Topic)
+			opentr.AddTraceID(span, traceID)
+			opentr.LogSubscriberReceived(span, p.id, pub)
+
+			logPublicationRecv(p.logger, p.id, pub, traceID)
+
+			handler(ctx, pub)
+		},
+	)
+
+	if added {
+		logSubscribed(p.logger, p.id, topic)
+	}
+
+	return err
+}
+
+func (p *peer) Unsubscribe(topic string) error {
+	namespaces.MustValidate(topic)
+
+	removed, err := p.listener.Unsubscribe(topic)
+
+	if removed {
+		logUnsubscribed(p.logger, p.id, topic)
+	}
+
+	return err
+}
+
+func (p *peer) LongRunningHandlers() []rinq.LongRunningHandler {
+	return p.watchdog.LongRunning()
+}
+
+// nextMessageID returns the ID of the next publication sent by this peer.
+// Publications are not sent by a session, so the ID refers to the
+// zero-session.
+func (p *peer) nextMessageID(ctx context.Context) (msgID ident.MessageID, traceID string) {
+	seq := atomic.AddUint32(&p.msgSeq, 1)
+	msgID = p.id.Session(0).At(0).Message(seq)
+	traceID = trace.Get(ctx)
+
+	if traceID == "" {
+		traceID = msgID.String()
+	}
+
+	return
+}
+
 func (p *peer) run() (service.State, error) {
 	select {
 	case <-p.remoteStore.Done():
@@ -228,6 +352,8 @@ func (p *peer) finalize(err error) error {
 		p.listener,
 	)
 
+	p.watchdog.Stop()
+
 	closeErr := p.broker.Close()
 
 	// only return the close err if there's no causal error.
@@ -10,6 +10,10 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/rinq/rinq-go/src/internal/functest"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/constraint"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/options"
 )
 
 var _ = Describe("peer (functional)", func() {
@@ -51,6 +55,25 @@ var _ = Describe("peer (functional)", func() {
 			Expect(sess.ID().Seq).To(BeNumerically(">", 0))
 		})
 
+		It("returns a session that is subject to the session quota", func() {
+			subject := functest.NewPeer(
+				options.SessionQuota(options.QuotaPolicy{ValueSize: 3}),
+			)
+			defer func() {
+				subject.Stop()
+				<-subject.Done()
+			}()
+
+			sess := subject.Session()
+			defer sess.Destroy()
+
+			rev := sess.CurrentRevision()
+
+			_, err := rev.Update(context.Background(), ns, rinq.Set("a", "1234"))
+			Expect(err).To(BeAssignableToTypeOf(rinq.QuotaExceededError{}))
+			Expect(err.(rinq.QuotaExceededError).Limit).To(Equal("value size"))
+		})
+
 		It("returns a session even if the peer is stopped", func() {
 			subject := functest.NewPeer()
 
@@ -116,6 +139,156 @@ var _ = Describe("peer (functional)", func() {
 			Expect(p.Value()).To(BeEquivalentTo(nonce))
 		})
 
+		It("accepts command requests when the namespace has a worker limit", func() {
+			subject := functest.SharedPeer()
+
+			nonce := rand.Int63()
+			err := subject.Listen(ns, functest.AlwaysReturn(nonce), options.Workers(1))
+			Expect(err).Should(BeNil())
+
+			sess := subject.Session()
+			defer sess.Destroy()
+
+			p, err := sess.Call(context.Background(), ns, "", nil)
+			defer p.Close()
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(p.Value()).To(BeEquivalentTo(nonce))
+		})
+
+		It("changes the worker limit when invoked a second time", func() {
+			subject := functest.SharedPeer()
+			functest.Must(subject.Listen(ns, functest.AlwaysPanic()))
+
+			nonce := rand.Int63()
+			functest.Must(subject.Listen(ns, functest.AlwaysReturn(nonce), options.Workers(2)))
+			functest.Must(subject.Listen(ns, functest.AlwaysReturn(nonce), options.Workers(1)))
+
+			sess := subject.Session()
+			defer sess.Destroy()
+
+			p, err := sess.Call(context.Background(), ns, "", nil)
+			defer p.Close()
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(p.Value()).To(BeEquivalentTo(nonce))
+		})
+
+		It("does not delay other namespaces while a namespace with a worker limit is saturated", func() {
+			subject := functest.NewPeer(options.CommandWorkers(2))
+			defer func() {
+				subject.Stop()
+				<-subject.Done()
+			}()
+
+			// the handler blocks until the peer is stopped, as nothing reads
+			// from the barrier
+			busy := functest.NewNamespace()
+			functest.Must(subject.Listen(busy, functest.BarrierN(make(chan struct{}), 1), options.Workers(2)))
+
+			nonce := rand.Int63()
+			functest.Must(subject.Listen(ns, functest.AlwaysReturn(nonce)))
+
+			sess := subject.Session()
+			defer sess.Destroy()
+
+			for i := 0; i < 10; i++ {
+				functest.Must(sess.Execute(context.Background(), busy, "", nil))
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			p, err := sess.Call(ctx, ns, "", nil)
+			defer p.Close()
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(p.Value()).To(BeEquivalentTo(nonce))
+		})
+
+		It("rejects command requests that exceed a rate limit", func() {
+			subject := functest.SharedPeer()
+			functest.Must(subject.Listen(
+				ns,
+				functest.AlwaysReturn(nil),
+				options.RateLimit(1, time.Minute, options.PerSession),
+			))
+
+			sess := subject.Session()
+			defer sess.Destroy()
+
+			p, err := sess.Call(context.Background(), ns, "", nil)
+			p.Close()
+			Expect(err).ShouldNot(HaveOccurred())
+
+			p, err = sess.Call(context.Background(), ns, "", nil)
+			defer p.Close()
+
+			Expect(rinq.IsFailureType(rinq.RateLimitedFailure, err)).To(BeTrue())
+
+			d, ok := rinq.RetryAfter(err)
+			Expect(ok).To(BeTrue())
+			Expect(d).To(BeNumerically(">", 0))
+		})
+
+		It("does not reset the rate limit when invoked a second time", func() {
+			subject := functest.SharedPeer()
+			limit := options.RateLimit(1, time.Minute, options.PerSession)
+			functest.Must(subject.Listen(ns, functest.AlwaysReturn(nil), limit))
+
+			sess := subject.Session()
+			defer sess.Destroy()
+
+			p, err := sess.Call(context.Background(), ns, "", nil)
+			p.Close()
+			Expect(err).ShouldNot(HaveOccurred())
+
+			functest.Must(subject.Listen(ns, functest.AlwaysReturn(nil), limit))
+
+			p, err = sess.Call(context.Background(), ns, "", nil)
+			defer p.Close()
+
+			Expect(rinq.IsFailureType(rinq.RateLimitedFailure, err)).To(BeTrue())
+		})
+
+		It("does not take a token from one limit when another limit rejects the request", func() {
+			subject := functest.SharedPeer()
+			functest.Must(subject.Listen(
+				ns,
+				functest.AlwaysReturn(nil),
+				options.RateLimit(2, time.Minute, options.PerSession),
+				options.RateLimit(1, time.Minute, options.PerCommand),
+			))
+
+			sess := subject.Session()
+			defer sess.Destroy()
+
+			p, err := sess.Call(context.Background(), ns, "a", nil)
+			p.Close()
+			Expect(err).ShouldNot(HaveOccurred())
+
+			// rejected by the per-command limit
+			p, err = sess.Call(context.Background(), ns, "a", nil)
+			p.Close()
+			Expect(rinq.IsFailureType(rinq.RateLimitedFailure, err)).To(BeTrue())
+
+			// the per-session limit still has a token available
+			p, err = sess.Call(context.Background(), ns, "b", nil)
+			defer p.Close()
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+
+		It("responds with a command error if the handler panics", func() {
+			subject := functest.SharedPeer()
+			functest.Must(subject.Listen(ns, functest.AlwaysPanic()))
+
+			sess := subject.Session()
+			defer sess.Destroy()
+
+			_, err := sess.Call(context.Background(), ns, "", nil)
+			Expect(err).To(Equal(rinq.CommandError("command handler panicked")))
+		})
+
 		It("panics if the namespace is invalid", func() {
 			subject := functest.SharedPeer()
 
@@ -180,6 +353,105 @@ var _ = Describe("peer (functional)", func() {
 		})
 	})
 
+	Context("when publishing to topics", func() {
+		var (
+			publisher, subscriber rinq.Peer
+			received              chan rinq.Publication
+		)
+
+		handler := func(_ context.Context, pub rinq.Publication) {
+			pub.Payload.Close()
+			received <- pub
+		}
+
+		BeforeEach(func() {
+			publisher, subscriber = functest.NewPeerPair()
+			received = make(chan rinq.Publication, 10)
+		})
+
+		AfterEach(func() {
+			functest.StopPeers(publisher, subscriber)
+		})
+
+		Describe("Publish", func() {
+			It("delivers the message to subscribed peers", func() {
+				functest.Must(subscriber.Subscribe(ns, handler))
+
+				err := publisher.Publish(context.Background(), ns, nil)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				var pub rinq.Publication
+				Eventually(received).Should(Receive(&pub))
+				Expect(pub.Topic).To(Equal(ns))
+				Expect(pub.Source).To(Equal(publisher.ID()))
+			})
+
+			It("does not deliver the message to peers subscribed to other topics", func() {
+				functest.Must(subscriber.Subscribe(functest.NewNamespace(), handler))
+
+				err := publisher.Publish(context.Background(), ns, nil)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				Consistently(received).ShouldNot(Receive())
+			})
+
+			It("does not deliver the message to sessions listening to a namespace with the same name", func() {
+				sess := subscriber.Session()
+				defer sess.Destroy()
+
+				functest.Must(sess.Listen(ns, func(_ context.Context, _ rinq.Session, n rinq.Notification) {
+					n.Payload.Close()
+					received <- rinq.Publication{}
+				}))
+
+				err := publisher.Publish(context.Background(), ns, nil)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				Consistently(received).ShouldNot(Receive())
+			})
+
+			It("panics if the topic is invalid", func() {
+				Expect(func() {
+					_ = publisher.Publish(context.Background(), "_invalid", nil)
+				}).Should(Panic())
+			})
+		})
+
+		Describe("PublishConfirmed", func() {
+			It("returns the number of peers that handled the message", func() {
+				functest.Must(publisher.Subscribe(ns, handler))
+				functest.Must(subscriber.Subscribe(ns, handler))
+
+				ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+				defer cancel()
+
+				n, err := publisher.PublishConfirmed(ctx, ns, nil)
+
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(n).To(Equal(2))
+			})
+		})
+
+		Describe("Unsubscribe", func() {
+			It("stops delivering messages to the peer", func() {
+				functest.Must(subscriber.Subscribe(ns, handler))
+
+				err := subscriber.Unsubscribe(ns)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				err = publisher.Publish(context.Background(), ns, nil)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				Consistently(received).ShouldNot(Receive())
+			})
+
+			It("can be invoked when not subscribed", func() {
+				err := subscriber.Unsubscribe(ns)
+				Expect(err).ShouldNot(HaveOccurred())
+			})
+		})
+	})
+
 	Describe("Stop", func() {
 		Context("when running normally", func() {
 			It("cancels pending calls", func() {
@@ -248,4 +520,674 @@ var _ = Describe("peer (functional)", func() {
 			Expect(err).ShouldNot(HaveOccurred())
 		})
 	})
+
+	Context("when listening for specific notification types and namespaces", func() {
+		var (
+			subject  rinq.Peer
+			sender   rinq.Session
+			receiver rinq.Session
+			received chan string
+		)
+
+		// handler returns a notification handler that sends the namespace and
+		// type of each notification it receives, prefixed with name.
+		handler := func(name string) rinq.NotificationHandler {
+			return func(_ context.Context, _ rinq.Session, n rinq.Notification) {
+				n.Payload.Close()
+				received <- name + ":" + n.Namespace + "::" + n.Type
+			}
+		}
+
+		notify := func(ns, t string) {
+			err := sender.Notify(context.Background(), ns, t, receiver.ID(), nil)
+			Expect(err).ShouldNot(HaveOccurred())
+		}
+
+		BeforeEach(func() {
+			subject = functest.NewPeer()
+			sender = subject.Session()
+			receiver = subject.Session()
+			received = make(chan string, 10)
+		})
+
+		AfterEach(func() {
+			sender.Destroy()
+			receiver.Destroy()
+
+			subject.Stop()
+			<-subject.Done()
+		})
+
+		It("only receives notifications of the given types", func() {
+			functest.Must(receiver.ListenTypes(ns, []string{"a"}, handler("h")))
+
+			notify(ns, "b")
+			notify(ns, "a")
+
+			Eventually(received).Should(Receive(Equal("h:" + ns + "::a")))
+			Consistently(received).ShouldNot(Receive())
+		})
+
+		It("receives notifications in namespaces that match a pattern", func() {
+			functest.Must(receiver.Listen(ns+".*", handler("h")))
+
+			notify(ns+".x", "t")
+			notify(ns, "t")
+			notify(ns+".x.y", "t")
+
+			Eventually(received).Should(Receive(Equal("h:" + ns + ".x::t")))
+			Consistently(received).ShouldNot(Receive())
+		})
+
+		It("prefers the handler for the namespace over a pattern", func() {
+			functest.Must(receiver.Listen(ns+".#", handler("pattern")))
+			functest.Must(receiver.Listen(ns+".x", handler("namespace")))
+
+			notify(ns+".x", "t")
+			notify(ns+".y", "t")
+
+			Eventually(received).Should(Receive(Equal("namespace:" + ns + ".x::t")))
+			Eventually(received).Should(Receive(Equal("pattern:" + ns + ".y::t")))
+		})
+
+		It("replaces the types when invoked a second time", func() {
+			functest.Must(receiver.ListenTypes(ns, []string{"a"}, handler("h")))
+			functest.Must(receiver.ListenTypes(ns, []string{"b"}, handler("h")))
+
+			notify(ns, "a")
+			notify(ns, "b")
+
+			Eventually(received).Should(Receive(Equal("h:" + ns + "::b")))
+			Consistently(received).ShouldNot(Receive())
+		})
+
+		It("panics if no types are given", func() {
+			Expect(func() {
+				receiver.ListenTypes(ns, nil, handler("h"))
+			}).To(Panic())
+		})
+
+		It("panics if the namespace pattern is invalid", func() {
+			Expect(func() {
+				receiver.Listen("*."+ns, handler("h"))
+			}).To(Panic())
+		})
+	})
+
+	Context("when confirming notifications", func() {
+		var (
+			sender, receiver rinq.Peer
+			sess             rinq.Session
+			listening        rinq.Session
+		)
+
+		handler := func(_ context.Context, _ rinq.Session, n rinq.Notification) {
+			n.Payload.Close()
+		}
+
+		BeforeEach(func() {
+			sender, receiver = functest.NewPeerPair()
+			sess = sender.Session()
+
+			// ensure notifications in ns are routed to the receiver
+			listening = receiver.Session()
+			functest.Must(listening.Listen(ns, handler))
+
+			_, err := listening.CurrentRevision().Update(context.Background(), ns, rinq.Set("group", "x"))
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			sess.Destroy()
+			listening.Destroy()
+
+			functest.StopPeers(sender, receiver)
+		})
+
+		Describe("NotifyConfirmed", func() {
+			It("returns true when the target session has handled the notification", func() {
+				handled, err := sess.NotifyConfirmed(context.Background(), ns, "t", listening.ID(), nil)
+
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(handled).To(BeTrue())
+			})
+
+			It("returns false when the target session is not listening", func() {
+				target := receiver.Session()
+				defer target.Destroy()
+
+				handled, err := sess.NotifyConfirmed(context.Background(), ns, "t", target.ID(), nil)
+
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(handled).To(BeFalse())
+			})
+
+			It("returns a not found error when the target session does not exist", func() {
+				target := receiver.Session()
+				target.Destroy()
+				<-target.Done()
+
+				_, err := sess.NotifyConfirmed(context.Background(), ns, "t", target.ID(), nil)
+
+				Expect(err).To(Equal(rinq.NotFoundError{ID: target.ID()}))
+			})
+
+			It("returns false when no sessions on the target's peer are listening", func() {
+				peer := functest.NewPeer()
+				defer functest.StopPeers(peer)
+
+				target := peer.Session()
+				defer target.Destroy()
+
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+
+				handled, err := sess.NotifyConfirmed(ctx, ns, "t", target.ID(), nil)
+
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(handled).To(BeFalse())
+			})
+
+			It("returns a not found error when the target has been destroyed and no sessions on its peer are listening", func() {
+				peer := functest.NewPeer()
+				defer functest.StopPeers(peer)
+
+				target := peer.Session()
+				target.Destroy()
+				<-target.Done()
+
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+
+				_, err := sess.NotifyConfirmed(ctx, ns, "t", target.ID(), nil)
+
+				Expect(err).To(Equal(rinq.NotFoundError{ID: target.ID()}))
+			})
+		})
+
+		Describe("NotifyManyConfirmed", func() {
+			It("returns the number of sessions that handled the notification", func() {
+				other := receiver.Session()
+				defer other.Destroy()
+
+				functest.Must(other.Listen(ns, handler))
+
+				_, err := other.CurrentRevision().Update(context.Background(), ns, rinq.Set("group", "x"))
+				Expect(err).ShouldNot(HaveOccurred())
+
+				ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+				defer cancel()
+
+				n, err := sess.NotifyManyConfirmed(ctx, ns, "t", constraint.Equal("group", "x"), nil)
+
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(n).To(Equal(2))
+			})
+		})
+	})
+
+	Context("when notifying a list of sessions", func() {
+		var (
+			peers    []rinq.Peer
+			sess     rinq.Session
+			received chan ident.SessionID
+		)
+
+		handler := func(_ context.Context, target rinq.Session, n rinq.Notification) {
+			n.Payload.Close()
+			received <- target.ID()
+		}
+
+		BeforeEach(func() {
+			peers = []rinq.Peer{functest.NewPeer(), functest.NewPeer()}
+			sess = peers[0].Session()
+			received = make(chan ident.SessionID, 10)
+		})
+
+		AfterEach(func() {
+			sess.Destroy()
+
+			functest.StopPeers(peers...)
+		})
+
+		Describe("NotifyEach", func() {
+			It("delivers the notification to each of the sessions, on each peer", func() {
+				var targets []ident.SessionID
+
+				for _, p := range peers {
+					for i := 0; i < 2; i++ {
+						target := p.Session()
+						defer target.Destroy()
+
+						functest.Must(target.Listen(ns, handler))
+						targets = append(targets, target.ID())
+					}
+				}
+
+				err := sess.NotifyEach(context.Background(), ns, "t", targets, nil)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				var ids []ident.SessionID
+				for range targets {
+					var id ident.SessionID
+					Eventually(received).Should(Receive(&id))
+					ids = append(ids, id)
+				}
+
+				Expect(ids).To(ConsistOf(targets))
+			})
+
+			It("does not deliver the notification to other sessions", func() {
+				target := peers[1].Session()
+				defer target.Destroy()
+				functest.Must(target.Listen(ns, handler))
+
+				other := peers[1].Session()
+				defer other.Destroy()
+				functest.Must(other.Listen(ns, handler))
+
+				err := sess.NotifyEach(context.Background(), ns, "t", []ident.SessionID{target.ID()}, nil)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				Eventually(received).Should(Receive(Equal(target.ID())))
+				Consistently(received).ShouldNot(Receive())
+			})
+
+			It("delivers the notification once to sessions that are listed more than once", func() {
+				target := peers[1].Session()
+				defer target.Destroy()
+				functest.Must(target.Listen(ns, handler))
+
+				targets := []ident.SessionID{target.ID(), target.ID()}
+				err := sess.NotifyEach(context.Background(), ns, "t", targets, nil)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				Eventually(received).Should(Receive(Equal(target.ID())))
+				Consistently(received).ShouldNot(Receive())
+			})
+
+			It("panics if one of the targets is the zero-session", func() {
+				Expect(func() {
+					_ = sess.NotifyEach(context.Background(), ns, "t", []ident.SessionID{peers[1].ID().Session(0)}, nil)
+				}).To(Panic())
+			})
+		})
+	})
+
+	Context("when notifications are retained", func() {
+		var (
+			sender, receiver rinq.Peer
+			sess, listening  rinq.Session
+			received         chan rinq.Notification
+		)
+
+		handler := func(_ context.Context, _ rinq.Session, n rinq.Notification) {
+			received <- n
+		}
+
+		BeforeEach(func() {
+			sender, receiver = functest.NewPeerPair(options.Retention(true))
+			sess = sender.Session()
+			listening = receiver.Session()
+			received = make(chan rinq.Notification, 10)
+
+			_, err := listening.CurrentRevision().Update(context.Background(), ns, rinq.Set("group", "x"))
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			sess.Destroy()
+			listening.Destroy()
+
+			functest.StopPeers(sender, receiver)
+		})
+
+		Describe("NotifyManyRetained", func() {
+			It("replays the notification to sessions that start listening later", func() {
+				out := rinq.NewPayload("<value>")
+				defer out.Close()
+
+				err := sess.NotifyManyRetained(context.Background(), ns, "t", constraint.Equal("group", "x"), time.Minute, out)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				functest.Must(listening.Listen(ns, handler))
+
+				var n rinq.Notification
+				Eventually(received).Should(Receive(&n))
+				defer n.Payload.Close()
+
+				Expect(n.Type).To(Equal("t"))
+				Expect(n.IsMulticast).To(BeTrue())
+				Expect(n.Payload.Value()).To(Equal("<value>"))
+			})
+
+			It("only replays the latest notification of each type", func() {
+				con := constraint.Equal("group", "x")
+
+				out := rinq.NewPayload(1)
+				functest.Must(sess.NotifyManyRetained(context.Background(), ns, "t", con, time.Minute, out))
+				out.Close()
+
+				out = rinq.NewPayload(2)
+				functest.Must(sess.NotifyManyRetained(context.Background(), ns, "t", con, time.Minute, out))
+				out.Close()
+
+				functest.Must(listening.Listen(ns, handler))
+
+				var n rinq.Notification
+				Eventually(received).Should(Receive(&n))
+				defer n.Payload.Close()
+
+				Expect(n.Payload.Value()).To(BeEquivalentTo(2))
+				Consistently(received).ShouldNot(Receive())
+			})
+
+			It("does not replay the notification to sessions that do not match the constraint", func() {
+				err := sess.NotifyManyRetained(context.Background(), ns, "t", constraint.Equal("group", "y"), time.Minute, nil)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				functest.Must(listening.Listen(ns, handler))
+
+				Consistently(received).ShouldNot(Receive())
+			})
+
+			It("does not replay the notification to sessions that do not accept its type", func() {
+				err := sess.NotifyManyRetained(context.Background(), ns, "t", constraint.Equal("group", "x"), time.Minute, nil)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				functest.Must(listening.ListenTypes(ns, []string{"other"}, handler))
+
+				Consistently(received).ShouldNot(Receive())
+			})
+
+			It("only replays notifications of types that were not already accepted", func() {
+				con := constraint.Equal("group", "x")
+				functest.Must(sess.NotifyManyRetained(context.Background(), ns, "a", con, time.Minute, nil))
+				functest.Must(sess.NotifyManyRetained(context.Background(), ns, "b", con, time.Minute, nil))
+
+				functest.Must(listening.ListenTypes(ns, []string{"a"}, handler))
+
+				var n rinq.Notification
+				Eventually(received).Should(Receive(&n))
+				Expect(n.Type).To(Equal("a"))
+
+				functest.Must(listening.Listen(ns, handler))
+
+				Eventually(received).Should(Receive(&n))
+				Expect(n.Type).To(Equal("b"))
+				Consistently(received).ShouldNot(Receive())
+			})
+
+			It("does not replay the notification if the listening peer does not enable retention", func() {
+				err := sess.NotifyManyRetained(context.Background(), ns, "t", constraint.Equal("group", "x"), time.Minute, nil)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				other := functest.NewPeer()
+				defer functest.StopPeers(other)
+
+				target := other.Session()
+				defer target.Destroy()
+
+				_, err = target.CurrentRevision().Update(context.Background(), ns, rinq.Set("group", "x"))
+				Expect(err).ShouldNot(HaveOccurred())
+
+				functest.Must(target.Listen(ns, handler))
+
+				Consistently(received).ShouldNot(Receive())
+			})
+
+			It("does not replay the notification after the TTL has elapsed", func() {
+				err := sess.NotifyManyRetained(context.Background(), ns, "t", constraint.Equal("group", "x"), 50*time.Millisecond, nil)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				time.Sleep(100 * time.Millisecond)
+
+				functest.Must(listening.Listen(ns, handler))
+
+				Consistently(received).ShouldNot(Receive())
+			})
+
+			It("panics if the TTL is not positive", func() {
+				Expect(func() {
+					_ = sess.NotifyManyRetained(context.Background(), ns, "t", constraint.None, 0, nil)
+				}).To(Panic())
+			})
+		})
+	})
+
+	Context("when notifications are queued in session mailboxes", func() {
+		var (
+			sender, receiver rinq.Peer
+			barrier          chan struct{}
+			handled          chan rinq.Session
+		)
+
+		// setup returns a session on the receiver that listens for
+		// notifications, blocking on barrier if block is true.
+		setup := func(block bool) rinq.Session {
+			sess := receiver.Session()
+
+			functest.Must(sess.Listen(ns, func(_ context.Context, target rinq.Session, n rinq.Notification) {
+				n.Payload.Close()
+
+				if block {
+					<-barrier
+				}
+
+				handled <- target
+			}))
+
+			_, err := sess.CurrentRevision().Update(context.Background(), ns, rinq.Set("group", "x"))
+			Expect(err).ShouldNot(HaveOccurred())
+
+			return sess
+		}
+
+		notify := func() {
+			sess := sender.Session()
+			defer sess.Destroy()
+
+			err := sess.NotifyMany(context.Background(), ns, "t", constraint.Equal("group", "x"), nil)
+			Expect(err).ShouldNot(HaveOccurred())
+		}
+
+		BeforeEach(func() {
+			barrier = make(chan struct{})
+			handled = make(chan rinq.Session, 10)
+			sender = functest.NewPeer()
+		})
+
+		AfterEach(func() {
+			close(barrier)
+
+			functest.StopPeers(sender, receiver)
+		})
+
+		It("does not delay other sessions while a handler is running", func() {
+			receiver = functest.NewPeer()
+
+			blocked := setup(true)
+			defer blocked.Destroy()
+
+			unblocked := setup(false)
+			defer unblocked.Destroy()
+
+			notify()
+
+			var target rinq.Session
+			Eventually(handled).Should(Receive(&target))
+			Expect(target.ID()).To(Equal(unblocked.ID()))
+		})
+
+		It("does not delay other sessions while a mailbox is full", func() {
+			receiver = functest.NewPeer(
+				options.SessionMailbox(options.MailboxPolicy{
+					Depth:    1,
+					Overflow: options.BlockOnOverflow,
+				}),
+			)
+
+			blocked := setup(true)
+			defer blocked.Destroy()
+
+			notify()
+			notify()
+			notify()
+
+			// allow the notifications to fill the mailbox
+			time.Sleep(100 * time.Millisecond)
+
+			unblocked := setup(false)
+			defer unblocked.Destroy()
+
+			notify()
+
+			var target rinq.Session
+			Eventually(handled).Should(Receive(&target))
+			Expect(target.ID()).To(Equal(unblocked.ID()))
+		})
+
+		It("discards notifications that arrive when the mailbox is full", func() {
+			receiver = functest.NewPeer(
+				options.SessionMailbox(options.MailboxPolicy{
+					Depth:    1,
+					Overflow: options.DropNewest,
+				}),
+			)
+
+			sess := setup(true)
+			defer sess.Destroy()
+
+			notify()
+			notify()
+			notify()
+
+			// allow the notifications to arrive before unblocking the handler
+			time.Sleep(100 * time.Millisecond)
+			barrier <- struct{}{}
+			barrier <- struct{}{}
+
+			Eventually(handled).Should(HaveLen(2))
+			Consistently(handled).Should(HaveLen(2))
+		})
+	})
+
+	Context("when multicast filtering is enabled", func() {
+		var (
+			sender, receiver rinq.Peer
+			notifications    chan rinq.Notification
+			target           rinq.Session
+		)
+
+		BeforeEach(func() {
+			sender, receiver = functest.NewPeerPair(options.MulticastFiltering(true))
+			notifications = make(chan rinq.Notification, 10)
+
+			target = receiver.Session()
+			functest.Must(target.Listen(ns, func(_ context.Context, _ rinq.Session, n rinq.Notification) {
+				n.Payload.Close()
+				notifications <- n
+			}))
+
+			_, err := target.CurrentRevision().Update(context.Background(), ns, rinq.Set("role", "admin"))
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			target.Destroy()
+
+			functest.StopPeers(sender, receiver)
+		})
+
+		It("delivers notifications to sessions with matching attributes", func() {
+			sess := sender.Session()
+			defer sess.Destroy()
+
+			err := sess.NotifyMany(context.Background(), ns, "t", constraint.Equal("role", "admin"), nil)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			var n rinq.Notification
+			Eventually(notifications).Should(Receive(&n))
+			Expect(n.IsMulticast).To(BeTrue())
+		})
+
+		It("delivers notifications that can not be filtered", func() {
+			sess := sender.Session()
+			defer sess.Destroy()
+
+			err := sess.NotifyMany(context.Background(), ns, "t", constraint.Prefix("role", "adm"), nil)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Eventually(notifications).Should(Receive())
+		})
+
+		It("does not deliver notifications to sessions without matching attributes", func() {
+			sess := sender.Session()
+			defer sess.Destroy()
+
+			err := sess.NotifyMany(context.Background(), ns, "t", constraint.Equal("role", "ops"), nil)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Consistently(notifications).ShouldNot(Receive())
+		})
+
+		It("delivers notifications to sessions after their attributes change", func() {
+			_, err := target.CurrentRevision().Update(context.Background(), ns, rinq.Set("role", "ops"))
+			Expect(err).ShouldNot(HaveOccurred())
+
+			sess := sender.Session()
+			defer sess.Destroy()
+
+			err = sess.NotifyMany(context.Background(), ns, "t", constraint.Equal("role", "ops"), nil)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Eventually(notifications).Should(Receive())
+		})
+
+		It("only delivers notifications of the types the session listens for", func() {
+			functest.Must(target.ListenTypes(ns, []string{"a"}, func(_ context.Context, _ rinq.Session, n rinq.Notification) {
+				n.Payload.Close()
+				notifications <- n
+			}))
+
+			sess := sender.Session()
+			defer sess.Destroy()
+
+			err := sess.NotifyMany(context.Background(), ns, "b", constraint.Equal("role", "admin"), nil)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			err = sess.NotifyMany(context.Background(), ns, "a", constraint.Equal("role", "admin"), nil)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			var n rinq.Notification
+			Eventually(notifications).Should(Receive(&n))
+			Expect(n.Type).To(Equal("a"))
+			Consistently(notifications).ShouldNot(Receive())
+		})
+
+		It("delivers notifications to peers with filtering disabled", func() {
+			unfiltered := functest.NewPeer()
+			defer functest.StopPeers(unfiltered)
+
+			other := unfiltered.Session()
+			defer other.Destroy()
+
+			received := make(chan rinq.Notification, 1)
+			functest.Must(other.Listen(ns, func(_ context.Context, _ rinq.Session, n rinq.Notification) {
+				n.Payload.Close()
+				received <- n
+			}))
+
+			_, err := other.CurrentRevision().Update(context.Background(), ns, rinq.Set("role", "admin"))
+			Expect(err).ShouldNot(HaveOccurred())
+
+			sess := sender.Session()
+			defer sess.Destroy()
+
+			err = sess.NotifyMany(context.Background(), ns, "t", constraint.Equal("role", "admin"), nil)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Eventually(received).Should(Receive())
+		})
+	})
 })
@@ -2,6 +2,7 @@ package rinqamqp
 
 import (
 	"github.com/jmalloc/twelf/src/twelf"
+	"github.com/rinq/rinq-go/src/rinq"
 	"github.com/rinq/rinq-go/src/rinq/ident"
 )
 
@@ -28,3 +29,88 @@ func logStoppedListening(
 		namespace,
 	)
 }
+
+func logSubscribed(
+	logger twelf.Logger,
+	peerID ident.PeerID,
+	topic string,
+) {
+	logger.Log(
+		"%s subscribed to '%s' topic",
+		peerID.ShortString(),
+		topic,
+	)
+}
+
+func logUnsubscribed(
+	logger twelf.Logger,
+	peerID ident.PeerID,
+	topic string,
+) {
+	logger.Log(
+		"%s unsubscribed from '%s' topic",
+		peerID.ShortString(),
+		topic,
+	)
+}
+
+func logPublish(
+	logger twelf.Logger,
+	msgID ident.MessageID,
+	topic string,
+	out *rinq.Payload,
+	err error,
+	traceID string,
+) {
+	if err != nil {
+		return // message never sent
+	}
+
+	logger.Log(
+		"%s published message to '%s' topic (%d/o) [%s]",
+		msgID.ShortString(),
+		topic,
+		out.Len(),
+		traceID,
+	)
+}
+
+func logPublishConfirmed(
+	logger twelf.Logger,
+	msgID ident.MessageID,
+	topic string,
+	out *rinq.Payload,
+	n int,
+	err error,
+	traceID string,
+) {
+	if err != nil {
+		return // message never sent
+	}
+
+	logger.Log(
+		"%s published message to '%s' topic (%d/o), handled by %d peer(s) [%s]",
+		msgID.ShortString(),
+		topic,
+		out.Len(),
+		n,
+		traceID,
+	)
+}
+
+func logPublicationRecv(
+	logger twelf.Logger,
+	peerID ident.PeerID,
+	pub rinq.Publication,
+	traceID string,
+) {
+	logger.Log(
+		"%s received message %s on '%s' topic from %s (%d/i) [%s]",
+		peerID.ShortString(),
+		pub.ID.ShortString(),
+		pub.Topic,
+		pub.Source.ShortString(),
+		pub.Payload.Len(),
+		traceID,
+	)
+}